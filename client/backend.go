@@ -377,20 +377,20 @@ func generateGameData() []*protocol.TransportMessage {
 		{
 			Bet:    5,
 			Wallet: 300,
-			Hand:   protocol.HandDTO{Cards: generateMockCards(), Value: 18, State: "LIVE"},
+			Hands:  []protocol.HandDTO{{Cards: generateMockCards(), Value: 18, State: "LIVE", Bet: 5}},
 			Name:   randomNames[rand.IntN(len(randomNames))],
 		},
 		{
 			Bet:    5,
 			Wallet: 300,
-			Hand:   protocol.HandDTO{Cards: generateMockCards(), Value: 18, State: "LIVE"},
+			Hands:  []protocol.HandDTO{{Cards: generateMockCards(), Value: 18, State: "LIVE", Bet: 5}},
 			Name:   randomNames[rand.IntN(len(randomNames))],
 		},
 		{},
 		{},
 		{},
 	}
-	gameState := protocol.GameDTO{Players: players, DealerHand: protocol.HandDTO{Cards: generateMockCards(), Value: 18, State: "LIVE"}}
+	gameState := protocol.GameDTO{Players: players, DealerHand: protocol.HandDTO{Cards: generateMockCards(), Value: 18, State: "LIVE"}, Rules: protocol.RulesDTO{MinBet: 5, MaxBet: 500, BetIncrement: 5}}
 	dat, err := protocol.PackageMessage(gameState)
 	if err != nil {
 		slog.Error("Unable to generate game data. gameState encoding error:", "error", err)
@@ -400,7 +400,8 @@ func generateGameData() []*protocol.TransportMessage {
 }
 
 func generateTableData() []*protocol.TransportMessage {
-	tblList := []protocol.TableDTO{{Id: "test1", Capacity: 5, CurrentPlayers: 1}, {Id: "test3", Capacity: 5, CurrentPlayers: 1}, {Id: "test2", Capacity: 5, CurrentPlayers: 1}}
+	rules := protocol.RulesDTO{StandOnSoft17: true, BlackjackPayout: "3:2", DoubleRule: "any", DoubleAfterSplit: true, MaxSplits: 3, Surrender: "late", MinBet: 1}
+	tblList := []protocol.TableDTO{{Id: "test1", Capacity: 5, CurrentPlayers: 1, Rules: rules}, {Id: "test3", Capacity: 5, CurrentPlayers: 1, Rules: rules}, {Id: "test2", Capacity: 5, CurrentPlayers: 1, Rules: rules}}
 	dat, err := protocol.PackageMessage(tblList) // This will need to be changed. PackageMessage is hankering for a refactor
 	if err != nil {
 		slog.Error("Unable to generate table data. tblList encoding error:", "error", err)
@@ -1,6 +1,7 @@
 package client
 
 import (
+	"fmt"
 	"strings"
 
 	tea "github.com/charmbracelet/bubbletea"
@@ -92,6 +93,7 @@ type LeftBar struct {
 	Width        int
 	Username     string
 	ServerStatus string
+	Tournament   *protocol.TournamentDTO // leaderboard of the tournament being played at our table
 }
 
 func NewLeftBar(height, width int) *LeftBar {
@@ -106,6 +108,12 @@ func (lb *LeftBar) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case AuthPollMsg:
 		lb.Username = msg.UserName
+	case *protocol.GameDTO:
+		lb.Tournament = msg.Tournament
+	case ChangeRootPageMsg:
+		if msg.page != gamePage {
+			lb.Tournament = nil
+		}
 	case tea.WindowSizeMsg:
 		lb.Width = (msg.Width - 8) / 4
 		lb.Height = msg.Height * 2 / 3
@@ -124,9 +132,51 @@ func (lb *LeftBar) View() string {
 		sb.WriteString(lb.Username)
 	}
 	sb.WriteString("\n")
+	if lb.Tournament != nil {
+		sb.WriteString("\n")
+		sb.WriteString(lb.renderLeaderboard())
+	}
 	return style.Render(sb.String())
 }
 
+// renderLeaderboard is the tournament's standings and how long it has left
+func (lb *LeftBar) renderLeaderboard() string {
+	tr := lb.Tournament
+	me := lipgloss.NewStyle().Foreground(lipgloss.Color(highlight))
+	out := lipgloss.NewStyle().Foreground(lipgloss.Color(softForeground))
+	var sb strings.Builder
+	sb.WriteString(tr.Name)
+	sb.WriteString("\n")
+	switch {
+	case tr.State == "finished":
+		sb.WriteString("Final standings\n")
+	case tr.NextElimination > 0:
+		sb.WriteString(fmt.Sprintf("%d hands left. Elimination in %d\n", tr.HandsLeft, tr.NextElimination))
+	default:
+		sb.WriteString(fmt.Sprintf("%d hands left\n", tr.HandsLeft))
+	}
+	for i, st := range tr.Standings {
+		place := i + 1
+		if st.Place > 0 {
+			place = st.Place
+		}
+		line := fmt.Sprintf("%d. %s %d", place, st.Name, st.Chips)
+		if st.Prize > 0 {
+			line += fmt.Sprintf(" (+%d)", st.Prize)
+		}
+		switch {
+		case st.Name == lb.Username:
+			line = me.Render(line)
+		case st.Place > 0 && tr.State != "finished":
+			// knocked out
+			line = out.Render(line)
+		}
+		sb.WriteString(line)
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
 func (lb *LeftBar) Init() tea.Cmd {
 	return nil
 }
@@ -93,6 +93,33 @@ func (c *Card) View() string {
 	return view.String()
 }
 
+// renderHand draws a hand with its face down cards at the end
+func renderHand(hand TuiHand, w, h int) string {
+	if hand.Hidden == 0 {
+		return renderMultipleCards(hand.Cards, w, h)
+	}
+	cardViews := []string{}
+	for _, card := range hand.Cards {
+		cardViews = append(cardViews, card.ViewPartial())
+	}
+	for range hand.Hidden - 1 {
+		cardViews = append(cardViews, hiddenCardPartial())
+	}
+	cardViews = append(cardViews, hiddenCardView())
+	return lipgloss.Place(w, h, lipgloss.Left, lipgloss.Center, lipgloss.JoinHorizontal(lipgloss.Left, cardViews...))
+}
+
+func hiddenCardPartial() string {
+	style := lipgloss.NewStyle().Foreground(lipgloss.Color("#FFFFFF"))
+	var view strings.Builder
+	view.WriteString(style.Render(cardTL+strings.Repeat(cardHor, 2)) + "\n")
+	for i := 1; i < Height-1; i++ {
+		view.WriteString(style.Render(cardVer+strings.Repeat(" ", 2)) + "\n")
+	}
+	view.WriteString(style.Render(cardBL + strings.Repeat(cardHor, 2)))
+	return view.String()
+}
+
 func hiddenCardView() string {
 	color := lipgloss.Color("#FFFFFF")
 	style := lipgloss.NewStyle().Foreground(color)
@@ -79,6 +79,8 @@ func (rm *RootModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		rm.transporter.Connect()
 		cmd := SendData(protocol.PackageClientMessage(protocol.MsgTableList, ""))
 		cmds = append(cmds, cmd)
+		cmds = append(cmds, SendData(protocol.PackageClientMessage(protocol.MsgTournamentList, "")))
+		cmds = append(cmds, SendData(protocol.PackageClientMessage(protocol.MsgJackpot, "")))
 	case ReloadStatsMsg:
 		cmd := SendData(protocol.PackageClientMessage(protocol.MsgGetStats, ""))
 		cmds = append(cmds, cmd)
@@ -96,6 +98,12 @@ func (rm *RootModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		cmds = append(cmds, cmd)
 		rm.table, cmd = rm.table.Update(msg)
 		cmds = append(cmds, cmd)
+	case protocol.ShoeCommitDTO:
+		// the commitment is sent as we join, which can land before the table page is showing
+		if rm.page != gamePage {
+			rm.table, cmd = rm.table.Update(msg)
+			cmds = append(cmds, cmd)
+		}
 	case tea.WindowSizeMsg:
 		rm.width = msg.Width - 1
 		rm.height = msg.Height - 1
@@ -200,13 +208,29 @@ func (rm *RootModel) Send(data *protocol.TransportMessage) tea.Cmd {
 	}
 }
 
+type TuiHand struct {
+	Cards  []*Card
+	Value  int
+	Bet    int
+	Hidden int    // face down cards after the ones in Cards
+	State  string // the hand state the server sent, e.g. Charlie
+}
+
 type TuiPlayer struct {
-	Name    string
-	Cards   []*Card
-	Value   int
-	Wallet  int
-	Bet     int
-	Current bool
+	Name        string
+	Hands       []TuiHand
+	Wallet      int
+	Bet         int
+	Current     bool
+	CurrentHand int
+	Insurance   int
+	Jackpot     int
+	SideBets    int
+	Bot         bool
+	Spot        int  // which of its player's spots this is
+	Acting      bool // one of our spots and the one our keys act on. Only set while we have more than one
+	AutoPlay    bool // the table is betting and playing for this spot
+	SittingOut  bool
 }
 
 func RunTui(mock bool) {
@@ -62,6 +62,13 @@ func SendData(msg *protocol.TransportMessage) tea.Cmd {
 	}
 }
 
+// PopUpCmd shows a pop up that didn't come from the server
+func PopUpCmd(message string, lvl protocol.PopUpType) tea.Cmd {
+	return func() tea.Msg {
+		return protocol.MessageToDTO(message, lvl)
+	}
+}
+
 func PopUpTimer() tea.Cmd {
 	return tea.Tick(5*time.Second, func(t time.Time) tea.Msg {
 		return PopUpRemoveMsg{}
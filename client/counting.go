@@ -0,0 +1,182 @@
+package client
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/dylanmccormick/blackjack-tui/protocol"
+)
+
+// QUIZ_ODDS is roughly how many game states with new cards go by between quizzes
+const QUIZ_ODDS = 6
+
+// CountTrainer keeps a Hi-Lo count of every card that shows on the table. Twos through sixes
+// count +1 and tens and aces count -1. The true count is the running count per deck left in the shoe
+type CountTrainer struct {
+	Shown   bool
+	Quizzes bool
+
+	running     int  // count from the rounds already finished in this shoe
+	round       int  // round the cards on the table belong to
+	roundCount  int  // count from the cards showing this round
+	roundCards  int  // cards showing this round
+	shuffled    bool // the shoe was shuffled since the last game state
+	cardsInShoe int
+	deckSize    int // Spanish 21 decks have no tens
+
+	asking  bool
+	input   textinput.Model
+	asked   int
+	correct int
+}
+
+func NewCountTrainer() *CountTrainer {
+	input := textinput.New()
+	input.Placeholder = "0"
+	input.Width = 5
+	return &CountTrainer{input: input}
+}
+
+func hiLo(c protocol.CardDTO) int {
+	switch {
+	case c.Rank >= 2 && c.Rank <= 6:
+		return 1
+	case c.Rank == 1 || c.Rank >= 10:
+		return -1
+	}
+	return 0
+}
+
+// Observe counts the cards showing in a game state. Cards from a round only show up once and in
+// the same place, so the round is recounted from scratch each time and kept once the round changes.
+// Returns true if new cards came out
+func (ct *CountTrainer) Observe(msg *protocol.GameDTO) bool {
+	if msg.Round != ct.round {
+		// a shuffle between rounds took the last round's cards back. So does a continuous shuffler
+		if !ct.shuffled && !msg.Rules.ContinuousShuffle {
+			ct.running += ct.roundCount
+		}
+		ct.round = msg.Round
+		ct.roundCount = 0
+		ct.roundCards = 0
+	}
+	ct.shuffled = false
+	ct.cardsInShoe = msg.CardsInShoe
+	ct.deckSize = 52
+	if msg.Variant == "spanish21" {
+		ct.deckSize = 48
+	}
+
+	count, cards := 0, 0
+	hands := []protocol.HandDTO{msg.DealerHand}
+	for _, p := range msg.Players {
+		hands = append(hands, p.Hands...)
+	}
+	for _, h := range hands {
+		for _, c := range h.Cards {
+			count += hiLo(c)
+			cards++
+		}
+	}
+	if cards <= ct.roundCards {
+		return false
+	}
+	ct.roundCount = count
+	ct.roundCards = cards
+	return true
+}
+
+// Shuffle starts the count over. Cards still on the table stay counted if the discards were
+// shuffled back in the middle of a round
+func (ct *CountTrainer) Shuffle() {
+	ct.running = 0
+	ct.shuffled = true
+}
+
+func (ct *CountTrainer) RunningCount() int {
+	return ct.running + ct.roundCount
+}
+
+func (ct *CountTrainer) DecksLeft() float64 {
+	return float64(ct.cardsInShoe) / float64(max(ct.deckSize, 1))
+}
+
+func (ct *CountTrainer) TrueCount() float64 {
+	decks := ct.DecksLeft()
+	if decks < 0.5 {
+		// the last few cards would blow the true count up
+		decks = 0.5
+	}
+	return float64(ct.RunningCount()) / decks
+}
+
+// MaybeQuiz asks for the running count every so often
+func (ct *CountTrainer) MaybeQuiz() tea.Cmd {
+	if !ct.Quizzes || ct.asking || rand.IntN(QUIZ_ODDS) != 0 {
+		return nil
+	}
+	ct.asking = true
+	ct.input.Reset()
+	ct.input.Focus()
+	// the server holds the count from here so cards dealt while the player thinks don't count
+	return tea.Batch(SendData(protocol.PackageCountQuiz(nil)), PopUpCmd("Quiz! What is the running count?", protocol.InfoMsg))
+}
+
+func (ct *CountTrainer) Asking() bool {
+	return ct.asking
+}
+
+// Answer sends the player's answer to the server, which keeps its own count and grades it
+func (ct *CountTrainer) Answer() tea.Cmd {
+	guess, err := strconv.Atoi(strings.TrimSpace(ct.input.Value()))
+	if err != nil {
+		return PopUpCmd("The count is a whole number", protocol.WarnMsg)
+	}
+	ct.asking = false
+	ct.input.Blur()
+	return SendData(protocol.PackageCountQuiz(&guess))
+}
+
+// Graded shows how the server marked the player's answer
+func (ct *CountTrainer) Graded(msg protocol.CountQuizDTO) tea.Cmd {
+	ct.asked++
+	if msg.Correct {
+		ct.correct++
+		return PopUpCmd(fmt.Sprintf("Correct! %d/%d this session", ct.correct, ct.asked), protocol.InfoMsg)
+	}
+	return PopUpCmd(fmt.Sprintf("The count was %+d (true count %+.1f). %d/%d this session", msg.Count, msg.TrueCount, ct.correct, ct.asked), protocol.WarnMsg)
+}
+
+func (ct *CountTrainer) Update(msg tea.Msg) tea.Cmd {
+	var cmd tea.Cmd
+	ct.input, cmd = ct.input.Update(msg)
+	return cmd
+}
+
+func (ct *CountTrainer) View() string {
+	if ct.asking {
+		return fmt.Sprintf("Running count?\n%s", ct.input.View())
+	}
+	if !ct.Shown {
+		return ""
+	}
+	return fmt.Sprintf("RC %+d  TC %+.1f  %.1f decks left", ct.RunningCount(), ct.TrueCount(), ct.DecksLeft())
+}
+
+// Reset forgets the shoe when leaving the table
+func (ct *CountTrainer) Reset() {
+	*ct = CountTrainer{Shown: ct.Shown, Quizzes: ct.Quizzes, input: ct.input}
+	ct.input.Blur()
+}
+
+func (t *TuiTable) toggleQuizzes() tea.Cmd {
+	t.trainer.Quizzes = !t.trainer.Quizzes
+	if t.trainer.Quizzes {
+		return PopUpCmd("You will be quizzed on the running count", protocol.InfoMsg)
+	}
+	return PopUpCmd("No more count quizzes", protocol.InfoMsg)
+}
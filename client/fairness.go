@@ -0,0 +1,83 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/dylanmccormick/blackjack-tui/protocol"
+)
+
+// commitShoe starts a hand history for a shoe the server has committed to
+func (t *TuiTable) commitShoe(msg protocol.ShoeCommitDTO) {
+	if t.shoes == nil {
+		t.shoes = map[int]*protocol.ShoeHistoryDTO{}
+	}
+	t.currentShoe = msg.Shoe
+	t.shoes[msg.Shoe] = &protocol.ShoeHistoryDTO{
+		Decks:      msg.Decks,
+		Variant:    msg.Variant,
+		Commitment: msg.Commitment,
+		Rounds:     []protocol.DealtDTO{},
+	}
+}
+
+// recordDealt keeps the cards dealt in a finished round. The same round can show up in several game states
+func (t *TuiTable) recordDealt(dealt *protocol.DealtDTO) {
+	history, ok := t.shoes[t.currentShoe]
+	if dealt == nil || !ok {
+		return
+	}
+	seen := slices.ContainsFunc(history.Rounds, func(r protocol.DealtDTO) bool {
+		return r.Position == dealt.Position
+	})
+	if !seen {
+		history.Rounds = append(history.Rounds, *dealt)
+	}
+}
+
+// revealShoe checks everything we saw from a finished shoe against its revealed seed
+func (t *TuiTable) revealShoe(msg protocol.ShoeRevealDTO) tea.Cmd {
+	history, ok := t.shoes[msg.Shoe]
+	if !ok {
+		// we joined after this shoe was committed to
+		return nil
+	}
+	delete(t.shoes, msg.Shoe)
+	history.Seed = msg.Seed
+	if history.Commitment != msg.Commitment {
+		return PopUpCmd(fmt.Sprintf("Shoe #%d was revealed with a different commitment than the one published", msg.Shoe), protocol.ErrMsg)
+	}
+	err := protocol.VerifyShoeHistory(msg.Seed, *history)
+	if err != nil {
+		slog.Error("Shoe failed verification", "shoe", msg.Shoe, "error", err)
+		return PopUpCmd(fmt.Sprintf("Shoe #%d failed verification: %s", msg.Shoe, err), protocol.ErrMsg)
+	}
+	if err := saveShoeHistory(history); err != nil {
+		slog.Error("Unable to save shoe history", "shoe", msg.Shoe, "error", err)
+	}
+	return PopUpCmd(fmt.Sprintf("Shoe #%d verified: %d rounds match the revealed seed", msg.Shoe, len(history.Rounds)), protocol.InfoMsg)
+}
+
+// saveShoeHistory writes a verified shoe to disk so it can be checked again with `blackjack-tui verify`
+func saveShoeHistory(history *protocol.ShoeHistoryDTO) error {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return err
+	}
+	dir = filepath.Join(dir, "blackjack-tui", "shoes")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(history, "", "  ")
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(dir, history.Commitment[:16]+".json")
+	slog.Info("Saving shoe history", "path", path)
+	return os.WriteFile(path, data, 0o644)
+}
@@ -1,16 +1,19 @@
 package client
 
 import (
+	"fmt"
 	"strings"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/dylanmccormick/blackjack-tui/protocol"
 )
 
 type HeaderModel struct {
 	// Header at the top of the screen. Will display server info. Username Etc
 	Username string
 	State    string
+	Jackpot  int // the progressive jackpot every table shares. 0 until the server tells us
 	Width    int
 	Height   int
 }
@@ -48,6 +51,9 @@ func (hm *HeaderModel) renderBanner() string {
 	var sb strings.Builder
 
 	sb.WriteString(banner)
+	if hm.Jackpot > 0 {
+		sb.WriteString(fmt.Sprintf("JACKPOT %d", hm.Jackpot))
+	}
 	return style.Render(sb.String())
 }
 
@@ -58,6 +64,8 @@ func (hm *HeaderModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		hm.Width = (msg.Width - 8) / 2
 	case AuthPollMsg:
 		hm.Username = msg.UserName
+	case protocol.JackpotDTO:
+		hm.Jackpot = msg.Amount
 	}
 	return hm, cmd
 }
@@ -0,0 +1,199 @@
+package client
+
+import (
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/dylanmccormick/blackjack-tui/protocol"
+)
+
+// HistoryMenuModel pages through the rounds the player has played. Opening a round
+// steps through it one card or decision at a time
+type HistoryMenuModel struct {
+	Commands      map[string]string
+	RoundCommands map[string]string
+	History       protocol.HistoryDTO
+	Round         *protocol.RoundDetailDTO // round being stepped through. nil while showing the list
+	selected      int
+	step          int
+}
+
+func NewHistoryMenu() *HistoryMenuModel {
+	return &HistoryMenuModel{
+		History: protocol.HistoryDTO{},
+		Commands: map[string]string{
+			"j":     "down",
+			"k":     "up",
+			"n":     "next page",
+			"p":     "previous page",
+			"enter": "select",
+			"esc":   "back",
+		},
+		RoundCommands: map[string]string{
+			"l":   "next card",
+			"h":   "previous card",
+			"esc": "back",
+		},
+	}
+}
+
+func (hm *HistoryMenuModel) Init() tea.Cmd {
+	return LoadHistoryCmd(0)
+}
+
+func LoadHistoryCmd(page int) tea.Cmd {
+	return SendData(protocol.PackageClientMessage(protocol.MsgGetHistory, strconv.Itoa(page)))
+}
+
+func (hm *HistoryMenuModel) View() string {
+	if hm.Round != nil {
+		return hm.viewRound()
+	}
+	selectedStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(highlight))
+	view := []string{fmt.Sprintf("Hand History (page %d)\n", hm.History.Page+1)}
+	if len(hm.History.Rounds) == 0 {
+		view = append(view, "No rounds here yet")
+	}
+	for i, r := range hm.History.Rounds {
+		line := fmt.Sprintf("%s  %s round %d  bet %d  paid %d", r.PlayedAt.Local().Format("Jan 02 15:04"), r.Table, r.Round, r.Bet, r.Payout)
+		if i == hm.selected {
+			line = selectedStyle.Render(line)
+		}
+		view = append(view, line)
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, view...)
+}
+
+func (hm *HistoryMenuModel) viewRound() string {
+	if len(hm.Round.Steps) == 0 {
+		return "Nothing was dealt in this round"
+	}
+	step := hm.Round.Steps[hm.step]
+	sb := strings.Builder{}
+	fmt.Fprintf(&sb, "%s round %d  (%d/%d)\n", hm.Round.Table, hm.Round.Round, hm.step+1, len(hm.Round.Steps))
+	commitment := hm.Round.Commitment
+	if len(commitment) > 16 {
+		commitment = commitment[:16]
+	}
+	fmt.Fprintf(&sb, "Shoe commitment %s\n\n", commitment)
+	fmt.Fprintf(&sb, "Dealer: %s\n", renderHistoryHand(step.Game.DealerHand))
+	for _, p := range step.Game.Players {
+		for i, h := range p.Hands {
+			name := p.Name
+			if len(p.Hands) > 1 {
+				name = fmt.Sprintf("%s (%d)", p.Name, i+1)
+			}
+			line := fmt.Sprintf("%s: %s B:%d", name, renderHistoryHand(h), h.Bet)
+			if p.CurrentPlayer && i == p.CurrentHand {
+				line = lipgloss.NewStyle().Foreground(lipgloss.Color(highlight)).Render(line)
+			}
+			fmt.Fprintf(&sb, "%s\n", line)
+		}
+	}
+	fmt.Fprintf(&sb, "\n%s", step.Description)
+	return sb.String()
+}
+
+// renderHistoryHand shows a hand on one line. A value of -1 means the hole card is still face down
+func renderHistoryHand(h protocol.HandDTO) string {
+	cards := []string{}
+	for _, c := range h.Cards {
+		cards = append(cards, CardToCard(c).String())
+	}
+	if h.Value == -1 {
+		return fmt.Sprintf("%s ??", strings.Join(cards, " "))
+	}
+	return fmt.Sprintf("%s (%d)", strings.Join(cards, " "), h.Value)
+}
+
+func (hm *HistoryMenuModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	var cmds []tea.Cmd
+	switch msg := msg.(type) {
+	case ChangeMenuPage:
+		hm.Round = nil
+		hm.selected = 0
+		cmds = append(cmds, AddCommands(hm.Commands), LoadHistoryCmd(0))
+	case ChangeRootPageMsg:
+		cmds = append(cmds, AddCommands(hm.Commands))
+	case protocol.HistoryDTO:
+		slog.Info("Updating hand history", "page", msg.Page, "rounds", len(msg.Rounds))
+		if len(msg.Rounds) == 0 && msg.Page > 0 {
+			// ran off the end. Stay on the last page
+			break
+		}
+		hm.History = msg
+		hm.selected = 0
+	case protocol.RoundDetailDTO:
+		hm.Round = &msg
+		hm.step = 0
+		cmds = append(cmds, AddCommands(hm.RoundCommands))
+	case tea.KeyMsg:
+		if hm.Round != nil {
+			cmds = append(cmds, hm.updateRound(msg))
+			break
+		}
+		switch msg.Type {
+		case tea.KeyEsc:
+			cmds = append(cmds, ChangeMenuPageCmd(mainMenu))
+		case tea.KeyEnter:
+			if hm.selected < len(hm.History.Rounds) {
+				id := hm.History.Rounds[hm.selected].ID
+				cmds = append(cmds, SendData(protocol.PackageClientMessage(protocol.MsgGetRound, strconv.FormatInt(id, 10))))
+			}
+		case tea.KeyRunes:
+			switch string(msg.Runes) {
+			case "j":
+				if hm.selected+1 < len(hm.History.Rounds) {
+					hm.selected += 1
+				}
+			case "k":
+				if hm.selected-1 >= 0 {
+					hm.selected -= 1
+				}
+			case "n":
+				cmds = append(cmds, LoadHistoryCmd(hm.History.Page+1))
+			case "p":
+				if hm.History.Page > 0 {
+					cmds = append(cmds, LoadHistoryCmd(hm.History.Page-1))
+				}
+			}
+		}
+	}
+	return hm, tea.Batch(cmds...)
+}
+
+func (hm *HistoryMenuModel) updateRound(msg tea.KeyMsg) tea.Cmd {
+	switch msg.Type {
+	case tea.KeyEsc:
+		hm.Round = nil
+		return AddCommands(hm.Commands)
+	case tea.KeyRight:
+		hm.nextStep()
+	case tea.KeyLeft:
+		hm.prevStep()
+	case tea.KeyRunes:
+		switch string(msg.Runes) {
+		case "l":
+			hm.nextStep()
+		case "h":
+			hm.prevStep()
+		}
+	}
+	return nil
+}
+
+func (hm *HistoryMenuModel) nextStep() {
+	if hm.step+1 < len(hm.Round.Steps) {
+		hm.step += 1
+	}
+}
+
+func (hm *HistoryMenuModel) prevStep() {
+	if hm.step-1 >= 0 {
+		hm.step -= 1
+	}
+}
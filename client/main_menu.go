@@ -21,6 +21,7 @@ func NewMainMenu() *MainMenuModel {
 			"Tables",
 			// "Settings",
 			"Stats",
+			"History",
 		},
 		Commands: map[string]string{
 			"j":     "down",
@@ -69,6 +70,8 @@ func (mm *MainMenuModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				newPage = settingsMenu
 			case "Stats":
 				newPage = statsMenu
+			case "History":
+				newPage = historyMenu
 			}
 			slog.Debug("New page:",  "page", newPage)
 			cmds = append(cmds, ChangeMenuPageCmd(newPage))
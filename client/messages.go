@@ -27,6 +27,20 @@ func ReceiveMessage(sub <-chan *protocol.TransportMessage) tea.Cmd {
 				slog.Error("error unmarshalling body", "error", err)
 			}
 			return body
+		case protocol.MsgTournamentList:
+			body := []*protocol.TournamentDTO{}
+			err := json.Unmarshal(msg.Data, &body)
+			if err != nil {
+				slog.Error("error unmarshalling body", "error", err)
+			}
+			return body
+		case protocol.MsgJackpot:
+			body := protocol.JackpotDTO{}
+			err := json.Unmarshal(msg.Data, &body)
+			if err != nil {
+				slog.Error("error unmarshalling body", "error", err)
+			}
+			return body
 		case protocol.MsgPopUp:
 			body := protocol.PopUpDTO{}
 			err := json.Unmarshal(msg.Data, &body)
@@ -42,6 +56,62 @@ func ReceiveMessage(sub <-chan *protocol.TransportMessage) tea.Cmd {
 			}
 			slog.Info("Parsed user stats", "body", body)
 			return body
+		case protocol.MsgShuffle:
+			body := protocol.ShuffleDTO{}
+			err := json.Unmarshal(msg.Data, &body)
+			if err != nil {
+				slog.Error("error unmarshalling body", "error", err)
+			}
+			return body
+		case protocol.MsgShoeCommit:
+			body := protocol.ShoeCommitDTO{}
+			err := json.Unmarshal(msg.Data, &body)
+			if err != nil {
+				slog.Error("error unmarshalling body", "error", err)
+			}
+			return body
+		case protocol.MsgShoeReveal:
+			body := protocol.ShoeRevealDTO{}
+			err := json.Unmarshal(msg.Data, &body)
+			if err != nil {
+				slog.Error("error unmarshalling body", "error", err)
+			}
+			return body
+		case protocol.MsgHistory:
+			body := protocol.HistoryDTO{}
+			err := json.Unmarshal(msg.Data, &body)
+			if err != nil {
+				slog.Error("error unmarshalling body", "error", err)
+			}
+			return body
+		case protocol.MsgRound:
+			body := protocol.RoundDetailDTO{}
+			err := json.Unmarshal(msg.Data, &body)
+			if err != nil {
+				slog.Error("error unmarshalling body", "error", err)
+			}
+			return body
+		case protocol.MsgStrategy:
+			body := protocol.StrategyDTO{}
+			err := json.Unmarshal(msg.Data, &body)
+			if err != nil {
+				slog.Error("error unmarshalling body", "error", err)
+			}
+			return body
+		case protocol.MsgCountQuiz:
+			body := protocol.CountQuizDTO{}
+			err := json.Unmarshal(msg.Data, &body)
+			if err != nil {
+				slog.Error("error unmarshalling body", "error", err)
+			}
+			return body
+		case protocol.MsgBetError:
+			body := protocol.BetErrorDTO{}
+			err := json.Unmarshal(msg.Data, &body)
+			if err != nil {
+				slog.Error("error unmarshalling body", "error", err)
+			}
+			return body
 		}
 
 		return nil
@@ -0,0 +1,162 @@
+package client
+
+import (
+	"fmt"
+	"log/slog"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/dylanmccormick/blackjack-tui/protocol"
+)
+
+const (
+	MIN_REPLAY_SPEED = 100 * time.Millisecond
+	MAX_REPLAY_SPEED = 10 * time.Second
+)
+
+var REPLAY_COMMANDS = map[string]string{
+	"space": "pause",
+	"l":     "step forward",
+	"h":     "step back",
+	"+":     "faster",
+	"-":     "slower",
+	"q":     "quit",
+}
+
+// ReplayModel plays a recorded round back through the table view one step at a time
+type ReplayModel struct {
+	round  protocol.RoundDetailDTO
+	step   int
+	speed  time.Duration
+	paused bool
+	table  *TuiTable
+	footer tea.Model
+	tickID int // bumped whenever the player takes over so older ticks are dropped
+}
+
+type replayTickMsg struct {
+	id int
+}
+
+func NewReplayModel(round protocol.RoundDetailDTO, speed time.Duration) *ReplayModel {
+	rm := &ReplayModel{
+		round:  round,
+		speed:  min(max(speed, MIN_REPLAY_SPEED), MAX_REPLAY_SPEED),
+		footer: NewFooter(3, 80),
+	}
+	rm.showStep()
+	return rm
+}
+
+func (rm *ReplayModel) Init() tea.Cmd {
+	return tea.Batch(AddCommands(REPLAY_COMMANDS), rm.tick())
+}
+
+func (rm *ReplayModel) tick() tea.Cmd {
+	id := rm.tickID
+	return tea.Tick(rm.speed, func(time.Time) tea.Msg {
+		return replayTickMsg{id}
+	})
+}
+
+// showStep draws the current step on a fresh table. The table keeps old cards around
+// between rounds, which would show up when stepping back
+func (rm *ReplayModel) showStep() {
+	rm.table = NewTable(20, 80)
+	if len(rm.round.Steps) == 0 {
+		return
+	}
+	rm.table.GameMessageToState(&rm.round.Steps[rm.step].Game)
+}
+
+func (rm *ReplayModel) moveTo(step int) {
+	if step < 0 || step >= len(rm.round.Steps) {
+		return
+	}
+	rm.step = step
+	rm.showStep()
+}
+
+func (rm *ReplayModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	var cmds []tea.Cmd
+	var cmd tea.Cmd
+	switch msg := msg.(type) {
+	case replayTickMsg:
+		if msg.id != rm.tickID || rm.paused {
+			break
+		}
+		if rm.step+1 >= len(rm.round.Steps) {
+			rm.paused = true
+			break
+		}
+		rm.moveTo(rm.step + 1)
+		cmds = append(cmds, rm.tick())
+	case tea.KeyMsg:
+		switch msg.Type {
+		case tea.KeyCtrlC, tea.KeyEsc:
+			return rm, tea.Quit
+		case tea.KeySpace:
+			rm.paused = !rm.paused
+			rm.tickID++
+			if !rm.paused {
+				cmds = append(cmds, rm.tick())
+			}
+		case tea.KeyRight:
+			rm.pause()
+			rm.moveTo(rm.step + 1)
+		case tea.KeyLeft:
+			rm.pause()
+			rm.moveTo(rm.step - 1)
+		case tea.KeyRunes:
+			switch string(msg.Runes) {
+			case "q":
+				return rm, tea.Quit
+			case "l":
+				rm.pause()
+				rm.moveTo(rm.step + 1)
+			case "h":
+				rm.pause()
+				rm.moveTo(rm.step - 1)
+			case "+":
+				rm.speed = max(rm.speed/2, MIN_REPLAY_SPEED)
+			case "-":
+				rm.speed = min(rm.speed*2, MAX_REPLAY_SPEED)
+			}
+		}
+	}
+	rm.footer, cmd = rm.footer.Update(msg)
+	cmds = append(cmds, cmd)
+	return rm, tea.Batch(cmds...)
+}
+
+func (rm *ReplayModel) pause() {
+	rm.paused = true
+	rm.tickID++
+}
+
+func (rm *ReplayModel) View() string {
+	titleStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(highlight))
+	status := fmt.Sprintf("every %s", rm.speed)
+	if rm.paused {
+		status = "paused"
+	}
+	title := fmt.Sprintf("%s round %d (#%d)  step %d/%d  %s", rm.round.Table, rm.round.Round, rm.round.ID, rm.step+1, len(rm.round.Steps), status)
+	description := "Nothing was dealt in this round"
+	if len(rm.round.Steps) > 0 {
+		description = rm.round.Steps[rm.step].Description
+	}
+	return lipgloss.JoinVertical(lipgloss.Center, titleStyle.Render(title), rm.table.View(), description, rm.footer.View())
+}
+
+// RunReplay plays a recorded round in the terminal. No server is needed
+func RunReplay(round protocol.RoundDetailDTO, speed time.Duration) error {
+	f, err := tea.LogToFile("debug.log", "debug")
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	slog.Debug("replaying round", "id", round.ID, "steps", len(round.Steps))
+	_, err = tea.NewProgram(NewReplayModel(round, speed)).Run()
+	return err
+}
@@ -0,0 +1,128 @@
+package client
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/dylanmccormick/blackjack-tui/protocol"
+)
+
+func replayHelper(steps int) *ReplayModel {
+	round := protocol.RoundDetailDTO{ID: 1, Table: "test", Round: 1, Steps: []protocol.RoundStepDTO{}}
+	for i := range steps {
+		round.Steps = append(round.Steps, protocol.RoundStepDTO{Description: fmt.Sprintf("step %d", i+1)})
+	}
+	return NewReplayModel(round, time.Second)
+}
+
+func keyRunes(key string) tea.KeyMsg {
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)}
+}
+
+func TestReplayTicks(t *testing.T) {
+	rm := replayHelper(3)
+	rm.Update(replayTickMsg{rm.tickID})
+	if rm.step != 1 {
+		t.Fatalf("Expected a tick to move to the next step. got=%d", rm.step)
+	}
+	// a tick from before the player took over is dropped
+	stale := replayTickMsg{rm.tickID}
+	rm.Update(tea.KeyMsg{Type: tea.KeySpace})
+	rm.Update(tea.KeyMsg{Type: tea.KeySpace})
+	rm.Update(stale)
+	if rm.step != 1 {
+		t.Errorf("Expected an old tick to be ignored. got=%d", rm.step)
+	}
+	rm.Update(replayTickMsg{rm.tickID})
+	rm.Update(replayTickMsg{rm.tickID})
+	if rm.step != 2 || !rm.paused {
+		t.Errorf("Expected the replay to stop on the last step. step=%d paused=%t", rm.step, rm.paused)
+	}
+}
+
+func TestReplayPause(t *testing.T) {
+	rm := replayHelper(3)
+	rm.Update(tea.KeyMsg{Type: tea.KeySpace})
+	if !rm.paused {
+		t.Fatalf("Expected space to pause the replay")
+	}
+	rm.Update(replayTickMsg{rm.tickID})
+	if rm.step != 0 {
+		t.Errorf("Expected a paused replay to stay on its step. got=%d", rm.step)
+	}
+	_, cmd := rm.Update(tea.KeyMsg{Type: tea.KeySpace})
+	if rm.paused || cmd == nil {
+		t.Errorf("Expected space to start the replay again")
+	}
+	rm.Update(replayTickMsg{rm.tickID})
+	if rm.step != 1 {
+		t.Errorf("Expected the replay to move on after it is started again. got=%d", rm.step)
+	}
+}
+
+func TestReplayStep(t *testing.T) {
+	rm := replayHelper(3)
+	tests := []struct {
+		key      tea.KeyMsg
+		expected int
+	}{
+		{keyRunes("l"), 1},
+		{tea.KeyMsg{Type: tea.KeyRight}, 2},
+		{keyRunes("l"), 2},
+		{keyRunes("h"), 1},
+		{tea.KeyMsg{Type: tea.KeyLeft}, 0},
+		{keyRunes("h"), 0},
+	}
+	for _, tt := range tests {
+		rm.Update(tt.key)
+		if rm.step != tt.expected {
+			t.Errorf("step incorrect after %s. expected=%d got=%d", tt.key, tt.expected, rm.step)
+		}
+		if !rm.paused {
+			t.Errorf("Expected stepping by hand to pause the replay")
+		}
+	}
+	if rm.round.Steps[rm.step].Description != "step 1" {
+		t.Errorf("Expected the first step. got=%s", rm.round.Steps[rm.step].Description)
+	}
+
+	empty := replayHelper(0)
+	empty.Update(keyRunes("l"))
+	empty.Update(replayTickMsg{empty.tickID})
+	if empty.step != 0 || !empty.paused {
+		t.Errorf("Expected a round without steps to stay put. step=%d paused=%t", empty.step, empty.paused)
+	}
+}
+
+func TestReplaySpeed(t *testing.T) {
+	rm := replayHelper(3)
+	rm.Update(keyRunes("+"))
+	if rm.speed != 500*time.Millisecond {
+		t.Errorf("Expected + to halve the time between steps. got=%s", rm.speed)
+	}
+	rm.Update(keyRunes("-"))
+	rm.Update(keyRunes("-"))
+	if rm.speed != 2*time.Second {
+		t.Errorf("Expected - to double the time between steps. got=%s", rm.speed)
+	}
+	for range 10 {
+		rm.Update(keyRunes("-"))
+	}
+	if rm.speed != MAX_REPLAY_SPEED {
+		t.Errorf("Expected the speed to stop at the slowest. got=%s", rm.speed)
+	}
+	for range 10 {
+		rm.Update(keyRunes("+"))
+	}
+	if rm.speed != MIN_REPLAY_SPEED {
+		t.Errorf("Expected the speed to stop at the fastest. got=%s", rm.speed)
+	}
+
+	slow := NewReplayModel(protocol.RoundDetailDTO{}, time.Hour)
+	fast := NewReplayModel(protocol.RoundDetailDTO{}, time.Millisecond)
+	if slow.speed != MAX_REPLAY_SPEED || fast.speed != MIN_REPLAY_SPEED {
+		t.Errorf("Expected the starting speed to be kept in range. got=%s %s", slow.speed, fast.speed)
+	}
+}
@@ -21,6 +21,7 @@ type (
 		TableMenu    tea.Model
 		SettingsMenu tea.Model
 		StatsMenu    tea.Model
+		HistoryMenu  tea.Model
 		Height       int
 		Width        int
 		commands     map[string]string
@@ -33,6 +34,7 @@ const (
 	tableMenu
 	settingsMenu
 	statsMenu
+	historyMenu
 )
 
 var dialogueCommands = map[string]string{
@@ -49,6 +51,7 @@ func NewMenuModel() *MenuModel {
 		TableMenu:     NewTableMenu(0, 0),
 		SettingsMenu:  NewSettingsMenu(),
 		StatsMenu:     NewStatsMenu(),
+		HistoryMenu:   NewHistoryMenu(),
 	}
 }
 
@@ -81,6 +84,8 @@ func (mm *MenuModel) View() string {
 		view = mm.SettingsMenu.View()
 	case statsMenu:
 		view = mm.StatsMenu.View()
+	case historyMenu:
+		view = mm.HistoryMenu.View()
 	}
 	return viewStyle.Render(view)
 }
@@ -113,6 +118,9 @@ func (mm *MenuModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case statsMenu:
 		mm.StatsMenu, cmd = mm.StatsMenu.Update(msg)
 		cmds = append(cmds, cmd)
+	case historyMenu:
+		mm.HistoryMenu, cmd = mm.HistoryMenu.Update(msg)
+		cmds = append(cmds, cmd)
 	}
 	return mm, tea.Batch(cmds...)
 }
@@ -40,8 +40,13 @@ func (sm *StatsMenuModel) View() string {
 	fmt.Fprintf(&sb, "Hands Played: %d\n", sm.Stats.HandsPlayed)
 	fmt.Fprintf(&sb, "Hands Won: %d\n", sm.Stats.HandsWon)
 	fmt.Fprintf(&sb, "Hands Lost: %d\n", sm.Stats.HandsLost)
+	fmt.Fprintf(&sb, "Hands Surrendered: %d\n", sm.Stats.Surrendered)
 	fmt.Fprintf(&sb, "Win Percentage: %d%%\n", sm.Stats.WinPercentage)
 	fmt.Fprintf(&sb, "Total Blackjacks: %d\n", sm.Stats.Blackjacks)
+	fmt.Fprintf(&sb, "Side Bets Won: %d of %d\n", sm.Stats.SideBetsWon, sm.Stats.SideBets)
+	fmt.Fprintf(&sb, "Timeouts: %d\n", sm.Stats.Timeouts)
+	fmt.Fprintf(&sb, "Basic Strategy Accuracy: %d%% (%d decisions)\n", sm.Stats.StrategyAccuracy, sm.Stats.StrategyDecisions)
+	fmt.Fprintf(&sb, "Count Quiz Accuracy: %d%% (%d quizzes)\n", sm.Stats.CountAccuracy, sm.Stats.CountQuizzes)
 	return sb.String()
 }
 
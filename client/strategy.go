@@ -0,0 +1,33 @@
+package client
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/dylanmccormick/blackjack-tui/protocol"
+)
+
+// reviewStrategy shows what basic strategy says about a decision. Hints always show up.
+// Finished decisions only do when the player asked to have their mistakes flagged
+func (t *TuiTable) reviewStrategy(msg protocol.StrategyDTO) tea.Cmd {
+	if msg.Action == "" {
+		return PopUpCmd(fmt.Sprintf("Basic strategy says: %s", describeAction(msg.Advice)), protocol.InfoMsg)
+	}
+	if !t.flagMistakes || msg.Action == msg.Advice {
+		return nil
+	}
+	return PopUpCmd(fmt.Sprintf("Basic strategy says %s, not %s", describeAction(msg.Advice), describeAction(msg.Action)), protocol.WarnMsg)
+}
+
+func (t *TuiTable) toggleFlagMistakes() tea.Cmd {
+	t.flagMistakes = !t.flagMistakes
+	if t.flagMistakes {
+		return PopUpCmd("Flagging plays that differ from basic strategy", protocol.InfoMsg)
+	}
+	return PopUpCmd("No longer flagging plays that differ from basic strategy", protocol.InfoMsg)
+}
+
+func describeAction(action string) string {
+	return strings.ReplaceAll(action, "_", " ")
+}
@@ -3,6 +3,8 @@ package client
 import (
 	"fmt"
 	"log/slog"
+	"strconv"
+	"strings"
 
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
@@ -11,13 +13,21 @@ import (
 )
 
 type TuiTable struct {
-	Players    []TuiPlayer
-	Height     int
-	Width      int
-	Commands   map[string]string
-	betInput   textinput.Model
-	commandSet bool
-	username   string
+	Players      []TuiPlayer
+	Height       int
+	Width        int
+	Commands     map[string]string
+	betInput     textinput.Model
+	inputAction  string // message type sent when the bet input is submitted
+	commandSet   bool
+	username     string
+	rules        protocol.RulesDTO
+	variant      string
+	shoes        map[int]*protocol.ShoeHistoryDTO // what we saw from each shoe still waiting on its reveal
+	currentShoe  int
+	flagMistakes bool // warn when a play differs from basic strategy
+	autoPlay     bool // the table is betting and playing for us
+	trainer      *CountTrainer
 }
 
 var GAME_COMMANDS = map[string]string{
@@ -25,24 +35,47 @@ var GAME_COMMANDS = map[string]string{
 	"b": "place bet",
 	"h": "hit",
 	"s": "stand",
+	"d": "double down",
+	"p": "split",
+	"i": "insurance",
+	"e": "even money",
+	"c": "no insurance",
+	"r": "surrender",
+	"?": "hint",
+	"m": "flag mistakes",
+	"t": "show count",
+	"T": "count quiz",
+	"o": "auto-play",
 }
 
 func NewTable(height, width int) *TuiTable {
 	betText := textinput.New()
 	betText.Placeholder = "5"
-	betText.Width = 5
+	betText.Width = 12
 	return &TuiTable{
-		Players: []TuiPlayer{{Name: "dealer", Cards: []*Card{}}, {}, {}, {}, {}, {}},
+		Players: []TuiPlayer{{Name: "dealer", Hands: []TuiHand{}}, {}, {}, {}, {}, {}},
 		Commands: map[string]string{
 			"n": "start game",
 			"b": "place bet",
 			"h": "hit",
 			"s": "stand",
+			"d": "double down",
+			"p": "split",
+			"i": "insurance",
+			"e": "even money",
+			"c": "no insurance",
+			"?": "hint",
+			"m": "flag mistakes",
+			"t": "show count",
+			"T": "count quiz",
+			"o": "auto-play",
 			"L": "leave server",
 		},
-		betInput: betText,
-		Height:   height,
-		Width:    width,
+		trainer:     NewCountTrainer(),
+		betInput:    betText,
+		inputAction: protocol.MsgPlaceBet,
+		Height:      height,
+		Width:       width,
 	}
 }
 
@@ -51,37 +84,228 @@ func (t *TuiTable) Init() tea.Cmd {
 }
 
 func (t *TuiTable) GameMessageToState(msg *protocol.GameDTO) {
+	mySpots := 0
+	for _, p := range msg.Players {
+		if p.Name == t.username {
+			mySpots++
+		}
+	}
 	for i := 1; i < 6; i++ {
 		player := t.Players[i]
 		if len(msg.Players) < i {
 			break
 		}
 		receivedPlayer := msg.Players[i-1]
-		if len(receivedPlayer.Hand.Cards) > 0 {
-			player.Cards = []*Card{}
-			player.Value = receivedPlayer.Hand.Value
-		}
-		for _, card := range receivedPlayer.Hand.Cards {
-			player.Cards = append(player.Cards, CardToCard(card))
+		// keep showing the last round's cards until new ones are dealt
+		if len(receivedPlayer.Hands) > 0 && len(receivedPlayer.Hands[0].Cards) > 0 {
+			player.Hands = []TuiHand{}
+			for _, hand := range receivedPlayer.Hands {
+				player.Hands = append(player.Hands, HandToTuiHand(hand))
+			}
 		}
 		player.Bet = receivedPlayer.Bet
 		player.Wallet = receivedPlayer.Wallet
 		player.Name = receivedPlayer.Name
 		player.Current = receivedPlayer.CurrentPlayer
+		player.CurrentHand = receivedPlayer.CurrentHand
+		player.Insurance = receivedPlayer.Insurance
+		player.Jackpot = receivedPlayer.Jackpot
+		player.SideBets = receivedPlayer.SideBets
+		player.Bot = receivedPlayer.Bot
+		player.Spot = receivedPlayer.Spot
+		player.Acting = receivedPlayer.Acting && receivedPlayer.Name == t.username && mySpots > 1
+		player.AutoPlay = receivedPlayer.AutoPlay
+		player.SittingOut = receivedPlayer.SittingOut
 		slog.Info("Adding player to board", "player", player.Name)
 		t.Players[i] = player
 	}
+	t.rules = msg.Rules
 	dealer := t.Players[0]
-	if len(msg.DealerHand.Cards) > 0 {
-		dealer.Cards = []*Card{}
-	}
-	for _, card := range msg.DealerHand.Cards {
-		dealer.Cards = append(dealer.Cards, CardToCard(card))
-		dealer.Value = msg.DealerHand.Value
+	if len(msg.DealerHand.Cards) > 0 || msg.DealerHand.Hidden > 0 {
+		dealer.Hands = []TuiHand{HandToTuiHand(msg.DealerHand)}
 	}
 	t.Players[0] = dealer
 }
 
+// announceHands calls out a house rule the moment one of our hands makes it
+func (t *TuiTable) announceHands(msg *protocol.GameDTO) []tea.Cmd {
+	cmds := []tea.Cmd{}
+	for i, p := range msg.Players {
+		if p.Name != t.username || i+1 >= len(t.Players) {
+			continue
+		}
+		seen := t.Players[i+1].Hands
+		for j, h := range p.Hands {
+			if j < len(seen) && seen[j].State == h.State {
+				continue
+			}
+			switch h.State {
+			case "Charlie":
+				cmds = append(cmds, PopUpCmd(fmt.Sprintf("%d Card Charlie!", msg.Rules.Charlie), protocol.InfoMsg))
+			case "SixSevenEight":
+				cmds = append(cmds, PopUpCmd(fmt.Sprintf("6-7-8! Pays %d to 1", msg.Rules.Bonus678), protocol.InfoMsg))
+			case "SevenSevenSeven":
+				cmds = append(cmds, PopUpCmd(fmt.Sprintf("7-7-7! Pays %d to 1", msg.Rules.Bonus777), protocol.InfoMsg))
+			}
+		}
+	}
+	return cmds
+}
+
+// updateSurrenderCommand only shows surrender in the footer while the server allows it.
+// Returns true if the commands changed
+func (t *TuiTable) updateSurrenderCommand(msg *protocol.GameDTO) bool {
+	canSurrender := false
+	for _, p := range msg.Players {
+		if p.Name == t.username && p.Acting {
+			canSurrender = p.CanSurrender
+		}
+	}
+	_, shown := t.Commands["r"]
+	if canSurrender == shown {
+		return false
+	}
+	if canSurrender {
+		t.Commands["r"] = "surrender"
+	} else {
+		delete(t.Commands, "r")
+	}
+	return true
+}
+
+// updateJackpotCommand only shows the jackpot bet at tables that offer it. Returns true if the commands changed
+func (t *TuiTable) updateJackpotCommand(msg *protocol.GameDTO) bool {
+	command := ""
+	if msg.Rules.JackpotBet > 0 {
+		command = fmt.Sprintf("jackpot bet (%d)", msg.Rules.JackpotBet)
+	}
+	if t.Commands["j"] == command {
+		return false
+	}
+	if command == "" {
+		delete(t.Commands, "j")
+	} else {
+		t.Commands["j"] = command
+	}
+	return true
+}
+
+// updateSpotCommands only shows the spot commands at tables that let a player take more than one.
+// Returns true if the commands changed
+func (t *TuiTable) updateSpotCommands(msg *protocol.GameDTO) bool {
+	multiSpot := msg.Rules.MaxSpots > 1
+	_, shown := t.Commands["a"]
+	if multiSpot == shown {
+		return false
+	}
+	if multiSpot {
+		t.Commands["a"] = "add spot"
+		t.Commands["x"] = "drop spot"
+	} else {
+		delete(t.Commands, "a")
+		delete(t.Commands, "x")
+	}
+	return true
+}
+
+// updateAutoPlayCommand flips the auto-play command to match what the server is doing for us.
+// Returns true if the commands changed
+func (t *TuiTable) updateAutoPlayCommand(msg *protocol.GameDTO) bool {
+	autoPlay := false
+	for _, p := range msg.Players {
+		if p.Name == t.username {
+			autoPlay = p.AutoPlay
+		}
+	}
+	if autoPlay == t.autoPlay {
+		return false
+	}
+	t.autoPlay = autoPlay
+	if autoPlay {
+		t.Commands["o"] = "stop auto-play"
+	} else {
+		t.Commands["o"] = "auto-play"
+	}
+	return true
+}
+
+// actingSpot is which of our spots the game is waiting on. nil leaves it to the server
+func (t *TuiTable) actingSpot() *int {
+	for _, p := range t.Players {
+		if p.Acting {
+			spot := p.Spot
+			return &spot
+		}
+	}
+	return nil
+}
+
+// sideBetNames are the side bets the table takes, in the order they are typed after the main bet
+func (t *TuiTable) sideBetNames() []string {
+	names := []string{}
+	for _, name := range []string{"perfect_pairs", "21+3"} {
+		if len(t.rules.SidePayouts[name]) > 0 {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// validateBet checks a bet against the table limits before it is sent to the server. The main
+// bet can be followed by an amount for each side bet the table takes
+func (t *TuiTable) validateBet(input string) (protocol.BetDTO, error) {
+	out := protocol.BetDTO{SideBets: map[string]int{}}
+	fields := strings.Fields(input)
+	if len(fields) == 0 {
+		return out, fmt.Errorf("Bet must be a positive number")
+	}
+	bet, err := strconv.Atoi(fields[0])
+	if err != nil || bet < 1 {
+		return out, fmt.Errorf("Bet must be a positive number")
+	}
+	if bet < t.rules.MinBet {
+		return out, fmt.Errorf("Minimum bet at this table is %d", t.rules.MinBet)
+	}
+	if t.rules.MaxBet > 0 && bet > t.rules.MaxBet {
+		return out, fmt.Errorf("Maximum bet at this table is %d", t.rules.MaxBet)
+	}
+	if t.rules.BetIncrement > 1 && bet%t.rules.BetIncrement != 0 {
+		return out, fmt.Errorf("Bets at this table must be in multiples of %d", t.rules.BetIncrement)
+	}
+	out.Main = bet
+	out.Spot = t.actingSpot()
+	names := t.sideBetNames()
+	if len(fields)-1 > len(names) {
+		return out, fmt.Errorf("This table takes %d side bets", len(names))
+	}
+	total := bet
+	for i, field := range fields[1:] {
+		amount, err := strconv.Atoi(field)
+		if err != nil || amount < 0 {
+			return out, fmt.Errorf("Side bets must be a positive number")
+		}
+		if amount > bet {
+			return out, fmt.Errorf("Side bets cannot be more than your main bet")
+		}
+		out.SideBets[names[i]] = amount
+		total += amount
+	}
+	for _, p := range t.Players {
+		if p.Name == t.username && total > p.Wallet {
+			return out, fmt.Errorf("Bet cannot be higher than current wallet amount")
+		}
+	}
+	return out, nil
+}
+
+func HandToTuiHand(h protocol.HandDTO) TuiHand {
+	hand := TuiHand{Cards: []*Card{}, Value: h.Value, Bet: h.Bet, Hidden: h.Hidden, State: h.State}
+	for _, card := range h.Cards {
+		hand.Cards = append(hand.Cards, CardToCard(card))
+	}
+	return hand
+}
+
 func (t *TuiTable) Resize(height, width int) {
 	t.Height = height
 	t.Width = width
@@ -100,9 +324,45 @@ func (t *TuiTable) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case TextFocusMsg:
 		t.betInput.Focus()
 	case *protocol.GameDTO:
+		cmds = append(cmds, t.announceHands(msg)...)
 		t.GameMessageToState(msg)
+		t.recordDealt(msg.Dealt)
+		if t.trainer.Observe(msg) && !t.betInput.Focused() {
+			cmds = append(cmds, t.trainer.MaybeQuiz())
+		}
+		variantChanged := t.updateVariantCommands(msg)
+		jackpotChanged := t.updateJackpotCommand(msg)
+		spotsChanged := t.updateSpotCommands(msg)
+		autoPlayChanged := t.updateAutoPlayCommand(msg)
+		if t.updateSurrenderCommand(msg) || variantChanged || jackpotChanged || spotsChanged || autoPlayChanged {
+			cmds = append(cmds, AddCommands(t.Commands))
+		}
 	case SaveBetMsg:
-		cmds = append(cmds, SendData(protocol.PackageClientMessage(protocol.MsgPlaceBet, t.betInput.Value())))
+		if t.inputAction == protocol.MsgPlaceBet {
+			bet, err := t.validateBet(t.betInput.Value())
+			if err != nil {
+				cmds = append(cmds, PopUpCmd(err.Error(), protocol.WarnMsg), TextFocusCmd())
+				break
+			}
+			cmds = append(cmds, SendData(protocol.PackageBet(bet)))
+			break
+		}
+		cmds = append(cmds, SendData(protocol.PackageClientMessage(t.inputAction, t.betInput.Value())))
+	case protocol.ShuffleDTO:
+		t.trainer.Shuffle()
+		cmds = append(cmds, PopUpCmd("Dealer is shuffling the shoe", protocol.InfoMsg))
+	case protocol.ShoeCommitDTO:
+		t.commitShoe(msg)
+	case protocol.ShoeRevealDTO:
+		cmds = append(cmds, t.revealShoe(msg))
+	case protocol.StrategyDTO:
+		cmds = append(cmds, t.reviewStrategy(msg))
+	case protocol.CountQuizDTO:
+		cmds = append(cmds, t.trainer.Graded(msg))
+	case protocol.BetErrorDTO:
+		// the server turned the bet down. Let the player try again
+		t.inputAction = protocol.MsgPlaceBet
+		cmds = append(cmds, PopUpCmd(msg.Message, protocol.WarnMsg), TextFocusCmd())
 	case tea.KeyMsg:
 		// Top Level Keys. Kill the program type keys
 		switch msg.Type {
@@ -110,17 +370,49 @@ func (t *TuiTable) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			if t.betInput.Focused() {
 				cmds = append(cmds, SaveBetCmd())
 				t.betInput.Blur()
+			} else if t.trainer.Asking() {
+				cmds = append(cmds, t.trainer.Answer())
 			}
 		case tea.KeyRunes:
 			switch string(msg.Runes) {
 			case "n":
 				cmds = append(cmds, SendData(protocol.PackageClientMessage(protocol.MsgStartGame, "")))
 			case "b":
+				t.inputAction = protocol.MsgPlaceBet
 				cmds = append(cmds, TextFocusCmd())
 			case "h":
 				cmds = append(cmds, SendData(protocol.PackageClientMessage(protocol.MsgHit, "")))
 			case "s":
 				cmds = append(cmds, SendData(protocol.PackageClientMessage(protocol.MsgStand, "")))
+			case "d":
+				cmds = append(cmds, SendData(protocol.PackageClientMessage(protocol.MsgDoubleDown, "")))
+			case "p":
+				cmds = append(cmds, SendData(protocol.PackageClientMessage(protocol.MsgSplit, "")))
+			case "i":
+				t.inputAction = protocol.MsgInsurance
+				cmds = append(cmds, TextFocusCmd())
+			case "e":
+				cmds = append(cmds, SendData(protocol.PackageClientMessage(protocol.MsgEvenMoney, "")))
+			case "c":
+				cmds = append(cmds, SendData(protocol.PackageClientMessage(protocol.MsgNoInsurance, "")))
+			case "r":
+				cmds = append(cmds, SendData(protocol.PackageClientMessage(protocol.MsgSurrender, "")))
+			case "j":
+				cmds = append(cmds, SendData(protocol.PackageClientMessage(protocol.MsgJackpotBet, "")))
+			case "a":
+				cmds = append(cmds, SendData(protocol.PackageClientMessage(protocol.MsgAddSpot, "")))
+			case "x":
+				cmds = append(cmds, SendData(protocol.PackageClientMessage(protocol.MsgRemoveSpot, "")))
+			case "o":
+				cmds = append(cmds, SendData(protocol.PackageClientMessage(protocol.MsgAutoPlay, strconv.FormatBool(!t.autoPlay))))
+			case "?":
+				cmds = append(cmds, SendData(protocol.PackageClientMessage(protocol.MsgHint, "")))
+			case "m":
+				cmds = append(cmds, t.toggleFlagMistakes())
+			case "t":
+				t.trainer.Shown = !t.trainer.Shown
+			case "T":
+				cmds = append(cmds, t.toggleQuizzes())
 			case "u":
 				cmd = SendData(protocol.PackageClientMessage(protocol.MsgGetState, ""))
 				cmds = append(cmds, cmd)
@@ -129,6 +421,8 @@ func (t *TuiTable) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				cmds = append(cmds, cmd)
 				cmds = append(cmds, ChangeRootPage(menuPage))
 				t.commandSet = false
+				t.shoes = nil
+				t.trainer.Reset()
 			}
 		}
 	}
@@ -136,6 +430,9 @@ func (t *TuiTable) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		t.betInput, cmd = t.betInput.Update(msg)
 		cmds = append(cmds, cmd)
 	}
+	if t.trainer.Asking() {
+		cmds = append(cmds, t.trainer.Update(msg))
+	}
 	return t, tea.Batch(cmds...)
 }
 
@@ -161,11 +458,27 @@ func (t *TuiTable) renderVerticalZone1() string {
 }
 
 func (t *TuiTable) renderBetDialogue() string {
-	betPrompt := "Input Bet Amount:"
+	betPrompt := fmt.Sprintf("Input Bet Amount (%s):", limitsSummary(t.rules))
+	if names := t.sideBetNames(); len(names) > 0 {
+		labels := map[string]string{"perfect_pairs": "PP", "21+3": "21+3"}
+		betPrompt = fmt.Sprintf("Input Bet Amount (%s) then", limitsSummary(t.rules))
+		for _, name := range names {
+			betPrompt += " " + labels[name]
+		}
+		betPrompt += ":"
+	}
+	if t.inputAction == protocol.MsgInsurance {
+		betPrompt = "Input Insurance Amount:"
+	}
+	for _, p := range t.Players {
+		if p.Acting {
+			betPrompt = fmt.Sprintf("Spot %d. %s", p.Spot+1, betPrompt)
+		}
+	}
 	if t.betInput.Focused() {
 		return lipgloss.JoinVertical(lipgloss.Top, betPrompt, t.betInput.View())
 	}
-	return ""
+	return t.trainer.View()
 }
 
 func (t *TuiTable) renderVerticalZone2() string {
@@ -192,21 +505,69 @@ func (p *TuiPlayer) renderPlayerZone(username string) string {
 	if p.Name == "" { // we have an empty slot
 		return renderEmptyPlayer()
 	}
-	nameTag := p.Name
-	if p.Current {
-		nameTag = currPlayer.Render(p.Name)
+	name := p.Name
+	if p.Spot > 0 {
+		name = fmt.Sprintf("%s #%d", p.Name, p.Spot+1)
+	}
+	if p.AutoPlay {
+		name += " (auto)"
+	} else if p.SittingOut {
+		name += " (out)"
+	}
+	if p.Acting {
+		name = "> " + name
+	}
+	nameTag := name
+	if p.Current || p.Acting {
+		nameTag = currPlayer.Render(name)
+	} else if p.Bot {
+		// bots are dimmed so the people at the table stand out
+		nameTag = lipgloss.NewStyle().Foreground(lipgloss.Color(blackCard)).Render(name)
+	}
+	if len(p.Hands) > 1 {
+		status := fmt.Sprintf("W:%d", p.Wallet)
+		if p.Name == username {
+			status = myPlayer.Render(status)
+		}
+		return lipgloss.Place(16, 5, lipgloss.Center, lipgloss.Center, lipgloss.JoinVertical(lipgloss.Top, nameTag, p.renderSplitHands(), status))
+	}
+	hand := TuiHand{}
+	if len(p.Hands) == 1 {
+		hand = p.Hands[0]
 	}
 	bet := p.Bet
 	wallet := p.Wallet
-	valueStr := fmt.Sprintf("%d", (p.Value))
-	if p.Value == -1 {
+	valueStr := fmt.Sprintf("%d", (hand.Value))
+	if hand.Value == -1 {
 		valueStr = "?"
 	}
 	status := fmt.Sprintf("V:%s B:%d W:%d", valueStr, bet, wallet)
+	if p.Insurance > 0 {
+		status = fmt.Sprintf("V:%s B:%d I:%d W:%d", valueStr, bet, p.Insurance, wallet)
+	} else if p.Jackpot > 0 {
+		status = fmt.Sprintf("V:%s B:%d J:%d W:%d", valueStr, bet, p.Jackpot, wallet)
+	} else if p.SideBets > 0 {
+		status = fmt.Sprintf("V:%s B:%d S:%d W:%d", valueStr, bet, p.SideBets, wallet)
+	}
 	if p.Name == username {
 		status = myPlayer.Render(status)
 	}
-	return lipgloss.Place(16, 5, lipgloss.Center, lipgloss.Center, lipgloss.JoinVertical(lipgloss.Top, nameTag, renderMultipleCards(p.Cards, 16, 6), status))
+	return lipgloss.Place(16, 5, lipgloss.Center, lipgloss.Center, lipgloss.JoinVertical(lipgloss.Top, nameTag, renderHand(hand, 16, 6), status))
+}
+
+// renderSplitHands draws every hand side by side and highlights the one being played
+func (p *TuiPlayer) renderSplitHands() string {
+	currHand := lipgloss.NewStyle().Foreground(lipgloss.Color(highlight))
+	handStyle := lipgloss.NewStyle().PaddingRight(1)
+	views := []string{}
+	for i, hand := range p.Hands {
+		label := fmt.Sprintf("V:%d B:%d", hand.Value, hand.Bet)
+		if p.Current && i == p.CurrentHand {
+			label = currHand.Render(label)
+		}
+		views = append(views, handStyle.Render(lipgloss.JoinVertical(lipgloss.Center, renderMultipleCards(hand.Cards, 0, 6), label)))
+	}
+	return lipgloss.JoinHorizontal(lipgloss.Top, views...)
 }
 
 func renderEmptyPlayer() string {
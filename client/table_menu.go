@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"log"
 	"log/slog"
+	"strings"
 
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
@@ -15,6 +16,7 @@ type TableMenuModel struct {
 	textInput       textinput.Model
 	currTableIndex  int
 	availableTables []*protocol.TableDTO
+	tournaments     []*protocol.TournamentDTO // listed below the tables
 	Commands        map[string]string
 	Height          int
 	Width           int
@@ -22,7 +24,7 @@ type TableMenuModel struct {
 
 func NewTableMenu(height, width int) *TableMenuModel {
 	ti := textinput.New()
-	ti.Placeholder = "my_cool_table"
+	ti.Placeholder = "my_cool_table [rules]"
 	ti.Width = 40
 	return &TableMenuModel{
 		textInput: ti,
@@ -32,6 +34,8 @@ func NewTableMenu(height, width int) *TableMenuModel {
 			"enter": "select",
 			"esc":   "back",
 			"n":     "new table",
+			"s":     "sign up",
+			"w":     "withdraw",
 		},
 		Height: height,
 		Width:  width,
@@ -47,15 +51,128 @@ func (tm *TableMenuModel) View() string {
 	selectedTableStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(highlight))
 	for i, table := range tm.availableTables {
 		if i == tm.currTableIndex {
-			items = append(items, selectedTableStyle.Render(fmt.Sprintf("%d %s %d/%d\n", i, table.Id, table.CurrentPlayers, table.Capacity)))
+			items = append(items, selectedTableStyle.Render(fmt.Sprintf("%d %s %d/%d  %s\n", i, table.Id, table.CurrentPlayers, table.Capacity, tableSummary(table))))
 		} else {
-			items = append(items, fmt.Sprintf("%d %s %d/%d\n", i, table.Id, table.CurrentPlayers, table.Capacity))
+			items = append(items, fmt.Sprintf("%d %s %d/%d  %s\n", i, table.Id, table.CurrentPlayers, table.Capacity, tableSummary(table)))
 		}
 	}
+	if len(tm.tournaments) > 0 {
+		items = append(items, "\nTournaments\n")
+	}
+	for i, tr := range tm.tournaments {
+		index := len(tm.availableTables) + i
+		line := fmt.Sprintf("%d %s\n", index, tournamentSummary(tr))
+		if index == tm.currTableIndex {
+			line = selectedTableStyle.Render(line)
+		}
+		items = append(items, line)
+	}
 	items = append(items, tm.textInput.View())
 	return lipgloss.JoinVertical(lipgloss.Left, items...)
 }
 
+// tournamentSummary is a tournament's line in the table list. e.g. "nightly 20:00 3/5 signed up  1000 chips 25 hands prizes 500/250"
+func tournamentSummary(tr *protocol.TournamentDTO) string {
+	status := tr.StartsAt.Local().Format("15:04")
+	switch tr.State {
+	case "running":
+		status = fmt.Sprintf("playing, %d hands left", tr.HandsLeft)
+	case "finished":
+		status = "finished"
+	}
+	summary := fmt.Sprintf("%s %s %d/%d", tr.Name, status, len(tr.Standings), tr.Capacity)
+	if tr.SignedUp {
+		summary += " signed up"
+	}
+	summary += fmt.Sprintf("  %d chips %d hands", tr.Chips, tr.Hands)
+	if len(tr.Prizes) > 0 {
+		prizes := []string{}
+		for _, p := range tr.Prizes {
+			prizes = append(prizes, fmt.Sprint(p))
+		}
+		summary += " prizes " + strings.Join(prizes, "/")
+	}
+	return summary
+}
+
+// selectedTournament is the tournament under the cursor. nil when the cursor is on a table
+func (tm *TableMenuModel) selectedTournament() *protocol.TournamentDTO {
+	i := tm.currTableIndex - len(tm.availableTables)
+	if i < 0 || i >= len(tm.tournaments) {
+		return nil
+	}
+	return tm.tournaments[i]
+}
+
+// tableSummary is the variant and rules shown in the table list
+func tableSummary(table *protocol.TableDTO) string {
+	summary := rulesSummary(table.Rules)
+	if table.Profile != "" {
+		summary = fmt.Sprintf("[%s] %s", table.Profile, summary)
+	}
+	if label := variantLabel(table.Variant); label != "" {
+		return label + " " + summary
+	}
+	return summary
+}
+
+// rulesSummary is the short form of the house rules shown in the table list. e.g. "S17 3:2 DA DAS LS $5-500 x5"
+func rulesSummary(r protocol.RulesDTO) string {
+	parts := []string{}
+	if r.StandOnSoft17 {
+		parts = append(parts, "S17")
+	} else {
+		parts = append(parts, "H17")
+	}
+	parts = append(parts, r.BlackjackPayout)
+	if r.DoubleRule == "any" {
+		parts = append(parts, "DA")
+	} else {
+		parts = append(parts, "D"+r.DoubleRule)
+	}
+	if r.DoubleAfterSplit {
+		parts = append(parts, "DAS")
+	}
+	if r.NoHoleCard {
+		parts = append(parts, "ENHC")
+	}
+	switch r.Surrender {
+	case "late":
+		parts = append(parts, "LS")
+	case "early":
+		parts = append(parts, "ES")
+	}
+	if r.Charlie > 0 {
+		parts = append(parts, fmt.Sprintf("%dCC", r.Charlie))
+	}
+	if r.DealerWinsTies {
+		parts = append(parts, "DWT")
+	}
+	if r.Bonus678 > 0 {
+		parts = append(parts, fmt.Sprintf("678:%d", r.Bonus678))
+	}
+	if r.Bonus777 > 0 {
+		parts = append(parts, fmt.Sprintf("777:%d", r.Bonus777))
+	}
+	if r.ContinuousShuffle {
+		parts = append(parts, "CSM")
+	}
+	parts = append(parts, limitsSummary(r))
+	return strings.Join(parts, " ")
+}
+
+// limitsSummary is the table's betting limits. e.g. "$5-500 x5"
+func limitsSummary(r protocol.RulesDTO) string {
+	limits := fmt.Sprintf("$%d+", r.MinBet)
+	if r.MaxBet > 0 {
+		limits = fmt.Sprintf("$%d-%d", r.MinBet, r.MaxBet)
+	}
+	if r.BetIncrement > 1 {
+		limits += fmt.Sprintf(" x%d", r.BetIncrement)
+	}
+	return limits
+}
+
 func (tm *TableMenuModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	// for when the root model is on page lobby
 	var cmds []tea.Cmd
@@ -63,6 +180,9 @@ func (tm *TableMenuModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case ChangeMenuPage:
 		cmds = append(cmds, AddCommands(tm.Commands))
+		// tournaments open and fill up while we're elsewhere
+		cmds = append(cmds, SendData(protocol.PackageClientMessage(protocol.MsgTableList, "")))
+		cmds = append(cmds, SendData(protocol.PackageClientMessage(protocol.MsgTournamentList, "")))
 	case ChangeRootPageMsg:
 		cmds = append(cmds, AddCommands(tm.Commands))
 	case TextFocusMsg:
@@ -80,10 +200,26 @@ func (tm *TableMenuModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			// this could be a cotmand?
 			var tableName string
 			if tm.textInput.Focused() {
-				tableName = tm.textInput.Value()
-				cmd = SendData(protocol.PackageClientMessage(protocol.MsgCreateTable, tableName))
+				// "name [rules]" picks one of the server's rule profiles
+				var profile string
+				fields := strings.Fields(tm.textInput.Value())
+				if len(fields) > 0 {
+					tableName = fields[0]
+				}
+				if len(fields) > 1 {
+					profile = fields[1]
+				}
+				cmd = SendData(protocol.PackageCreateTable(tableName, profile))
 				cmds = append(cmds, cmd)
 				cmds = append(cmds, AddCommands(tm.Commands))
+			} else if tr := tm.selectedTournament(); tr != nil {
+				if tr.State != "running" {
+					cmds = append(cmds, SendData(protocol.PackageClientMessage(protocol.MsgSignUp, tr.Name)))
+					break
+				}
+				// tournaments are played at a table named after them
+				cmds = append(cmds, SendData(protocol.PackageClientMessage(protocol.MsgJoinTable, tr.Name)))
+				cmds = append(cmds, ChangeRootPage(gamePage))
 			} else {
 				if len(tm.availableTables) > 0 {
 					tableName = tm.availableTables[tm.currTableIndex].Id
@@ -100,8 +236,16 @@ func (tm *TableMenuModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				cmd = TextFocusCmd()
 				cmds = append(cmds, cmd)
 				cmds = append(cmds, AddCommands(map[string]string{"enter": "create table", "esc": "cancel"}))
+			case "s":
+				if tr := tm.selectedTournament(); tr != nil {
+					cmds = append(cmds, SendData(protocol.PackageClientMessage(protocol.MsgSignUp, tr.Name)))
+				}
+			case "w":
+				if tr := tm.selectedTournament(); tr != nil {
+					cmds = append(cmds, SendData(protocol.PackageClientMessage(protocol.MsgWithdraw, tr.Name)))
+				}
 			case "j":
-				if tm.currTableIndex+1 < len(tm.availableTables) {
+				if tm.currTableIndex+1 < len(tm.availableTables)+len(tm.tournaments) {
 					tm.currTableIndex += 1
 				}
 				// lower the index on the room
@@ -112,10 +256,13 @@ func (tm *TableMenuModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			case "u":
 				cmd = SendData(protocol.PackageClientMessage(protocol.MsgTableList, ""))
 				cmds = append(cmds, cmd)
+				cmds = append(cmds, SendData(protocol.PackageClientMessage(protocol.MsgTournamentList, "")))
 			}
 		}
 	case []*protocol.TableDTO:
 		tm.TablesToState(msg)
+	case []*protocol.TournamentDTO:
+		tm.tournaments = msg
 	}
 
 	if tm.textInput.Focused() {
@@ -0,0 +1,61 @@
+package client
+
+import "github.com/dylanmccormick/blackjack-tui/protocol"
+
+// variantLabel is the short name shown next to a table. Classic blackjack doesn't get one
+func variantLabel(variant string) string {
+	switch variant {
+	case "spanish21":
+		return "Spanish21"
+	case "pontoon":
+		return "Pontoon"
+	case "double_exposure":
+		return "DblExposure"
+	}
+	return ""
+}
+
+// dealerName is what the dealer's seat is called. Pontoon calls the dealer the banker
+func dealerName(variant string) string {
+	switch variant {
+	case "pontoon":
+		return "banker"
+	case "spanish21", "double_exposure":
+		return "dealer · " + variantLabel(variant)
+	}
+	return "dealer"
+}
+
+// updateVariantCommands names the plays the way the table's variant does and drops the ones it
+// doesn't have. Returns true if the commands changed
+func (t *TuiTable) updateVariantCommands(msg *protocol.GameDTO) bool {
+	if msg.Variant == t.variant {
+		return false
+	}
+	t.variant = msg.Variant
+	t.Players[0].Name = dealerName(msg.Variant)
+	t.Commands["h"] = "hit"
+	t.Commands["s"] = "stand"
+	t.Commands["d"] = "double down"
+	t.Commands["i"] = "insurance"
+	t.Commands["e"] = "even money"
+	t.Commands["c"] = "no insurance"
+	t.Commands["?"] = "hint"
+	switch msg.Variant {
+	case "pontoon":
+		t.Commands["h"] = "twist"
+		t.Commands["s"] = "stick"
+		t.Commands["d"] = "buy"
+		fallthrough
+	case "double_exposure":
+		// nobody can insure against a card they can't see, or one they already can
+		delete(t.Commands, "i")
+		delete(t.Commands, "e")
+		delete(t.Commands, "c")
+	}
+	if msg.Variant != "" && msg.Variant != "classic" {
+		// basic strategy is only charted for classic blackjack
+		delete(t.Commands, "?")
+	}
+	return true
+}
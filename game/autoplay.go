@@ -0,0 +1,186 @@
+package game
+
+import (
+	"fmt"
+	"math/rand/v2"
+)
+
+// Strategies play a seat without anyone at the keyboard. They only pick the play. The play
+// itself goes through Act and the same checks a person's play would
+
+const (
+	STRATEGY_BASIC      = "basic"
+	STRATEGY_NEVER_BUST = "never_bust"
+	STRATEGY_RANDOM     = "random"
+)
+
+// Strategy picks the play for the decision a player has in front of them, including the insurance window
+type Strategy interface {
+	Decide(g *Game, p *Player) (PlayerAction, error)
+}
+
+// ParseStrategy gets a fresh strategy by name. Strategies aren't safe to share between goroutines
+func ParseStrategy(name string) (Strategy, error) {
+	switch name {
+	case STRATEGY_BASIC:
+		return BasicStrategyPlayer{}, nil
+	case STRATEGY_NEVER_BUST:
+		return NeverBust{}, nil
+	case STRATEGY_RANDOM:
+		return &RandomPlay{rand: rand.New(rand.NewPCG(rand.Uint64(), rand.Uint64()))}, nil
+	}
+	return nil, fmt.Errorf("Unknown strategy %q", name)
+}
+
+// BasicStrategyPlayer always makes the play Advise gives. Variants without a chart are played never bust
+type BasicStrategyPlayer struct{}
+
+func (BasicStrategyPlayer) Decide(g *Game, p *Player) (PlayerAction, error) {
+	if !g.charted() {
+		return NeverBust{}.Decide(g, p)
+	}
+	return g.Advise(p)
+}
+
+// NeverBust never takes a card that could bust the hand. Hard 12 and up stands, soft hands
+// draw to 18. It never doubles, splits, surrenders or insures
+type NeverBust struct{}
+
+func (NeverBust) Decide(g *Game, p *Player) (PlayerAction, error) {
+	err := g.checkDecision(p)
+	if err != nil {
+		return "", err
+	}
+	if g.State == INSURANCE {
+		return ACTION_DECLINE_INSURANCE, nil
+	}
+	hand := g.CurrentHand()
+	value, soft := calculateValue(hand.Cards)
+	if !g.canHit(hand) {
+		return ACTION_STAND, nil
+	}
+	if (soft && value < 18) || (!soft && value < 12) {
+		return ACTION_HIT, nil
+	}
+	return ACTION_STAND, nil
+}
+
+// RandomPlay picks any play the rules allow with equal odds
+type RandomPlay struct {
+	rand *rand.Rand
+}
+
+func (r *RandomPlay) Decide(g *Game, p *Player) (PlayerAction, error) {
+	err := g.checkDecision(p)
+	if err != nil {
+		return "", err
+	}
+	actions := []PlayerAction{}
+	if g.State == INSURANCE {
+		actions = append(actions, ACTION_DECLINE_INSURANCE)
+		if g.DealerHand.Cards[0].Rank == ACE {
+			if p.Hands[0].GetState() == BLACKJACK {
+				actions = append(actions, ACTION_EVEN_MONEY)
+			} else if p.Hands[0].Bet >= 2 && p.Wallet >= p.Hands[0].Bet/2 {
+				actions = append(actions, ACTION_INSURANCE)
+			}
+		}
+	} else {
+		actions = append(actions, ACTION_STAND)
+		if g.canHit(g.CurrentHand()) {
+			actions = append(actions, ACTION_HIT)
+		}
+		if g.CanDouble(p) {
+			actions = append(actions, ACTION_DOUBLE)
+		}
+		if g.CanSplit(p) {
+			actions = append(actions, ACTION_SPLIT)
+		}
+	}
+	if g.CanSurrender(p) {
+		actions = append(actions, ACTION_SURRENDER)
+	}
+	return actions[r.rand.IntN(len(actions))], nil
+}
+
+// AutoPlay makes the strategy's play for a player who isn't there to make it. The play is
+// marked automatic like AutoStay's and isn't held against the player's strategy. A play the
+// rules won't take falls back to standing, or declining insurance
+func (g *Game) AutoPlay(p *Player, s Strategy) error {
+	g.automatic = true
+	defer func() { g.automatic = false }()
+	action, err := s.Decide(g, p)
+	if err == nil {
+		err = g.Act(p, action)
+	}
+	if err == nil {
+		return nil
+	}
+	if g.State == INSURANCE {
+		return g.DeclineInsurance(p)
+	}
+	return g.AutoStay(p)
+}
+
+// Rebet puts the player's last bet down again for them. Side bets that can't go down again,
+// because the wallet is short or the table stopped offering them, are left off
+func (g *Game) Rebet(p *Player) error {
+	if p == nil || p.LastBet.Main == 0 {
+		return fmt.Errorf("There is no bet to repeat")
+	}
+	g.automatic = true
+	defer func() { g.automatic = false }()
+	err := g.PlaceBet(p, p.LastBet)
+	if err != nil && len(p.LastBet.SideBets) > 0 {
+		err = g.PlaceBet(p, Bet{Main: p.LastBet.Main})
+	}
+	return err
+}
+
+// checkDecision errors unless the player has a decision to make right now
+func (g *Game) checkDecision(p *Player) error {
+	if p == nil || len(p.Hands) == 0 || !p.IsActive() {
+		return fmt.Errorf("You are not playing this round")
+	}
+	switch g.State {
+	case INSURANCE:
+		if p.InsuranceDecided {
+			return fmt.Errorf("You already made your decision")
+		}
+	case PLAYER_TURN:
+		if p != g.CurrentPlayer() {
+			return fmt.Errorf("It is not Player %d's turn", p.ID)
+		}
+	default:
+		return fmt.Errorf("There is nothing to decide right now")
+	}
+	return nil
+}
+
+// canHit is false for split aces that only get the one card
+func (g *Game) canHit(h *Hand) bool {
+	return !h.IsSplitAces() || g.Config.Rules.HitSplitAces
+}
+
+// Act makes a play for a player. Insurance is always the full half of the bet
+func (g *Game) Act(p *Player, action PlayerAction) error {
+	switch action {
+	case ACTION_HIT:
+		return g.Hit(p)
+	case ACTION_STAND:
+		return g.Stay(p)
+	case ACTION_DOUBLE:
+		return g.DoubleDown(p)
+	case ACTION_SPLIT:
+		return g.Split(p)
+	case ACTION_SURRENDER:
+		return g.Surrender(p)
+	case ACTION_INSURANCE:
+		return g.PlaceInsurance(p, p.Hands[0].Bet/2)
+	case ACTION_EVEN_MONEY:
+		return g.TakeEvenMoney(p)
+	case ACTION_DECLINE_INSURANCE:
+		return g.DeclineInsurance(p)
+	}
+	return fmt.Errorf("Unknown action %q", action)
+}
@@ -0,0 +1,78 @@
+package game
+
+import "fmt"
+
+type BetErrorReason string
+
+const (
+	BET_INVALID      BetErrorReason = "invalid"
+	BET_BELOW_MIN    BetErrorReason = "below_min"
+	BET_ABOVE_MAX    BetErrorReason = "above_max"
+	BET_INCREMENT    BetErrorReason = "increment"
+	BET_OVER_WALLET  BetErrorReason = "over_wallet"
+	BET_ALREADY_MADE BetErrorReason = "already_made"
+
+	BET_SIDE_NOT_OFFERED BetErrorReason = "side_not_offered"
+	BET_SIDE_OVER_MAIN   BetErrorReason = "side_over_main"
+)
+
+// BetError is returned when a bet is rejected by the table limits or the player's wallet.
+// The limits are included so clients can tell the player what would be accepted
+type BetError struct {
+	Reason    BetErrorReason
+	Bet       int
+	MinBet    int
+	MaxBet    int
+	Increment int
+	Wallet    int     // what the player had when the bet was over their wallet
+	SideBet   SideBet // the side bet that was turned down, if it wasn't the main bet
+}
+
+func (e *BetError) Error() string {
+	switch e.Reason {
+	case BET_INVALID:
+		if e.SideBet != "" {
+			return fmt.Sprintf("%s bet cannot be negative", e.SideBet)
+		}
+		return "Bets must be at least 1"
+	case BET_BELOW_MIN:
+		return fmt.Sprintf("Minimum bet at this table is %d", e.MinBet)
+	case BET_ABOVE_MAX:
+		return fmt.Sprintf("Maximum bet at this table is %d", e.MaxBet)
+	case BET_INCREMENT:
+		return fmt.Sprintf("Bets at this table must be in multiples of %d", e.Increment)
+	case BET_OVER_WALLET:
+		return fmt.Sprintf("You can't bet %d with %d in your wallet", e.Bet, e.Wallet)
+	case BET_ALREADY_MADE:
+		return "Bet already made. You can't make another bet"
+	case BET_SIDE_NOT_OFFERED:
+		return fmt.Sprintf("This table doesn't offer %s", e.SideBet)
+	case BET_SIDE_OVER_MAIN:
+		return fmt.Sprintf("%s bet cannot be more than your main bet", e.SideBet)
+	}
+	return fmt.Sprintf("Bet of %d was rejected", e.Bet)
+}
+
+// ValidateBet checks a bet against the table limits
+func (r RuleSet) ValidateBet(bet int) error {
+	if bet < r.MinBet {
+		return r.betError(BET_BELOW_MIN, bet)
+	}
+	if r.MaxBet > 0 && bet > r.MaxBet {
+		return r.betError(BET_ABOVE_MAX, bet)
+	}
+	if r.BetIncrement > 1 && bet%r.BetIncrement != 0 {
+		return r.betError(BET_INCREMENT, bet)
+	}
+	return nil
+}
+
+func (r RuleSet) betError(reason BetErrorReason, bet int) *BetError {
+	return &BetError{
+		Reason:    reason,
+		Bet:       bet,
+		MinBet:    r.MinBet,
+		MaxBet:    r.MaxBet,
+		Increment: r.BetIncrement,
+	}
+}
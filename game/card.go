@@ -18,6 +18,11 @@ const (
 	ACE   cardRank = 1
 )
 
+// NewCard builds a card from its wire format
+func NewCard(s string, rank int) Card {
+	return Card{Suit: suit(s), Rank: cardRank(rank)}
+}
+
 func ValToString(i cardRank) (string, error) {
 	vals := map[cardRank]string{
 		1:  "ace",
@@ -1,44 +1,124 @@
 package game
 
 import (
+	crand "crypto/rand"
+	"encoding/hex"
 	"fmt"
+	"io"
+	"log/slog"
 	"math/rand/v2"
 	"slices"
 )
 
+// Seed fully determines the order of a shoe. Recording it lets any hand be dealt again exactly
+type Seed [32]byte
+
+func (s Seed) String() string {
+	return hex.EncodeToString(s[:])
+}
+
+func (s Seed) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+func (s *Seed) UnmarshalText(b []byte) error {
+	seed, err := ParseSeed(string(b))
+	if err != nil {
+		return err
+	}
+	*s = seed
+	return nil
+}
+
+func ParseSeed(s string) (Seed, error) {
+	var seed Seed
+	b, err := hex.DecodeString(s)
+	if err != nil || len(b) != len(seed) {
+		return seed, fmt.Errorf("Invalid shoe seed %q", s)
+	}
+	copy(seed[:], b)
+	return seed, nil
+}
+
 type Deck struct {
 	Cards     []Card
 	UsedCards []Card
 	Threshold int
+	DeckCount int
+	Seed      Seed // Seed of the current shoe
+	entropy   io.Reader
+	variant   Variant // what goes into each deck. nil is a standard 52 card deck
+	rng       *rand.Rand
+	mixed     bool // discards were shuffled back in, so UsedCards no longer follows the shoe order
 }
 
+// CreateDeck builds a shoe seeded from crypto/rand
 func CreateDeck(numDecks, threshold int) *Deck {
+	return NewDeck(numDecks, threshold, nil)
+}
+
+// NewDeck builds a shoe that reads a fresh seed from entropy on every shuffle.
+// A nil entropy falls back to crypto/rand. Pass a seeded reader to get the same shoes every time
+func NewDeck(numDecks, threshold int, entropy io.Reader) *Deck {
+	return NewVariantDeck(numDecks, threshold, entropy, Classic{})
+}
+
+// NewVariantDeck builds a shoe out of the variant's decks
+func NewVariantDeck(numDecks, threshold int, entropy io.Reader, variant Variant) *Deck {
 	if numDecks == 0 {
 		numDecks = DECK_COUNT
 	}
 	if threshold == 0 {
 		threshold = CUT_LOCATION
 	}
-	d := &Deck{Cards: make([]Card, 52*numDecks), Threshold: threshold}
-	i := 0
-	for range numDecks {
-		for _, s := range []suit{"club", "diamond", "heart", "spade"} {
-			for _, v := range []cardRank{2, 3, 4, 5, 6, 7, 8, 9, 10, JACK, QUEEN, KING, ACE} {
-				d.Cards[i] = Card{Suit: s, Rank: v}
-				i++
-			}
-		}
-	}
+	d := &Deck{Threshold: threshold, DeckCount: numDecks, entropy: entropy, variant: variant}
 
 	d.Shuffle()
 
 	return d
 }
 
+// Shuffle brings every card back into the shoe and shuffles it with a new seed
 func (d *Deck) Shuffle() {
-	d.Cards = append(d.Cards, d.UsedCards...)
-	d.Cards = Shuffle(d.Cards)
+	d.ShuffleWithSeed(d.nextSeed())
+}
+
+// ShuffleWithSeed rebuilds the shoe in its unshuffled order and shuffles it with the given seed,
+// so the same seed always gives the same shoe
+func (d *Deck) ShuffleWithSeed(seed Seed) {
+	d.seed(seed)
+	d.Cards = Shuffle(orderedCards(d.DeckCount, d.variant), d.rng)
 	d.UsedCards = []Card{}
+	d.mixed = false
+}
+
+func (d *Deck) seed(seed Seed) {
+	d.Seed = seed
+	d.rng = rand.New(rand.NewChaCha8(seed))
+}
+
+func (d *Deck) nextSeed() Seed {
+	var seed Seed
+	if d.entropy == nil {
+		crand.Read(seed[:])
+		return seed
+	}
+	if _, err := io.ReadFull(d.entropy, seed[:]); err != nil {
+		slog.Error("Unable to read shoe seed. Falling back to crypto/rand", "error", err)
+		crand.Read(seed[:])
+	}
+	return seed
+}
+
+func orderedCards(numDecks int, variant Variant) []Card {
+	if variant == nil {
+		variant = Classic{}
+	}
+	cards := []Card{}
+	for range numDecks {
+		cards = append(cards, variant.Deck()...)
+	}
+	return cards
 }
 
 func (d *Deck) DrawCard() (Card, error) {
@@ -51,15 +131,38 @@ func (d *Deck) DrawCard() (Card, error) {
 	return c, nil
 }
 
+// Burn takes the top card out of the shoe without dealing it
+func (d *Deck) Burn() error {
+	_, err := d.DrawCard()
+	return err
+}
+
+// ReshuffleDiscards shuffles the discard tray back into the shoe when it runs dry mid-round.
+// Cards that are still on the table stay out of the shoe. The shoe's seed keeps driving the shuffle
+func (d *Deck) ReshuffleDiscards(inPlay []Card) {
+	discards := slices.Clone(d.UsedCards)
+	for _, c := range inPlay {
+		if i := slices.Index(discards, c); i >= 0 {
+			discards = slices.Delete(discards, i, i+1)
+		}
+	}
+	if d.rng == nil {
+		d.seed(d.nextSeed())
+	}
+	d.Cards = append(d.Cards, Shuffle(discards, d.rng)...)
+	d.UsedCards = slices.Clone(inPlay)
+	d.mixed = true
+}
+
 func (d *Deck) NeedsReshuffle() bool {
 	return len(d.Cards) < d.Threshold
 }
 
-func Shuffle[T any](items []T) []T {
+func Shuffle[T any](items []T, r *rand.Rand) []T {
 	// Copy so we don't mutate the list. Good practice from assembly I think
 	ret := slices.Clone(items)
 	for i := len(ret) - 1; i > 0; i-- {
-		j := rand.IntN(i + 1)
+		j := r.IntN(i + 1)
 		ret[i], ret[j] = ret[j], ret[i]
 	}
 	return ret
@@ -0,0 +1,272 @@
+package game
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/dylanmccormick/blackjack-tui/store"
+	"github.com/google/uuid"
+)
+
+// The engine records an event for everything that happens at the table. Events are numbered
+// in the order they happened and carry enough to rebuild a round without looking at the game.
+// Consumers drain them after every call into the game and switch on the event data.
+
+type Event struct {
+	Seq   int       `json:"seq"`   // Order the event happened in. The first event of a game is 1
+	Round int       `json:"round"` // Round the event belongs to. Between rounds this is the next round
+	Type  EventType `json:"type"`
+	Data  EventData `json:"data"`
+}
+
+type EventType string
+
+const (
+	EVENT_SHOE_SHUFFLED      EventType = "shoe_shuffled"
+	EVENT_CARD_BURNED        EventType = "card_burned"
+	EVENT_BET_PLACED         EventType = "bet_placed"
+	EVENT_ROUND_STARTED      EventType = "round_started"
+	EVENT_CARD_DEALT         EventType = "card_dealt"
+	EVENT_INSURANCE_OFFERED  EventType = "insurance_offered"
+	EVENT_DEALER_PEEKED      EventType = "dealer_peeked"
+	EVENT_TURN_CHANGED       EventType = "turn_changed"
+	EVENT_PLAYER_ACTED       EventType = "player_acted"
+	EVENT_HOLE_CARD_REVEALED EventType = "hole_card_revealed"
+	EVENT_HAND_RESOLVED      EventType = "hand_resolved"
+	EVENT_INSURANCE_RESOLVED EventType = "insurance_resolved"
+	EVENT_JACKPOT_RESOLVED   EventType = "jackpot_resolved"
+	EVENT_SIDE_BET_RESOLVED  EventType = "side_bet_resolved"
+	EVENT_ROUND_ENDED        EventType = "round_ended"
+)
+
+type EventData interface {
+	EventType() EventType
+}
+
+// ShoeShuffled is a new shoe, or the discards going back into the shoe when it ran dry mid-round
+type ShoeShuffled struct {
+	Seed     Seed `json:"seed"`
+	Decks    int  `json:"decks"`
+	Cards    int  `json:"cards"`    // cards in the shoe after the shuffle
+	Discards bool `json:"discards"` // the cards went back into the same shoe. Its seed hasn't changed
+}
+
+type CardBurned struct {
+	Card Card `json:"card"`
+}
+
+type BetPlaced struct {
+	PlayerID  uuid.UUID       `json:"player_id"`
+	Amount    int             `json:"amount"`
+	SideBets  map[SideBet]int `json:"side_bets,omitempty"`
+	Wallet    int             `json:"wallet"`    // wallet after the bet
+	Automatic bool            `json:"automatic"` // the table put the player's last bet down for them
+}
+
+type RoundPlayer struct {
+	PlayerID uuid.UUID       `json:"player_id"`
+	Name     string          `json:"name"`
+	Owner    uuid.UUID       `json:"owner"` // the player the spot belongs to when it is an extra spot
+	Bot      bool            `json:"bot,omitempty"`
+	Bet      int             `json:"bet"`
+	Jackpot  int             `json:"jackpot,omitempty"` // jackpot side bet
+	SideBets map[SideBet]int `json:"side_bets,omitempty"`
+}
+
+// RoundStarted lists who is playing the round in seat order
+type RoundStarted struct {
+	Players []RoundPlayer `json:"players"`
+	Variant string        `json:"variant,omitempty"` // classic when empty
+	Rules   HandRules     `json:"hand_rules"`
+}
+
+// CardDealt is a card coming out of the shoe. The recipient is either the dealer or one of a player's hands
+type CardDealt struct {
+	Dealer   bool      `json:"dealer"`
+	PlayerID uuid.UUID `json:"player_id"`
+	Hand     int       `json:"hand"`
+	Card     Card      `json:"card"`
+	FaceDown bool      `json:"face_down"` // the dealer's hole card
+}
+
+type InsuranceOffered struct {
+	UpCard Card `json:"up_card"`
+}
+
+type DealerPeeked struct {
+	Blackjack bool `json:"blackjack"`
+}
+
+// TurnChanged is the hand that is up next. The dealer's turn has Dealer set
+type TurnChanged struct {
+	Dealer   bool      `json:"dealer"`
+	PlayerID uuid.UUID `json:"player_id"`
+	Hand     int       `json:"hand"`
+}
+
+type PlayerAction string
+
+const (
+	ACTION_HIT               PlayerAction = "hit"
+	ACTION_STAND             PlayerAction = "stand"
+	ACTION_DOUBLE            PlayerAction = "double"
+	ACTION_SPLIT             PlayerAction = "split"
+	ACTION_SURRENDER         PlayerAction = "surrender"
+	ACTION_INSURANCE         PlayerAction = "insurance"
+	ACTION_EVEN_MONEY        PlayerAction = "even_money"
+	ACTION_DECLINE_INSURANCE PlayerAction = "decline_insurance"
+)
+
+// PlayerActed is a decision a player made. Amount is any money the action put on the table
+type PlayerActed struct {
+	PlayerID  uuid.UUID    `json:"player_id"`
+	Hand      int          `json:"hand"`
+	Action    PlayerAction `json:"action"`
+	Amount    int          `json:"amount"`
+	Automatic bool         `json:"automatic"`        // the game decided for the player. e.g. a timeout or a disconnect
+	Advice    PlayerAction `json:"advice,omitempty"` // basic strategy's play for the decision. Not set when the game decided
+}
+
+type HoleCardRevealed struct {
+	Card Card `json:"card"`
+}
+
+type HandResolved struct {
+	PlayerID  uuid.UUID      `json:"player_id"`
+	Hand      int            `json:"hand"`
+	Outcome   store.WonState `json:"outcome"`
+	Blackjack bool           `json:"blackjack"`
+	Bet       int            `json:"bet"`
+	Payout    int            `json:"payout"` // everything paid back to the player including the bet
+}
+
+type InsuranceResolved struct {
+	PlayerID uuid.UUID `json:"player_id"`
+	Amount   int       `json:"amount"`
+	Payout   int       `json:"payout"`
+}
+
+// JackpotResolved is a jackpot side bet settling. Hand is empty when the player didn't hit a jackpot hand
+type JackpotResolved struct {
+	PlayerID uuid.UUID   `json:"player_id"`
+	Amount   int         `json:"amount"`
+	Hand     JackpotHand `json:"hand,omitempty"`
+	Payout   int         `json:"payout"`
+}
+
+// SideBetResolved is a side bet settling on the deal. Hand is empty when it lost
+type SideBetResolved struct {
+	PlayerID uuid.UUID `json:"player_id"`
+	SideBet  SideBet   `json:"side_bet"`
+	Amount   int       `json:"amount"`
+	Hand     SideHand  `json:"hand,omitempty"`
+	Payout   int       `json:"payout"` // everything paid back including the bet
+}
+
+type RoundEnded struct {
+	DealerValue int `json:"dealer_value"`
+}
+
+func (ShoeShuffled) EventType() EventType      { return EVENT_SHOE_SHUFFLED }
+func (CardBurned) EventType() EventType        { return EVENT_CARD_BURNED }
+func (BetPlaced) EventType() EventType         { return EVENT_BET_PLACED }
+func (RoundStarted) EventType() EventType      { return EVENT_ROUND_STARTED }
+func (CardDealt) EventType() EventType         { return EVENT_CARD_DEALT }
+func (InsuranceOffered) EventType() EventType  { return EVENT_INSURANCE_OFFERED }
+func (DealerPeeked) EventType() EventType      { return EVENT_DEALER_PEEKED }
+func (TurnChanged) EventType() EventType       { return EVENT_TURN_CHANGED }
+func (PlayerActed) EventType() EventType       { return EVENT_PLAYER_ACTED }
+func (HoleCardRevealed) EventType() EventType  { return EVENT_HOLE_CARD_REVEALED }
+func (HandResolved) EventType() EventType      { return EVENT_HAND_RESOLVED }
+func (InsuranceResolved) EventType() EventType { return EVENT_INSURANCE_RESOLVED }
+func (JackpotResolved) EventType() EventType   { return EVENT_JACKPOT_RESOLVED }
+func (SideBetResolved) EventType() EventType   { return EVENT_SIDE_BET_RESOLVED }
+func (RoundEnded) EventType() EventType        { return EVENT_ROUND_ENDED }
+
+// UnmarshalJSON reads an event back into its concrete data type so stored rounds can be replayed
+func (e *Event) UnmarshalJSON(b []byte) error {
+	var raw struct {
+		Seq   int             `json:"seq"`
+		Round int             `json:"round"`
+		Type  EventType       `json:"type"`
+		Data  json.RawMessage `json:"data"`
+	}
+	err := json.Unmarshal(b, &raw)
+	if err != nil {
+		return err
+	}
+	var data EventData
+	switch raw.Type {
+	case EVENT_SHOE_SHUFFLED:
+		data, err = unmarshalData[ShoeShuffled](raw.Data)
+	case EVENT_CARD_BURNED:
+		data, err = unmarshalData[CardBurned](raw.Data)
+	case EVENT_BET_PLACED:
+		data, err = unmarshalData[BetPlaced](raw.Data)
+	case EVENT_ROUND_STARTED:
+		data, err = unmarshalData[RoundStarted](raw.Data)
+	case EVENT_CARD_DEALT:
+		data, err = unmarshalData[CardDealt](raw.Data)
+	case EVENT_INSURANCE_OFFERED:
+		data, err = unmarshalData[InsuranceOffered](raw.Data)
+	case EVENT_DEALER_PEEKED:
+		data, err = unmarshalData[DealerPeeked](raw.Data)
+	case EVENT_TURN_CHANGED:
+		data, err = unmarshalData[TurnChanged](raw.Data)
+	case EVENT_PLAYER_ACTED:
+		data, err = unmarshalData[PlayerActed](raw.Data)
+	case EVENT_HOLE_CARD_REVEALED:
+		data, err = unmarshalData[HoleCardRevealed](raw.Data)
+	case EVENT_HAND_RESOLVED:
+		data, err = unmarshalData[HandResolved](raw.Data)
+	case EVENT_INSURANCE_RESOLVED:
+		data, err = unmarshalData[InsuranceResolved](raw.Data)
+	case EVENT_JACKPOT_RESOLVED:
+		data, err = unmarshalData[JackpotResolved](raw.Data)
+	case EVENT_SIDE_BET_RESOLVED:
+		data, err = unmarshalData[SideBetResolved](raw.Data)
+	case EVENT_ROUND_ENDED:
+		data, err = unmarshalData[RoundEnded](raw.Data)
+	default:
+		return fmt.Errorf("unknown event type %q", raw.Type)
+	}
+	if err != nil {
+		return err
+	}
+	*e = Event{Seq: raw.Seq, Round: raw.Round, Type: raw.Type, Data: data}
+	return nil
+}
+
+func unmarshalData[T EventData](b []byte) (EventData, error) {
+	var data T
+	err := json.Unmarshal(b, &data)
+	return data, err
+}
+
+func (g *Game) emit(data EventData) {
+	if g.automatic {
+		// the game made this play for someone. It isn't theirs
+		switch e := data.(type) {
+		case PlayerActed:
+			e.Automatic = true
+			data = e
+		case BetPlaced:
+			e.Automatic = true
+			data = e
+		}
+	}
+	g.eventSeq++
+	g.events = append(g.events, Event{
+		Seq:   g.eventSeq,
+		Round: g.Round,
+		Type:  data.EventType(),
+		Data:  data,
+	})
+}
+
+// DrainEvents hands back everything that happened since the last drain, oldest first
+func (g *Game) DrainEvents() []Event {
+	events := g.events
+	g.events = nil
+	return events
+}
@@ -0,0 +1,79 @@
+package game
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"slices"
+)
+
+// DealtCards are cards that came out of the shoe one after another, starting at Position.
+// Position 0 is the top of a freshly shuffled shoe, which is the burn card when the table burns one
+type DealtCards struct {
+	Position int    `json:"position"`
+	Cards    []Card `json:"cards"`
+}
+
+// ShoeHistory is what a player saw dealt from a single shoe
+type ShoeHistory struct {
+	Decks      int          `json:"decks"`
+	Variant    string       `json:"variant,omitempty"` // classic when empty
+	Commitment string       `json:"commitment"`
+	Rounds     []DealtCards `json:"rounds"`
+}
+
+// Commitment is published before a shoe is dealt. It's the sha256 of the seed so the
+// shoe can't be swapped out once players have seen the commitment
+func (s Seed) Commitment() string {
+	sum := sha256.Sum256(s[:])
+	return hex.EncodeToString(sum[:])
+}
+
+// VerifyShoe rebuilds the shoe from a revealed seed and checks every dealt card against it
+func VerifyShoe(seed Seed, h ShoeHistory) error {
+	if h.Commitment != "" && h.Commitment != seed.Commitment() {
+		return fmt.Errorf("Seed %s does not match commitment %s", seed, h.Commitment)
+	}
+	if h.Decks <= 0 {
+		return fmt.Errorf("Invalid deck count %d", h.Decks)
+	}
+	variant, err := ParseVariant(h.Variant)
+	if err != nil {
+		return err
+	}
+	d := &Deck{DeckCount: h.Decks, variant: variant}
+	d.ShuffleWithSeed(seed)
+	for _, round := range h.Rounds {
+		if round.Position < 0 || round.Position+len(round.Cards) > len(d.Cards) {
+			return fmt.Errorf("Cards dealt at position %d are outside a %d card shoe", round.Position, len(d.Cards))
+		}
+		for i, c := range round.Cards {
+			expected := d.Cards[round.Position+i]
+			if c != expected {
+				return fmt.Errorf("Card %d of the shoe should be the %s but the %s was dealt", round.Position+i, cardName(expected), cardName(c))
+			}
+		}
+	}
+	return nil
+}
+
+// RoundCards returns every card dealt this round in the order it left the shoe.
+// Once the discards have been shuffled back in the shoe order is gone and nothing is returned
+func (g *Game) RoundCards() (DealtCards, bool) {
+	d := g.Deck.Base()
+	if g.State != RESOLVING_BETS || d.mixed || g.roundStart > len(d.UsedCards) {
+		return DealtCards{}, false
+	}
+	return DealtCards{
+		Position: g.roundStart,
+		Cards:    slices.Clone(d.UsedCards[g.roundStart:]),
+	}, true
+}
+
+func cardName(c Card) string {
+	rank, err := ValToString(c.Rank)
+	if err != nil {
+		return fmt.Sprintf("%d of %ss", c.Rank, c.Suit)
+	}
+	return fmt.Sprintf("%s of %ss", rank, c.Suit)
+}
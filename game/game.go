@@ -1,9 +1,11 @@
 package game
 
 import (
+	"errors"
 	"fmt"
+	"io"
 	"log/slog"
-	"math"
+	"maps"
 	"slices"
 
 	"github.com/dylanmccormick/blackjack-tui/store"
@@ -18,12 +20,18 @@ type (
 type GameConfig struct {
 	DeckCount   int
 	CutLocation int
+	BurnCard    bool        // Burn the first card after every shuffle
+	Entropy     io.Reader   // Where shoe seeds come from. nil means crypto/rand
+	Variant     Variant     // nil means classic blackjack
+	Jackpot     JackpotPool // nil means no jackpot side bets
+	Rules       RuleSet
 }
 
 const (
 	WAIT_FOR_START GameState = iota
 	WAITING_FOR_BETS
 	DEALING
+	INSURANCE
 	PLAYER_TURN
 	DEALER_TURN
 	RESOLVING_BETS
@@ -37,6 +45,8 @@ func (gs GameState) String() string {
 		return "WAITING_FOR_BETS"
 	case DEALING:
 		return "DEALING"
+	case INSURANCE:
+		return "INSURANCE"
 	case PLAYER_TURN:
 		return "PLAYER_TURN"
 	case DEALER_TURN:
@@ -51,28 +61,63 @@ const (
 	PLAYER_LIMIT int = 5
 	DECK_COUNT   int = 6
 	CUT_LOCATION int = 150
+	MAX_SPLITS   int = 3
+	MAX_SPOTS    int = 2
 )
 
-var StandOnSoft17 bool = true
-
 type Game struct {
 	State              GameState
-	Deck               *Deck
+	Deck               Shoe
 	Players            []*Player
 	DealerHand         *Hand
 	CurrentPlayerIndex int
+	CurrentHandIndex   int
+	Config             GameConfig
+	ShuffleCount       int // How many times the shoe has been shuffled, including the discards mid-round
+	Round              int // The round being played, or the next one between rounds. Starts at 1
 	activePlayers      []*Player
+	roundStart         int // Where in the shoe this round's first card came from
+	events             []Event
+	eventSeq           int
+	automatic          bool // the game is playing for someone. See AutoPlay
 }
 
 func NewGame(config GameConfig) *Game {
 	slog.Info("Creating game")
-	return &Game{
+	if config.Rules.MaxSplits == 0 {
+		config.Rules.MaxSplits = MAX_SPLITS
+	}
+	if config.Rules.MaxSpots == 0 {
+		config.Rules.MaxSpots = MAX_SPOTS
+	}
+	if config.Rules.BlackjackPayout.Denominator == 0 {
+		config.Rules.BlackjackPayout = PAYOUT_3_2
+	}
+	if config.Variant == nil {
+		config.Variant = Classic{}
+	}
+	g := &Game{
 		State:              WAIT_FOR_START,
-		Deck:               CreateDeck(config.DeckCount, config.CutLocation),
+		Deck:               newShoe(config),
 		Players:            make([]*Player, PLAYER_LIMIT),
 		DealerHand:         &Hand{},
 		CurrentPlayerIndex: 0,
+		CurrentHandIndex:   0,
+		Config:             config,
+		Round:              1,
+	}
+	g.emitShuffle(false)
+	if config.BurnCard {
+		g.burnCard()
 	}
+	return g
+}
+
+func newShoe(config GameConfig) Shoe {
+	if config.Rules.ContinuousShuffle {
+		return NewCSM(config.DeckCount, config.Entropy, config.Variant)
+	}
+	return NewVariantDeck(config.DeckCount, config.CutLocation, config.Entropy, config.Variant)
 }
 
 func (g *Game) RemovePlayer(playerId uuid.UUID) error {
@@ -109,6 +154,17 @@ func (g *Game) StartGame() error {
 	return nil
 }
 
+// SkipRound goes back to taking bets after a round nobody bet on. Nothing is dealt and the
+// round number stays the same
+func (g *Game) SkipRound() error {
+	err := g.checkState(WAIT_FOR_START, "SkipRound")
+	if err != nil {
+		return err
+	}
+	g.State = WAITING_FOR_BETS
+	return nil
+}
+
 func (g *Game) EndRound() error {
 	err := g.checkState(RESOLVING_BETS, "EndRound")
 	if err != nil {
@@ -118,6 +174,9 @@ func (g *Game) EndRound() error {
 	return nil
 }
 
+// ErrNoActivePlayers is StartRound finding nobody with a bet down
+var ErrNoActivePlayers = errors.New("no active players in game")
+
 func (g *Game) StartRound() error {
 	err := g.checkState(WAITING_FOR_BETS, "StartRound")
 	if err != nil {
@@ -134,24 +193,34 @@ func (g *Game) StartRound() error {
 	}
 	if len(g.ActivePlayers()) == 0 {
 		g.State = WAIT_FOR_START
-		return fmt.Errorf("no active players in game")
+		return ErrNoActivePlayers
 	}
 	g.State = DEALING
+	started := RoundStarted{Players: []RoundPlayer{}, Variant: g.Config.Variant.Name(), Rules: g.Config.Rules.HandRules()}
+	for _, p := range g.ActivePlayers() {
+		started.Players = append(started.Players, RoundPlayer{PlayerID: p.ID, Name: p.Name, Owner: p.Owner, Bot: p.Bot, Bet: p.Bet, Jackpot: p.Jackpot, SideBets: p.SideBets})
+	}
+	g.emit(started)
 	return nil
 }
 
 func (g *Game) StartPlayerTurn() error {
-	err := g.checkState(DEALING, "StartPlayerTurn")
+	err := g.checkStates("StartPlayerTurn", DEALING, INSURANCE)
 	if err != nil {
 		return err
 	}
 	g.State = PLAYER_TURN
 	p := g.CurrentPlayer()
 	g.CurrentPlayer().State = PLAYING_TURN
+	if p.Hands[0].Surrendered {
+		// surrendered early. There is nothing left to play
+		return g.endPlayerTurn(p)
+	}
+	g.emit(TurnChanged{PlayerID: p.ID, Hand: g.CurrentHandIndex})
 	if !p.DisconnectedAt.IsZero() {
 		// automatic stay if player is disconnected.
 		// INACTIVE should already be set
-		g.Stay(p)
+		g.AutoStay(p)
 	}
 	return nil
 }
@@ -162,6 +231,8 @@ func (g *Game) StartDealerTurn() error {
 		return err
 	}
 	g.State = DEALER_TURN
+	g.emit(TurnChanged{Dealer: true})
+	g.revealHoleCards()
 	return nil
 }
 
@@ -183,30 +254,95 @@ func (g *Game) DealCards() error {
 	g.DealerHand = NewHand()
 	g.activePlayers = g.ActivePlayers()
 	for _, player := range g.activePlayers {
-		player.Hand = NewHand()
+		hand := NewHand()
+		hand.Bet = player.Bet
+		hand.Rules = g.Config.Rules.HandRules()
+		player.Hands = []*Hand{hand}
 		player.State = WAITING_FOR_TURN
 	}
+	g.CurrentHandIndex = 0
+	g.roundStart = len(g.Deck.Base().UsedCards)
 	// Deal Player Cards
 	for range 2 {
 		for _, player := range g.activePlayers {
-			card, err := g.Deck.DrawCard()
+			err := g.dealPlayer(player, 0)
 			if err != nil {
 				slog.Error("Unable to deal card to player", "error", err)
+				return err
 			}
-			player.Hand.AddCard(card)
 		}
 	}
-	for range 2 {
-		card, err := g.Deck.DrawCard()
+	err = g.dealDealer()
+	if err != nil {
+		slog.Error("Unable to deal card to dealer", "error", err)
+		return err
+	}
+	if !g.Config.Rules.NoHoleCard {
+		err = g.dealDealer()
 		if err != nil {
 			slog.Error("Unable to deal card to dealer", "error", err)
+			return err
+		}
+	}
+	g.settleSideBets()
+	if g.insuranceOffered() || g.earlySurrenderOffered() {
+		return g.StartInsurance()
+	}
+	return g.startPlay()
+}
+
+// startPlay peeks at the hole card before handing the round to the players. A dealer
+// blackjack ends the round right away
+func (g *Game) startPlay() error {
+	err := g.checkStates("startPlay", DEALING, INSURANCE)
+	if err != nil {
+		return err
+	}
+	if g.dealerPeeks() {
+		blackjack := g.DealerPeekBlackjack()
+		g.emit(DealerPeeked{Blackjack: blackjack})
+		if blackjack {
+			slog.Info("Dealer has blackjack")
+			g.State = RESOLVING_BETS
+			g.revealHoleCards()
+			return nil
 		}
-		g.DealerHand.AddCard(card)
 	}
 	return g.StartPlayerTurn()
 }
 
+// dealerPeeks is true if the dealer has a hole card under an ace or a ten-value card
+func (g *Game) dealerPeeks() bool {
+	if g.Config.Rules.NoHoleCard || len(g.DealerHand.Cards) < 2 {
+		return false
+	}
+	upValue, _ := calculateValue(g.DealerHand.Cards[:1])
+	return upValue == 10 || upValue == 11
+}
+
+// revealHoleCards turns over every dealer card that was dealt face down
+func (g *Game) revealHoleCards() {
+	up := min(g.Config.Variant.UpCards(), len(g.DealerHand.Cards))
+	for _, c := range g.DealerHand.Cards[up:] {
+		g.emit(HoleCardRevealed{Card: c})
+	}
+}
+
+// DealerPeekBlackjack is true if the dealer shows an ace or a ten-value card and the hole card makes blackjack
+func (g *Game) DealerPeekBlackjack() bool {
+	return g.dealerPeeks() && g.DealerHand.GetState() == BLACKJACK
+}
+
 func (g *Game) Stay(p *Player) error {
+	return g.stay(p, false)
+}
+
+// AutoStay stands for a player who ran out of time or left the table
+func (g *Game) AutoStay(p *Player) error {
+	return g.stay(p, true)
+}
+
+func (g *Game) stay(p *Player, automatic bool) error {
 	err := g.checkState(PLAYER_TURN, "Stay")
 	if err != nil {
 		return err
@@ -214,7 +350,12 @@ func (g *Game) Stay(p *Player) error {
 	if p != g.CurrentPlayer() {
 		return fmt.Errorf("It is not Player %d's turn", p.ID)
 	}
-	g.endPlayerTurn(p)
+	acted := PlayerActed{PlayerID: p.ID, Hand: g.CurrentHandIndex, Action: ACTION_STAND, Automatic: automatic}
+	if !automatic {
+		acted.Advice = g.advice(p)
+	}
+	g.emit(acted)
+	g.endHand(p)
 	return nil
 }
 
@@ -226,22 +367,171 @@ func (g *Game) Hit(p *Player) error {
 	if p != g.CurrentPlayer() {
 		return fmt.Errorf("It is not Player %d's turn", p.ID)
 	}
+	hand := g.CurrentHand()
+	if hand.IsSplitAces() && !g.Config.Rules.HitSplitAces {
+		return fmt.Errorf("You can't hit split aces")
+	}
 
 	// add card to hand
-	c, err := g.Deck.DrawCard()
+	g.emit(PlayerActed{PlayerID: p.ID, Hand: g.CurrentHandIndex, Action: ACTION_HIT, Advice: g.advice(p)})
+	err = g.dealPlayer(p, g.CurrentHandIndex)
 	if err != nil {
 		return err
 	}
-	p.Hand.AddCard(c)
 
 	// update player state
-	if p.Hand.GetState() == BUST || p.Hand.GetState() == TWENTYONE {
-		g.endPlayerTurn(p)
+	if hand.GetState().finished() {
+		g.endHand(p)
+	}
+
+	return nil
+}
+
+func (g *Game) DoubleDown(p *Player) error {
+	err := g.checkState(PLAYER_TURN, "DoubleDown")
+	if err != nil {
+		return err
+	}
+	err = g.checkDouble(p)
+	if err != nil {
+		return err
+	}
+	hand := g.CurrentHand()
+
+	// second bet matches the first one
+	g.emit(PlayerActed{PlayerID: p.ID, Hand: g.CurrentHandIndex, Action: ACTION_DOUBLE, Amount: hand.Bet, Advice: g.advice(p)})
+	g.pay(p, -hand.Bet)
+	p.Bet += hand.Bet
+	hand.Bet *= 2
+	hand.Doubled = true
+	err = g.dealPlayer(p, g.CurrentHandIndex)
+	if err != nil {
+		return err
+	}
+
+	// doubling down always ends the hand after exactly one card
+	g.endHand(p)
+	return nil
+}
+
+// CanDouble is true if the player could double down on the hand they are playing
+func (g *Game) CanDouble(p *Player) bool {
+	return g.State == PLAYER_TURN && g.checkDouble(p) == nil
+}
+
+func (g *Game) checkDouble(p *Player) error {
+	if p != g.CurrentPlayer() {
+		return fmt.Errorf("It is not Player %d's turn", p.ID)
+	}
+	hand := g.CurrentHand()
+	if len(hand.Cards) != 2 {
+		return fmt.Errorf("You can only double down on your first two cards")
+	}
+	if hand.GetState() == BLACKJACK {
+		return fmt.Errorf("You can't double down on a blackjack")
+	}
+	if hand.IsSplitAces() && !g.Config.Rules.HitSplitAces {
+		return fmt.Errorf("You can't double down on split aces")
+	}
+	if hand.Split && !g.Config.Rules.DoubleAfterSplit {
+		return fmt.Errorf("You can't double down after splitting")
+	}
+	if !g.Config.Rules.DoubleRule.Allows(hand.GetValue()) {
+		return fmt.Errorf("You can only double down on %s at this table", g.Config.Rules.DoubleRule)
+	}
+	if hand.Bet > p.Wallet {
+		return fmt.Errorf("Not enough money in wallet to double down")
+	}
+	return nil
+}
+
+func (g *Game) Split(p *Player) error {
+	err := g.checkState(PLAYER_TURN, "Split")
+	if err != nil {
+		return err
+	}
+	err = g.checkSplit(p)
+	if err != nil {
+		return err
+	}
+	hand := g.CurrentHand()
+
+	// the new hand takes the second card of the pair and its own bet
+	g.emit(PlayerActed{PlayerID: p.ID, Hand: g.CurrentHandIndex, Action: ACTION_SPLIT, Amount: hand.Bet, Advice: g.advice(p)})
+	newHand := &Hand{Cards: []Card{hand.Cards[1]}, Bet: hand.Bet, Split: true, Rules: hand.Rules}
+	hand.Cards = hand.Cards[:1]
+	hand.Split = true
+	g.pay(p, -newHand.Bet)
+	p.Bet += newHand.Bet
+	p.Hands = slices.Insert(p.Hands, g.CurrentHandIndex+1, newHand)
+
+	for _, i := range []int{g.CurrentHandIndex, g.CurrentHandIndex + 1} {
+		err := g.dealPlayer(p, i)
+		if err != nil {
+			return err
+		}
 	}
 
+	if g.handFinished(p, hand) {
+		g.endHand(p)
+	}
 	return nil
 }
 
+// CanSplit is true if the player could split the hand they are playing
+func (g *Game) CanSplit(p *Player) bool {
+	return g.State == PLAYER_TURN && g.checkSplit(p) == nil
+}
+
+func (g *Game) checkSplit(p *Player) error {
+	if p != g.CurrentPlayer() {
+		return fmt.Errorf("It is not Player %d's turn", p.ID)
+	}
+	hand := g.CurrentHand()
+	if !hand.IsPair() {
+		return fmt.Errorf("You can only split a pair")
+	}
+	if len(p.Hands) > g.Config.Rules.MaxSplits {
+		return fmt.Errorf("You can't split more than %d times", g.Config.Rules.MaxSplits)
+	}
+	if hand.IsSplitAces() && !g.Config.Rules.ResplitAces {
+		return fmt.Errorf("You can't split aces again")
+	}
+	if hand.Bet > p.Wallet {
+		return fmt.Errorf("Not enough money in wallet to split")
+	}
+	return nil
+}
+
+// handFinished reports whether a hand has no decisions left to make
+func (g *Game) handFinished(p *Player, h *Hand) bool {
+	if h.GetState().finished() {
+		return true
+	}
+	if h.IsSplitAces() && !g.Config.Rules.HitSplitAces {
+		// split aces only stay open when they can be split again
+		canResplit := g.Config.Rules.ResplitAces && h.IsPair() && len(p.Hands) <= g.Config.Rules.MaxSplits
+		return !canResplit
+	}
+	return false
+}
+
+// endHand moves the player on to their next unfinished hand or ends their turn
+func (g *Game) endHand(p *Player) {
+	g.CurrentHandIndex++
+	for g.CurrentHandIndex < len(p.Hands) {
+		if !g.handFinished(p, p.Hands[g.CurrentHandIndex]) {
+			g.emit(TurnChanged{PlayerID: p.ID, Hand: g.CurrentHandIndex})
+			if !p.DisconnectedAt.IsZero() {
+				g.AutoStay(p)
+			}
+			return
+		}
+		g.CurrentHandIndex++
+	}
+	g.endPlayerTurn(p)
+}
+
 func (g *Game) endPlayerTurn(p *Player) error {
 	if p != g.CurrentPlayer() {
 		return fmt.Errorf("It is not Player %d's turn", p.ID)
@@ -249,95 +539,173 @@ func (g *Game) endPlayerTurn(p *Player) error {
 	if p.State != INACTIVE {
 		p.State = DONE
 	}
+	g.CurrentHandIndex = 0
 	if !g.NextPlayer() {
 		slog.Info("Starting dealer turn")
 		g.CurrentPlayerIndex = 0
 		g.StartDealerTurn()
 	} else {
-		g.CurrentPlayer().State = PLAYING_TURN
+		next := g.CurrentPlayer()
+		next.State = PLAYING_TURN
+		if next.Hands[0].Surrendered {
+			return g.endPlayerTurn(next)
+		}
+		g.emit(TurnChanged{PlayerID: next.ID, Hand: 0})
+		if !next.DisconnectedAt.IsZero() {
+			// the player left during the round. Stand for them
+			return g.AutoStay(next)
+		}
 	}
 	return nil
 }
 
-func (g *Game) ResolveBets() (map[uuid.UUID]store.RoundResult, error) {
-	retMap := map[uuid.UUID]store.RoundResult{}
+// ResolveBets pays out every hand and returns one result per hand for each player
+func (g *Game) ResolveBets() (map[uuid.UUID][]store.RoundResult, error) {
+	retMap := map[uuid.UUID][]store.RoundResult{}
 	err := g.checkState(RESOLVING_BETS, "ResolveBets")
 	if err != nil {
 		return retMap, err
 	}
 	for _, player := range g.activePlayers {
-		winAmt := g.calculatePayout(player)
-		player.Wallet += winAmt
-		retMap[player.ID] = store.RoundResult{
-			Outcome:     getOutcome(player.Bet, winAmt),
-			Blackjack:   (player.Hand.GetState() == BLACKJACK),
-			Bet:         player.Bet,
-			Wallet:      player.Wallet,
-			WalletDelta: winAmt - player.Bet,
+		results := []store.RoundResult{}
+		insuranceWin := g.calculateInsurancePayout(player)
+		g.pay(player, insuranceWin)
+		if player.Insurance > 0 {
+			g.emit(InsuranceResolved{PlayerID: player.ID, Amount: player.Insurance, Payout: insuranceWin})
+		}
+		jackpotWin := g.resolveJackpot(player)
+		g.pay(player, jackpotWin)
+		for i, hand := range player.Hands {
+			winAmt := g.calculatePayout(hand)
+			g.pay(player, winAmt)
+			result := store.RoundResult{
+				Outcome:     getOutcome(hand, winAmt),
+				Blackjack:   (hand.GetState() == BLACKJACK),
+				Bet:         hand.Bet,
+				WalletDelta: winAmt - hand.Bet,
+			}
+			results = append(results, result)
+			g.emit(HandResolved{
+				PlayerID:  player.ID,
+				Hand:      i,
+				Outcome:   result.Outcome,
+				Blackjack: result.Blackjack,
+				Bet:       hand.Bet,
+				Payout:    winAmt,
+			})
+		}
+		// insurance and the jackpot are single side bets so they are reported with the first hand
+		if len(results) > 0 {
+			results[0].Insurance = player.Insurance
+			results[0].WalletDelta += insuranceWin - player.Insurance
+			results[0].Jackpot = player.Jackpot
+			results[0].WalletDelta += jackpotWin - player.Jackpot
+			// side bets were paid on the deal
+			results[0].SideBets = player.SideBetResults
+			for _, sb := range player.SideBetResults {
+				results[0].WalletDelta += sb.Payout - sb.Bet
+			}
+		}
+		retMap[player.ID] = results
+	}
+	// every hand reports the wallet after the whole round is paid out, including the owner's other spots
+	for _, player := range g.activePlayers {
+		for i := range retMap[player.ID] {
+			retMap[player.ID][i].Wallet = player.Wallet
 		}
 	}
+	g.emit(RoundEnded{DealerValue: g.DealerHand.GetValue()})
+	g.Round++
 	g.reset()
+	g.reshuffleIfNeeded()
 	return retMap, g.EndRound()
 }
 
-func getOutcome(bet, winAmt int) store.WonState {
+func getOutcome(h *Hand, winAmt int) store.WonState {
+	if h.Surrendered {
+		return store.Surrendered
+	}
 	if winAmt == 0 {
 		return store.Lost
 	}
-	if winAmt == bet {
+	if winAmt == h.Bet {
 		return store.Tied
 	}
 	return store.Won
 }
 
 func (g *Game) reset() {
-	for _, p := range g.ActivePlayers() {
+	// everyone dealt in, including anyone who left during the round
+	for _, p := range g.activePlayers {
 		p.Bet = 0
-		p.Hand = &Hand{Cards: []Card{}}
+		p.Hands = []*Hand{}
+		p.Insurance = 0
+		p.InsuranceDecided = false
+		p.Jackpot = 0
+		p.SideBets = nil
+		p.SideBetResults = nil
 	}
 	g.DealerHand = &Hand{Cards: []Card{}}
 	g.CurrentPlayerIndex = 0
+	g.CurrentHandIndex = 0
 }
 
-func (g *Game) calculatePayout(p *Player) int {
-	pVal := p.Hand.GetValue()
-	pState := p.Hand.GetState()
-	dVal := g.DealerHand.GetValue()
-	dState := g.DealerHand.GetState()
-	if pState == BUST {
+func (g *Game) calculatePayout(h *Hand) int {
+	if h.GetState() == BUST {
 		return 0
 	}
-	if pState == BLACKJACK && dState == BLACKJACK {
-		return p.Bet
-	}
-	if pVal == dVal && dState != BLACKJACK {
-		if pState == BLACKJACK && dState != BLACKJACK {
-			return int(math.Floor(float64(p.Bet)*float64(1.5))) + p.Bet
-		}
-		return p.Bet // push
-	}
-
-	// Win Conditions. Player > dealer. Player is live when dealer busts. BlackJack, but not if dealer also gets blackjack
-	if pVal > dVal || g.DealerHand.GetState() == BUST {
-		if pState == BLACKJACK {
-			// player won with blackjack
-			return int(math.Floor(float64(p.Bet)*float64(1.5))) + p.Bet
-		}
-		// player won regular style
-		return p.Bet * 2
+	if h.Surrendered {
+		return g.calculateSurrenderPayout(h)
+	}
+	if h.EvenMoney {
+		// even money is paid 1:1 no matter what the dealer has
+		return h.Bet * 2
+	}
+	payout := g.Config.Variant.Payout(h, g.DealerHand, g.Config.Rules)
+	if g.DealerHand.GetState() == BLACKJACK {
+		// house rules don't beat a dealer blackjack
+		return payout
+	}
+	rules := g.Config.Rules
+	switch h.GetState() {
+	case CHARLIE:
+		return max(payout, h.Bet*2)
+	case SIX_SEVEN_EIGHT:
+		return max(payout, h.Bet+h.Bet*rules.Bonus678)
+	case SEVEN_SEVEN_SEVEN:
+		return max(payout, h.Bet+h.Bet*rules.Bonus777)
+	case BLACKJACK:
+		return payout
+	}
+	if rules.DealerWinsTies && h.GetValue() == g.DealerHand.GetValue() && h.GetValue() >= 17 {
+		return 0
 	}
-	// player did not win
-	return 0
+	return payout
 }
 
 func (g *Game) CurrentPlayer() *Player {
+	return g.roundPlayers()[g.CurrentPlayerIndex]
+}
+
+// roundPlayers are the players taking turns this round, in order
+func (g *Game) roundPlayers() []*Player {
 	if len(g.activePlayers) == 0 {
 		g.activePlayers = g.ActivePlayers()
 	}
-	return g.activePlayers[g.CurrentPlayerIndex]
+	return g.activePlayers
+}
+
+// CurrentHand is the hand the current player is acting on
+func (g *Game) CurrentHand() *Hand {
+	p := g.CurrentPlayer()
+	if g.CurrentHandIndex >= len(p.Hands) {
+		return nil
+	}
+	return p.Hands[g.CurrentHandIndex]
 }
 
-func (g *Game) PlaceBet(p *Player, bet int) error {
+// PlaceBet puts down the player's main bet and any side bets for the next round
+func (g *Game) PlaceBet(p *Player, bet Bet) error {
 	err := g.checkState(WAITING_FOR_BETS, "PlaceBet")
 	if err != nil {
 		return err
@@ -346,22 +714,42 @@ func (g *Game) PlaceBet(p *Player, bet int) error {
 		return fmt.Errorf("Player %d not in this game", p.ID)
 	}
 	i := slices.Index(g.Players, p)
-	err = p.ValidateBet(bet)
+	if p.State == BETS_MADE {
+		return g.Config.Rules.betError(BET_ALREADY_MADE, bet.Main)
+	}
+	err = p.ValidateBet(bet.Main)
 	if err != nil {
 		return err
 	}
-	if p.State == BETS_MADE {
-		return fmt.Errorf("Bet already made. You can't make another bet")
+	err = g.Config.Rules.ValidateBet(bet.Main)
+	if err != nil {
+		return err
 	}
-	g.Players[i].Bet = bet
-	g.Players[i].Wallet -= bet
+	err = g.validateSideBets(bet)
+	if err != nil {
+		return err
+	}
+	err = p.ValidateBet(bet.Total())
+	if err != nil {
+		return err
+	}
+	sideBets := maps.Clone(bet.SideBets)
+	maps.DeleteFunc(sideBets, func(_ SideBet, amount int) bool { return amount == 0 })
+	g.Players[i].Bet = bet.Main
+	g.Players[i].SideBets = sideBets
+	g.pay(g.Players[i], -bet.Total())
 	g.Players[i].State = BETS_MADE
+	g.Players[i].LastBet = Bet{Main: bet.Main, SideBets: maps.Clone(sideBets)}
+	g.Players[i].SittingOut = false
+	g.emit(BetPlaced{PlayerID: p.ID, Amount: bet.Main, SideBets: sideBets, Wallet: p.Wallet})
 	return nil
 }
 
+// NextPlayer moves the turn along the players dealt into the round. Anyone who has left since
+// the deal keeps their place in the order
 func (g *Game) NextPlayer() bool {
 	g.CurrentPlayerIndex++
-	return g.CurrentPlayerIndex < len(g.ActivePlayers())
+	return g.CurrentPlayerIndex < len(g.roundPlayers())
 }
 
 func (g *Game) ActivePlayers() []*Player {
@@ -384,25 +772,111 @@ func (g *Game) PlayDealer() error {
 	}
 hitPhase:
 	for g.DealerHand.GetValue() < 17 {
-		c, err := g.Deck.DrawCard()
+		err := g.dealDealer()
 		if err != nil {
 			return err
 		}
-		g.DealerHand.AddCard(c)
 	}
 
-	if g.DealerHand.GetValue() == 17 && g.DealerHand.IsSoft() && !StandOnSoft17 {
-		c, err := g.Deck.DrawCard()
+	if g.DealerHand.GetValue() == 17 && g.DealerHand.IsSoft() && !g.Config.Rules.StandOnSoft17 {
+		err := g.dealDealer()
 		if err != nil {
 			return err
 		}
-		g.DealerHand.AddCard(c)
 		goto hitPhase
 	}
 	g.StartResolvingBets()
 	return nil
 }
 
+// drawCard deals from the shoe. If the shoe runs dry mid-round the discards are shuffled back in
+func (g *Game) drawCard() (Card, error) {
+	if len(g.Deck.Base().Cards) == 0 {
+		slog.Warn("Shoe ran out mid-round. Reshuffling the discards")
+		g.Deck.ReshuffleDiscards(g.cardsInPlay())
+		g.ShuffleCount++
+		g.emitShuffle(true)
+	}
+	return g.Deck.DrawCard()
+}
+
+// dealPlayer deals the next card onto one of the player's hands
+func (g *Game) dealPlayer(p *Player, hand int) error {
+	c, err := g.drawCard()
+	if err != nil {
+		return err
+	}
+	p.Hands[hand].AddCard(c)
+	g.emit(CardDealt{PlayerID: p.ID, Hand: hand, Card: c})
+	return nil
+}
+
+// dealDealer deals the next card to the dealer. Cards dealt past the variant's up cards are hole cards
+func (g *Game) dealDealer() error {
+	c, err := g.drawCard()
+	if err != nil {
+		return err
+	}
+	g.DealerHand.AddCard(c)
+	faceDown := g.State == DEALING && len(g.DealerHand.Cards) > g.Config.Variant.UpCards()
+	g.emit(CardDealt{Dealer: true, Card: c, FaceDown: faceDown})
+	return nil
+}
+
+func (g *Game) burnCard() {
+	d := g.Deck.Base()
+	if err := g.Deck.Burn(); err != nil {
+		slog.Error("Unable to burn a card", "error", err)
+		return
+	}
+	g.emit(CardBurned{Card: d.UsedCards[len(d.UsedCards)-1]})
+}
+
+func (g *Game) emitShuffle(discards bool) {
+	d := g.Deck.Base()
+	g.emit(ShoeShuffled{Seed: d.Seed, Decks: d.DeckCount, Cards: len(d.Cards), Discards: discards})
+}
+
+// reshuffleIfNeeded shuffles the whole shoe between rounds once the cut card has come out
+func (g *Game) reshuffleIfNeeded() {
+	if !g.Deck.NeedsReshuffle() {
+		return
+	}
+	slog.Info("Cut card reached. Shuffling the shoe")
+	g.shuffleShoe()
+}
+
+// ShuffleShoe brings every card back and starts a new shoe. Only allowed between rounds
+func (g *Game) ShuffleShoe() error {
+	err := g.checkStates("ShuffleShoe", WAIT_FOR_START, WAITING_FOR_BETS)
+	if err != nil {
+		return err
+	}
+	g.shuffleShoe()
+	return nil
+}
+
+func (g *Game) shuffleShoe() {
+	seed := g.Deck.Base().Seed
+	g.Deck.Shuffle()
+	g.ShuffleCount++
+	// a continuous shuffler keeps its seed, so its rounds all come out of one shoe
+	g.emitShuffle(g.Deck.Base().Seed == seed)
+	if g.Config.BurnCard {
+		g.burnCard()
+	}
+}
+
+func (g *Game) cardsInPlay() []Card {
+	cards := slices.Clone(g.DealerHand.Cards)
+	for _, p := range g.activePlayers {
+		for _, h := range p.Hands {
+			cards = append(cards, h.Cards...)
+		}
+	}
+	return cards
+}
+
 func (g *Game) checkState(expected GameState, method string) error {
 	if g.State != expected {
 		return fmt.Errorf("Method %s() cannot be run from state %s", method, g.State)
@@ -410,6 +884,13 @@ func (g *Game) checkState(expected GameState, method string) error {
 	return nil
 }
 
+func (g *Game) checkStates(method string, expected ...GameState) error {
+	if !slices.Contains(expected, g.State) {
+		return fmt.Errorf("Method %s() cannot be run from state %s", method, g.State)
+	}
+	return nil
+}
+
 func (g *Game) GetPlayer(playerId uuid.UUID) *Player {
 	for _, p := range g.Players {
 		if p == nil {
@@ -422,11 +903,20 @@ func (g *Game) GetPlayer(playerId uuid.UUID) *Player {
 	return nil
 }
 
+// AllPlayersBet is true once everyone who isn't sitting out has bet. A table where everyone is
+// sitting out waits for the bet timer
 func (g *Game) AllPlayersBet() bool {
+	bet, sittingOut := false, false
 	for _, p := range g.Players {
-		if p != nil && p.Bet == 0 {
+		switch {
+		case p == nil:
+		case p.SittingOut && p.Bet == 0:
+			sittingOut = true
+		case p.Bet == 0:
 			return false
+		default:
+			bet = true
 		}
 	}
-	return true
+	return bet || !sittingOut
 }
@@ -1,14 +1,20 @@
 package game
 
 import (
+	"encoding/json"
+	"errors"
+	"math/rand/v2"
+	"slices"
 	"testing"
 
+	"github.com/dylanmccormick/blackjack-tui/store"
 	"github.com/google/uuid"
 )
 
 var GC = GameConfig{
 	DeckCount:   6,
 	CutLocation: 150,
+	Rules:       DefaultRules(),
 }
 
 func TestDeckHasCorrectCardCount(t *testing.T) {
@@ -106,6 +112,58 @@ func TestUsedCardsLimit(t *testing.T) {
 	}
 }
 
+func TestSeededDeck(t *testing.T) {
+	seed := Seed{1, 2, 3}
+	first := CreateDeck(2, 0)
+	second := CreateDeck(2, 0)
+	first.ShuffleWithSeed(seed)
+	second.DrawCard()
+	second.ShuffleWithSeed(seed)
+	if !slices.Equal(first.Cards, second.Cards) {
+		t.Fatalf("Same seed should give the same shoe")
+	}
+	if first.Seed != seed {
+		t.Errorf("Deck should record its seed. expected=%s got=%s", seed, first.Seed)
+	}
+
+	first.ShuffleWithSeed(Seed{4, 5, 6})
+	if slices.Equal(first.Cards, second.Cards) {
+		t.Errorf("Different seeds should give different shoes")
+	}
+}
+
+func TestDeckEntropy(t *testing.T) {
+	first := NewDeck(1, 0, rand.NewChaCha8(Seed{42}))
+	second := NewDeck(1, 0, rand.NewChaCha8(Seed{42}))
+	for range 3 {
+		if first.Seed != second.Seed || !slices.Equal(first.Cards, second.Cards) {
+			t.Fatalf("Decks from the same entropy should match. got=%s and %s", first.Seed, second.Seed)
+		}
+		previous := first.Seed
+		first.Shuffle()
+		second.Shuffle()
+		if first.Seed == previous {
+			t.Fatalf("Every shuffle should use a new seed. got=%s", first.Seed)
+		}
+	}
+}
+
+func TestParseSeed(t *testing.T) {
+	seed := Seed{0xde, 0xad, 0xbe, 0xef}
+	got, err := ParseSeed(seed.String())
+	if err != nil {
+		t.Fatalf("Unexpected error parsing seed: %s", err)
+	}
+	if got != seed {
+		t.Errorf("Seed did not round trip. expected=%s got=%s", seed, got)
+	}
+	for _, bad := range []string{"", "xyz", "deadbeef"} {
+		if _, err := ParseSeed(bad); err == nil {
+			t.Errorf("Expected an error parsing %q", bad)
+		}
+	}
+}
+
 func TestOverdrawnDeck(t *testing.T) {
 	deck := CreateDeck(1, 0)
 
@@ -203,15 +261,15 @@ func TestPlaceBet(t *testing.T) {
 	game.AddPlayer(p2)
 	err = game.StartGame()
 	genericErrHelper(t, err)
-	err = game.PlaceBet(p1, 5)
+	err = game.PlaceBet(p1, Bet{Main: 5})
 	if err != nil {
 		t.Fatalf("No error expected for placing bet. got=%#v", err)
 	}
-	err = game.PlaceBet(p2, 5)
+	err = game.PlaceBet(p2, Bet{Main: 5})
 	if err == nil {
 		t.Fatalf("Error expected for placing bet, but got nil")
 	}
-	err = game.PlaceBet(p2, -5)
+	err = game.PlaceBet(p2, Bet{Main: -5})
 	if err == nil {
 		t.Fatalf("Error expected for placing negative bet, but got nil")
 	}
@@ -256,18 +314,72 @@ func TestNextPlayer(t *testing.T) {
 	}
 }
 
+func TestLeftPlayerKeepsTurnOrder(t *testing.T) {
+	suit := suit("spade")
+	g := NewGame(GC)
+	for range 8 {
+		g.Deck.Base().Cards = append([]Card{{suit, 5}}, g.Deck.Base().Cards...)
+	}
+	players := []*Player{}
+	for range 3 {
+		p := &Player{ID: uuid.New(), Wallet: 100}
+		genericErrHelper(t, g.AddPlayer(p))
+		players = append(players, p)
+	}
+	genericErrHelper(t, g.StartGame())
+	for _, p := range players {
+		genericErrHelper(t, g.PlaceBet(p, Bet{Main: 10}))
+	}
+	genericErrHelper(t, g.StartRound())
+	genericErrHelper(t, g.DealCards())
+	if g.State != PLAYER_TURN || g.CurrentPlayer() != players[0] {
+		t.Fatalf("Expected the first player to act. state=%s", g.State)
+	}
+
+	// the second player leaves before their turn. Their spot stands and the third player still gets a turn
+	players[1].MarkDisconnected(true)
+	genericErrHelper(t, g.Stay(players[0]))
+	if g.State != PLAYER_TURN || g.CurrentPlayer() != players[2] {
+		t.Fatalf("Expected the turn to pass the player who left. state=%s", g.State)
+	}
+	genericErrHelper(t, g.Stay(players[2]))
+	if g.State != DEALER_TURN {
+		t.Fatalf("Expected the dealer to play. got=%s", g.State)
+	}
+	genericErrHelper(t, g.PlayDealer())
+	results, err := g.ResolveBets()
+	genericErrHelper(t, err)
+	if len(results[players[1].ID]) != 1 {
+		t.Errorf("Expected the player who left to have their bet resolved. got=%v", results[players[1].ID])
+	}
+	if players[1].Bet != 0 || len(players[1].Hands) != 0 {
+		t.Errorf("Expected the player who left to be cleared for the next round. bet=%d hands=%d", players[1].Bet, len(players[1].Hands))
+	}
+}
+
 func TestGameFlow(t *testing.T) {
 	u1, err := uuid.NewUUID()
 	if err != nil {
 		t.Fatalf("Unable to create UUID err:%#v", err)
 	}
+	suit := suit("spade")
 	game := NewGame(GC)
+	// keep an ace away from the dealer's up card so the round skips insurance
+	game.Deck.Base().Cards = append(
+		[]Card{
+			{suit, 10}, // player cards
+			{suit, 9},
+			{suit, 10}, // dealer cards
+			{suit, 7},
+		},
+		game.Deck.Base().Cards...,
+	)
 	p1 := &Player{ID: u1, Wallet: 10, State: BETTING}
 	err = game.AddPlayer(p1)
 	genericErrHelper(t, err)
 	err = game.StartGame()
 	genericErrHelper(t, err)
-	err = game.PlaceBet(p1, 5)
+	err = game.PlaceBet(p1, Bet{Main: 5})
 	genericErrHelper(t, err)
 	if p1.Wallet != 5 {
 		t.Errorf("player wallet not updated when betting. expected=%d got=%d", 5, p1.Wallet)
@@ -310,7 +422,7 @@ func TestGameFlowErrors(t *testing.T) {
 	genericErrHelper(t, err)
 	err = game.StartGame()
 	genericErrHelper(t, err)
-	err = game.PlaceBet(p1, 5)
+	err = game.PlaceBet(p1, Bet{Main: 5})
 	genericErrHelper(t, err)
 	err = game.PlayDealer()
 	if err == nil {
@@ -322,6 +434,33 @@ func TestGameFlowErrors(t *testing.T) {
 	}
 }
 
+func TestSkipRound(t *testing.T) {
+	u1, err := uuid.NewUUID()
+	if err != nil {
+		t.Fatalf("Unable to create UUID err:%#v", err)
+	}
+	game := NewGame(GC)
+	p1 := &Player{ID: u1, Wallet: 10, State: BETTING}
+	genericErrHelper(t, game.AddPlayer(p1))
+	genericErrHelper(t, game.StartGame())
+	if err = game.SkipRound(); err == nil {
+		t.Fatalf("Expected error from game.SkipRound() while taking bets. got nil")
+	}
+	err = game.StartRound()
+	if !errors.Is(err, ErrNoActivePlayers) {
+		t.Fatalf("Expected a round nobody bet on not to start. got=%v", err)
+	}
+	genericErrHelper(t, game.SkipRound())
+	if game.State != WAITING_FOR_BETS || game.Round != 1 {
+		t.Fatalf("Expected to take bets for the same round. state=%s round=%d", game.State, game.Round)
+	}
+	genericErrHelper(t, game.PlaceBet(p1, Bet{Main: 5}))
+	genericErrHelper(t, game.StartRound())
+	if err = game.SkipRound(); err == nil {
+		t.Fatalf("Expected error from game.SkipRound() once the round started. got nil")
+	}
+}
+
 func TestCalculatePayout(t *testing.T) {
 	suit := suit("spade")
 	tests := []struct {
@@ -345,8 +484,8 @@ func TestCalculatePayout(t *testing.T) {
 	genericErrHelper(t, err)
 	for _, tt := range tests {
 		g.DealerHand = tt.DealerHand
-		p1.Hand = tt.PlayerHand
-		payout := g.calculatePayout(p1)
+		tt.PlayerHand.Bet = p1.Bet
+		payout := g.calculatePayout(tt.PlayerHand)
 		if payout != tt.ExpectedPayout {
 			t.Errorf("payout calculation incorrect. expected=%d got=%d", tt.ExpectedPayout, payout)
 		}
@@ -356,7 +495,7 @@ func TestCalculatePayout(t *testing.T) {
 func TestHitUntilBust(t *testing.T) {
 	suit := suit("spade")
 	g := NewGame(GC)
-	g.Deck.Cards = append(
+	g.Deck.Base().Cards = append(
 		[]Card{
 			{suit, 10}, // player cards
 			{suit, 10},
@@ -364,7 +503,7 @@ func TestHitUntilBust(t *testing.T) {
 			{suit, 10},
 			{suit, 10}, // busting card (player)
 		},
-		g.Deck.Cards...,
+		g.Deck.Base().Cards...,
 	)
 	u1, err := uuid.NewUUID()
 	if err != nil {
@@ -375,7 +514,7 @@ func TestHitUntilBust(t *testing.T) {
 	genericErrHelper(t, err)
 	err = g.StartGame()
 	genericErrHelper(t, err)
-	err = g.PlaceBet(p1, 5)
+	err = g.PlaceBet(p1, Bet{Main: 5})
 	genericErrHelper(t, err)
 	err = g.StartRound()
 	genericErrHelper(t, err)
@@ -394,7 +533,7 @@ func TestHitUntilBust(t *testing.T) {
 func TestHitUntilStay(t *testing.T) {
 	suit := suit("spade")
 	g := NewGame(GC)
-	g.Deck.Cards = append(
+	g.Deck.Base().Cards = append(
 		[]Card{
 			{suit, 2}, // player cards
 			{suit, 2},
@@ -409,7 +548,7 @@ func TestHitUntilStay(t *testing.T) {
 			{suit, 2},
 			{suit, 2},
 		},
-		g.Deck.Cards...,
+		g.Deck.Base().Cards...,
 	)
 	u1, err := uuid.NewUUID()
 	if err != nil {
@@ -420,7 +559,7 @@ func TestHitUntilStay(t *testing.T) {
 	genericErrHelper(t, err)
 	err = g.StartGame()
 	genericErrHelper(t, err)
-	err = g.PlaceBet(p1, 5)
+	err = g.PlaceBet(p1, Bet{Main: 5})
 	genericErrHelper(t, err)
 	err = g.StartRound()
 	genericErrHelper(t, err)
@@ -447,10 +586,11 @@ func TestHitUntilStay(t *testing.T) {
 }
 
 func TestDealerLogicHitSoft17(t *testing.T) {
-	StandOnSoft17 = false
 	suit := suit("spade")
-	g := NewGame(GC)
-	g.Deck.Cards = append(
+	config := GC
+	config.Rules.StandOnSoft17 = false
+	g := NewGame(config)
+	g.Deck.Base().Cards = append(
 		[]Card{
 			{suit, 10}, // player cards
 			{suit, 10},
@@ -458,7 +598,7 @@ func TestDealerLogicHitSoft17(t *testing.T) {
 			{suit, 6},
 			{suit, 10},
 		},
-		g.Deck.Cards...,
+		g.Deck.Base().Cards...,
 	)
 	u1, err := uuid.NewUUID()
 	if err != nil {
@@ -469,12 +609,14 @@ func TestDealerLogicHitSoft17(t *testing.T) {
 	genericErrHelper(t, err)
 	err = g.StartGame()
 	genericErrHelper(t, err)
-	err = g.PlaceBet(p1, 5)
+	err = g.PlaceBet(p1, Bet{Main: 5})
 	genericErrHelper(t, err)
 	err = g.StartRound()
 	genericErrHelper(t, err)
 	err = g.DealCards() // bet is already set in player struct
 	genericErrHelper(t, err)
+	err = g.EndInsurance()
+	genericErrHelper(t, err)
 	err = g.Stay(p1)
 	genericErrHelper(t, err)
 	err = g.PlayDealer()
@@ -485,10 +627,11 @@ func TestDealerLogicHitSoft17(t *testing.T) {
 }
 
 func TestDealerLogicStandSoft17(t *testing.T) {
-	StandOnSoft17 = true
 	suit := suit("spade")
-	g := NewGame(GC)
-	g.Deck.Cards = append(
+	config := GC
+	config.Rules.StandOnSoft17 = true
+	g := NewGame(config)
+	g.Deck.Base().Cards = append(
 		[]Card{
 			{suit, 10}, // player cards
 			{suit, 10},
@@ -496,7 +639,7 @@ func TestDealerLogicStandSoft17(t *testing.T) {
 			{suit, 6},
 			{suit, 10},
 		},
-		g.Deck.Cards...,
+		g.Deck.Base().Cards...,
 	)
 	u1, err := uuid.NewUUID()
 	if err != nil {
@@ -507,12 +650,14 @@ func TestDealerLogicStandSoft17(t *testing.T) {
 	genericErrHelper(t, err)
 	err = g.StartGame()
 	genericErrHelper(t, err)
-	err = g.PlaceBet(p1, 5)
+	err = g.PlaceBet(p1, Bet{Main: 5})
 	genericErrHelper(t, err)
 	err = g.StartRound()
 	genericErrHelper(t, err)
 	err = g.DealCards() // bet is already set in player struct
 	genericErrHelper(t, err)
+	err = g.EndInsurance()
+	genericErrHelper(t, err)
 	err = g.Stay(p1)
 	genericErrHelper(t, err)
 	err = g.PlayDealer()
@@ -521,3 +666,1880 @@ func TestDealerLogicStandSoft17(t *testing.T) {
 		t.Fatalf("dealer logic incorrect. dealer has incorrect amount of cards. expected=%d got=%d", 2, len(g.DealerHand.Cards))
 	}
 }
+
+func TestDoubleDown(t *testing.T) {
+	suit := suit("spade")
+	g := NewGame(GC)
+	g.Deck.Base().Cards = append(
+		[]Card{
+			{suit, 5}, // player cards
+			{suit, 6},
+			{suit, 10}, // dealer cards
+			{suit, 7},
+			{suit, 10}, // double down card (player)
+		},
+		g.Deck.Base().Cards...,
+	)
+	u1, err := uuid.NewUUID()
+	if err != nil {
+		t.Fatalf("Unable to create UUID err:%#v", err)
+	}
+	p1 := &Player{ID: u1, Wallet: 100}
+	err = g.AddPlayer(p1)
+	genericErrHelper(t, err)
+	err = g.StartGame()
+	genericErrHelper(t, err)
+	err = g.PlaceBet(p1, Bet{Main: 10})
+	genericErrHelper(t, err)
+	err = g.StartRound()
+	genericErrHelper(t, err)
+	err = g.DealCards()
+	genericErrHelper(t, err)
+	err = g.DoubleDown(p1)
+	genericErrHelper(t, err)
+	if p1.Bet != 20 || p1.Hands[0].Bet != 20 {
+		t.Fatalf("bet not doubled. expected=%d got=%d", 20, p1.Hands[0].Bet)
+	}
+	if p1.Wallet != 80 {
+		t.Fatalf("second bet not taken from wallet. expected=%d got=%d", 80, p1.Wallet)
+	}
+	if len(p1.Hands[0].Cards) != 3 {
+		t.Fatalf("expected exactly one card after doubling down. expected=%d got=%d", 3, len(p1.Hands[0].Cards))
+	}
+	if g.State != DEALER_TURN {
+		t.Fatalf("incorrect state after doubling down. expected=%s got=%s", DEALER_TURN, g.State)
+	}
+	err = g.PlayDealer()
+	genericErrHelper(t, err)
+	results, err := g.ResolveBets()
+	genericErrHelper(t, err)
+	res := results[p1.ID][0]
+	if res.Bet != 20 {
+		t.Errorf("round result does not report doubled bet. expected=%d got=%d", 20, res.Bet)
+	}
+	if res.WalletDelta != 20 {
+		t.Errorf("round result wallet delta incorrect. expected=%d got=%d", 20, res.WalletDelta)
+	}
+	if p1.Wallet != 120 {
+		t.Errorf("wallet incorrect after winning double down. expected=%d got=%d", 120, p1.Wallet)
+	}
+}
+
+func TestDoubleDownErrors(t *testing.T) {
+	suit := suit("spade")
+	g := NewGame(GC)
+	g.Deck.Base().Cards = append(
+		[]Card{
+			{suit, 2}, // player cards
+			{suit, 3},
+			{suit, 10}, // dealer cards
+			{suit, 7},
+			{suit, 2}, // hit card (player)
+		},
+		g.Deck.Base().Cards...,
+	)
+	u1, err := uuid.NewUUID()
+	if err != nil {
+		t.Fatalf("Unable to create UUID err:%#v", err)
+	}
+	p1 := &Player{ID: u1, Wallet: 15}
+	err = g.AddPlayer(p1)
+	genericErrHelper(t, err)
+	err = g.DoubleDown(p1)
+	if err == nil {
+		t.Fatalf("Expected error doubling down outside of player turn. got nil")
+	}
+	err = g.StartGame()
+	genericErrHelper(t, err)
+	err = g.PlaceBet(p1, Bet{Main: 10})
+	genericErrHelper(t, err)
+	err = g.StartRound()
+	genericErrHelper(t, err)
+	err = g.DealCards()
+	genericErrHelper(t, err)
+	err = g.DoubleDown(p1)
+	if err == nil {
+		t.Fatalf("Expected error doubling down without enough money. got nil")
+	}
+	p1.Wallet = 100
+	err = g.Hit(p1)
+	genericErrHelper(t, err)
+	err = g.DoubleDown(p1)
+	if err == nil {
+		t.Fatalf("Expected error doubling down on three cards. got nil")
+	}
+}
+
+func TestSplit(t *testing.T) {
+	suit := suit("spade")
+	g := NewGame(GC)
+	g.Deck.Base().Cards = append(
+		[]Card{
+			{suit, 8}, // player cards
+			{suit, 8},
+			{suit, 10}, // dealer cards
+			{suit, 8},
+			{suit, 3},  // first split hand
+			{suit, 10}, // second split hand
+			{suit, 10}, // hit on first split hand
+		},
+		g.Deck.Base().Cards...,
+	)
+	u1, err := uuid.NewUUID()
+	if err != nil {
+		t.Fatalf("Unable to create UUID err:%#v", err)
+	}
+	p1 := &Player{ID: u1, Wallet: 100}
+	err = g.AddPlayer(p1)
+	genericErrHelper(t, err)
+	err = g.StartGame()
+	genericErrHelper(t, err)
+	err = g.PlaceBet(p1, Bet{Main: 10})
+	genericErrHelper(t, err)
+	err = g.StartRound()
+	genericErrHelper(t, err)
+	err = g.DealCards()
+	genericErrHelper(t, err)
+	err = g.Split(p1)
+	genericErrHelper(t, err)
+	if len(p1.Hands) != 2 {
+		t.Fatalf("expected two hands after splitting. got=%d", len(p1.Hands))
+	}
+	if p1.Wallet != 80 {
+		t.Fatalf("split bet not taken from wallet. expected=%d got=%d", 80, p1.Wallet)
+	}
+	for i, hand := range p1.Hands {
+		if hand.Bet != 10 {
+			t.Errorf("split hand %d has incorrect bet. expected=%d got=%d", i, 10, hand.Bet)
+		}
+		if len(hand.Cards) != 2 {
+			t.Errorf("split hand %d has incorrect amount of cards. expected=%d got=%d", i, 2, len(hand.Cards))
+		}
+	}
+	if g.CurrentHand() != p1.Hands[0] {
+		t.Fatalf("expected to play the first split hand")
+	}
+	err = g.Hit(p1) // 8 + 3 + 10 = 21 moves on to the next hand
+	genericErrHelper(t, err)
+	if g.CurrentHand() != p1.Hands[1] || g.State != PLAYER_TURN {
+		t.Fatalf("expected to play the second split hand. state=%s hand=%d", g.State, g.CurrentHandIndex)
+	}
+	err = g.Stay(p1)
+	genericErrHelper(t, err)
+	if g.State != DEALER_TURN {
+		t.Fatalf("incorrect state after all split hands played. expected=%s got=%s", DEALER_TURN, g.State)
+	}
+	err = g.PlayDealer()
+	genericErrHelper(t, err)
+	results, err := g.ResolveBets()
+	genericErrHelper(t, err)
+	if len(results[p1.ID]) != 2 {
+		t.Fatalf("expected a result per hand. got=%d", len(results[p1.ID]))
+	}
+	// 21 wins against 18, 18 pushes
+	if results[p1.ID][0].Outcome != store.Won || results[p1.ID][1].Outcome != store.Tied {
+		t.Errorf("incorrect outcomes for split hands. got=%v, %v", results[p1.ID][0].Outcome, results[p1.ID][1].Outcome)
+	}
+	if p1.Wallet != 110 {
+		t.Errorf("wallet incorrect after split hands resolved. expected=%d got=%d", 110, p1.Wallet)
+	}
+	for _, res := range results[p1.ID] {
+		if res.Wallet != p1.Wallet {
+			t.Errorf("result wallet should match final wallet. expected=%d got=%d", p1.Wallet, res.Wallet)
+		}
+	}
+}
+
+func TestSplitAces(t *testing.T) {
+	suit := suit("spade")
+	tests := []struct {
+		name          string
+		config        GameConfig
+		expectedState GameState
+		expectedHands int
+	}{
+		{"no_hit_split_aces", GameConfig{}, DEALER_TURN, 2},
+		{"hit_split_aces", GameConfig{Rules: RuleSet{HitSplitAces: true}}, PLAYER_TURN, 2},
+		{"resplit_aces", GameConfig{Rules: RuleSet{ResplitAces: true}}, PLAYER_TURN, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewGame(tt.config)
+			g.Deck.Base().Cards = append(
+				[]Card{
+					{suit, ACE}, // player cards
+					{suit, ACE},
+					{suit, 10}, // dealer cards
+					{suit, 7},
+					{suit, ACE}, // first split hand
+					{suit, 5},   // second split hand
+				},
+				g.Deck.Base().Cards...,
+			)
+			p1 := &Player{ID: uuid.New(), Wallet: 100}
+			genericErrHelper(t, g.AddPlayer(p1))
+			genericErrHelper(t, g.StartGame())
+			genericErrHelper(t, g.PlaceBet(p1, Bet{Main: 10}))
+			genericErrHelper(t, g.StartRound())
+			genericErrHelper(t, g.DealCards())
+			genericErrHelper(t, g.Split(p1))
+			if g.State != tt.expectedState {
+				t.Fatalf("incorrect state after splitting aces. expected=%s got=%s", tt.expectedState, g.State)
+			}
+			if len(p1.Hands) != tt.expectedHands {
+				t.Fatalf("incorrect number of hands. expected=%d got=%d", tt.expectedHands, len(p1.Hands))
+			}
+			if p1.Hands[0].GetState() == BLACKJACK {
+				t.Errorf("split hands should never count as blackjack")
+			}
+		})
+	}
+}
+
+func TestSplitErrors(t *testing.T) {
+	suit := suit("spade")
+	g := NewGame(GameConfig{Rules: RuleSet{MaxSplits: 1}})
+	g.Deck.Base().Cards = append(
+		[]Card{
+			{suit, 8}, // player cards
+			{suit, 9},
+			{suit, 10}, // dealer cards
+			{suit, 7},
+		},
+		g.Deck.Base().Cards...,
+	)
+	p1 := &Player{ID: uuid.New(), Wallet: 100}
+	genericErrHelper(t, g.AddPlayer(p1))
+	genericErrHelper(t, g.StartGame())
+	genericErrHelper(t, g.PlaceBet(p1, Bet{Main: 10}))
+	genericErrHelper(t, g.StartRound())
+	genericErrHelper(t, g.DealCards())
+	err := g.Split(p1)
+	if err == nil {
+		t.Fatalf("Expected error splitting a hand that isn't a pair. got nil")
+	}
+	p1.Hands[0].Cards = []Card{{suit, 8}, {suit, 8}}
+	p1.Hands = append(p1.Hands, &Hand{Cards: []Card{{suit, 8}, {suit, 10}}, Bet: 10, Split: true})
+	err = g.Split(p1)
+	if err == nil {
+		t.Fatalf("Expected error splitting more than max splits. got nil")
+	}
+}
+
+// dealGameHelper deals one round to a single player with 100 in their wallet. The cards go on
+// top of the shoe. At a table with the jackpot the player takes the jackpot bet too
+func dealGameHelper(t *testing.T, config GameConfig, cards []Card, bet Bet) (*Game, *Player) {
+	g := NewGame(config)
+	g.Deck.Base().Cards = append(cards, g.Deck.Base().Cards...)
+	p1 := &Player{ID: uuid.New(), Wallet: 100}
+	genericErrHelper(t, g.AddPlayer(p1))
+	genericErrHelper(t, g.StartGame())
+	genericErrHelper(t, g.PlaceBet(p1, bet))
+	if config.Jackpot != nil && config.Rules.JackpotBet > 0 {
+		genericErrHelper(t, g.PlaceJackpotBet(p1))
+	}
+	genericErrHelper(t, g.StartRound())
+	genericErrHelper(t, g.DealCards())
+	return g, p1
+}
+
+func TestInsurance(t *testing.T) {
+	suit := suit("spade")
+	g, p1 := dealGameHelper(t, GC, []Card{
+		{suit, 10}, // player cards
+		{suit, 9},
+		{suit, ACE}, // dealer cards
+		{suit, KING},
+	}, Bet{Main: 10})
+	if g.State != INSURANCE {
+		t.Fatalf("dealer ace should open insurance. expected=%s got=%s", INSURANCE, g.State)
+	}
+	err := g.PlaceInsurance(p1, 5)
+	genericErrHelper(t, err)
+	if p1.Wallet != 85 {
+		t.Fatalf("insurance not taken from wallet. expected=%d got=%d", 85, p1.Wallet)
+	}
+	if !g.AllInsuranceDecided() {
+		t.Fatalf("expected all players to have decided on insurance")
+	}
+	err = g.EndInsurance()
+	genericErrHelper(t, err)
+	if g.State != RESOLVING_BETS {
+		t.Fatalf("dealer blackjack should end the round after insurance. expected=%s got=%s", RESOLVING_BETS, g.State)
+	}
+	results, err := g.ResolveBets()
+	genericErrHelper(t, err)
+	// lose the 10 bet, insurance pays 2:1 on 5
+	if p1.Wallet != 100 {
+		t.Errorf("wallet incorrect after insurance payout. expected=%d got=%d", 100, p1.Wallet)
+	}
+	res := results[p1.ID][0]
+	if res.Insurance != 5 {
+		t.Errorf("round result does not report insurance. expected=%d got=%d", 5, res.Insurance)
+	}
+	if res.WalletDelta != 0 {
+		t.Errorf("round result wallet delta incorrect. expected=%d got=%d", 0, res.WalletDelta)
+	}
+}
+
+func TestEvenMoney(t *testing.T) {
+	suit := suit("spade")
+	g, p1 := dealGameHelper(t, GC, []Card{
+		{suit, ACE}, // player cards
+		{suit, KING},
+		{suit, ACE}, // dealer cards
+		{suit, KING},
+	}, Bet{Main: 10})
+	err := g.TakeEvenMoney(p1)
+	genericErrHelper(t, err)
+	err = g.EndInsurance()
+	genericErrHelper(t, err)
+	if g.State != RESOLVING_BETS {
+		t.Fatalf("dealer blackjack should end the round after insurance. expected=%s got=%s", RESOLVING_BETS, g.State)
+	}
+	_, err = g.ResolveBets()
+	genericErrHelper(t, err)
+	// even money pays 1:1 even though the dealer also has blackjack
+	if p1.Wallet != 110 {
+		t.Errorf("wallet incorrect after taking even money. expected=%d got=%d", 110, p1.Wallet)
+	}
+}
+
+func TestInsuranceErrors(t *testing.T) {
+	suit := suit("spade")
+	g, p1 := dealGameHelper(t, GC, []Card{
+		{suit, 10}, // player cards
+		{suit, 9},
+		{suit, ACE}, // dealer cards
+		{suit, 7},
+	}, Bet{Main: 10})
+	err := g.PlaceInsurance(p1, 6)
+	if err == nil {
+		t.Errorf("expected error insuring for more than half the bet")
+	}
+	err = g.TakeEvenMoney(p1)
+	if err == nil {
+		t.Errorf("expected error taking even money without blackjack")
+	}
+	err = g.Hit(p1)
+	if err == nil {
+		t.Errorf("expected error hitting during insurance")
+	}
+	if g.AllInsuranceDecided() {
+		t.Fatalf("expected player to still be deciding on insurance")
+	}
+	// nobody decided, so closing the window declines for them
+	err = g.EndInsurance()
+	genericErrHelper(t, err)
+	if p1.Insurance != 0 || p1.Wallet != 90 {
+		t.Errorf("undecided player should decline insurance. insurance=%d wallet=%d", p1.Insurance, p1.Wallet)
+	}
+	if g.State != PLAYER_TURN {
+		t.Fatalf("incorrect state after insurance. expected=%s got=%s", PLAYER_TURN, g.State)
+	}
+	err = g.DeclineInsurance(p1)
+	if err == nil {
+		t.Errorf("expected error declining insurance after the window closed")
+	}
+}
+
+func TestDealerPeek(t *testing.T) {
+	suit := suit("spade")
+	tests := []struct {
+		name          string
+		dealer        []Card
+		noHoleCard    bool
+		expectedState GameState
+	}{
+		{"ten_up_blackjack", []Card{{suit, KING}, {suit, ACE}}, false, RESOLVING_BETS},
+		{"ten_up_no_blackjack", []Card{{suit, KING}, {suit, 9}}, false, PLAYER_TURN},
+		{"low_up_card", []Card{{suit, 6}, {suit, 5}}, false, PLAYER_TURN},
+		{"no_hole_card", []Card{{suit, KING}, {suit, ACE}}, true, PLAYER_TURN},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := GC
+			config.Rules.NoHoleCard = tt.noHoleCard
+			g := NewGame(config)
+			cards := append([]Card{{suit, 10}, {suit, 9}}, tt.dealer...)
+			g.Deck.Base().Cards = append(cards, g.Deck.Base().Cards...)
+			p1 := &Player{ID: uuid.New(), Wallet: 100}
+			genericErrHelper(t, g.AddPlayer(p1))
+			genericErrHelper(t, g.StartGame())
+			genericErrHelper(t, g.PlaceBet(p1, Bet{Main: 10}))
+			genericErrHelper(t, g.StartRound())
+			genericErrHelper(t, g.DealCards())
+			if g.State != tt.expectedState {
+				t.Fatalf("incorrect state after the deal. expected=%s got=%s", tt.expectedState, g.State)
+			}
+			if g.State == RESOLVING_BETS {
+				_, err := g.ResolveBets()
+				genericErrHelper(t, err)
+				if p1.Wallet != 90 {
+					t.Errorf("player should lose to a dealer blackjack. expected=%d got=%d", 90, p1.Wallet)
+				}
+			}
+		})
+	}
+}
+
+func TestNoHoleCardDealerBlackjack(t *testing.T) {
+	suit := suit("spade")
+	config := GC
+	config.Rules.NoHoleCard = true
+	g := NewGame(config)
+	g.Deck.Base().Cards = append(
+		[]Card{
+			{suit, 6}, // player cards
+			{suit, 5},
+			{suit, 10},  // dealer up card
+			{suit, 10},  // double down card (player)
+			{suit, ACE}, // dealer's second card
+		},
+		g.Deck.Base().Cards...,
+	)
+	p1 := &Player{ID: uuid.New(), Wallet: 100}
+	genericErrHelper(t, g.AddPlayer(p1))
+	genericErrHelper(t, g.StartGame())
+	genericErrHelper(t, g.PlaceBet(p1, Bet{Main: 10}))
+	genericErrHelper(t, g.StartRound())
+	genericErrHelper(t, g.DealCards())
+	if len(g.DealerHand.Cards) != 1 {
+		t.Fatalf("dealer should not get a hole card. expected=%d got=%d", 1, len(g.DealerHand.Cards))
+	}
+	genericErrHelper(t, g.DoubleDown(p1))
+	genericErrHelper(t, g.PlayDealer())
+	if g.DealerHand.GetState() != BLACKJACK {
+		t.Fatalf("dealer should have drawn blackjack. got=%s", g.DealerHand.GetState())
+	}
+	_, err := g.ResolveBets()
+	genericErrHelper(t, err)
+	// the whole doubled bet is lost to the dealer blackjack
+	if p1.Wallet != 80 {
+		t.Errorf("wallet incorrect after losing double down. expected=%d got=%d", 80, p1.Wallet)
+	}
+}
+
+func TestLateSurrender(t *testing.T) {
+	suit := suit("spade")
+	config := GC
+	config.Rules.Surrender = LATE_SURRENDER
+	g, p1 := dealGameHelper(t, config, []Card{
+		{suit, 10}, // player cards
+		{suit, 6},
+		{suit, 10}, // dealer cards
+		{suit, 8},
+	}, Bet{Main: 10})
+	if !g.CanSurrender(p1) {
+		t.Fatalf("expected player to be able to surrender")
+	}
+	genericErrHelper(t, g.Surrender(p1))
+	if g.State != DEALER_TURN {
+		t.Fatalf("surrender should end the player's turn. expected=%s got=%s", DEALER_TURN, g.State)
+	}
+	genericErrHelper(t, g.PlayDealer())
+	results, err := g.ResolveBets()
+	genericErrHelper(t, err)
+	if p1.Wallet != 95 {
+		t.Errorf("surrender should return half the bet. expected=%d got=%d", 95, p1.Wallet)
+	}
+	res := results[p1.ID][0]
+	if res.Outcome != store.Surrendered {
+		t.Errorf("round result outcome incorrect. expected=%d got=%d", store.Surrendered, res.Outcome)
+	}
+	if res.WalletDelta != -5 {
+		t.Errorf("round result wallet delta incorrect. expected=%d got=%d", -5, res.WalletDelta)
+	}
+}
+
+func TestEarlySurrender(t *testing.T) {
+	suit := suit("spade")
+	config := GC
+	config.Rules.Surrender = EARLY_SURRENDER
+	g, p1 := dealGameHelper(t, config, []Card{
+		{suit, 10}, // player cards
+		{suit, 6},
+		{suit, 10}, // dealer cards
+		{suit, ACE},
+	}, Bet{Main: 10})
+	if g.State != INSURANCE {
+		t.Fatalf("early surrender should open a decision window on a ten. expected=%s got=%s", INSURANCE, g.State)
+	}
+	err := g.PlaceInsurance(p1, 5)
+	if err == nil {
+		t.Errorf("expected error taking insurance when the dealer shows a ten")
+	}
+	genericErrHelper(t, g.Surrender(p1))
+	if !g.AllInsuranceDecided() {
+		t.Fatalf("surrendering should count as the player's decision")
+	}
+	genericErrHelper(t, g.EndInsurance())
+	if g.State != RESOLVING_BETS {
+		t.Fatalf("dealer blackjack should end the round. expected=%s got=%s", RESOLVING_BETS, g.State)
+	}
+	_, err = g.ResolveBets()
+	genericErrHelper(t, err)
+	// early surrender keeps half the bet even against a dealer blackjack
+	if p1.Wallet != 95 {
+		t.Errorf("early surrender should return half the bet. expected=%d got=%d", 95, p1.Wallet)
+	}
+}
+
+func TestSurrenderErrors(t *testing.T) {
+	suit := suit("spade")
+	cards := []Card{
+		{suit, 10}, // player cards
+		{suit, 2},
+		{suit, 10}, // dealer cards
+		{suit, 8},
+		{suit, 3}, // hit card (player)
+	}
+	config := GC
+	config.Rules.Surrender = NO_SURRENDER
+	g, p1 := dealGameHelper(t, config, cards, Bet{Main: 10})
+	if g.CanSurrender(p1) {
+		t.Errorf("expected surrender to be unavailable without a surrender rule")
+	}
+	err := g.Surrender(p1)
+	if err == nil {
+		t.Errorf("expected error surrendering at a table without surrender")
+	}
+
+	config.Rules.Surrender = LATE_SURRENDER
+	g, p1 = dealGameHelper(t, config, cards, Bet{Main: 10})
+	genericErrHelper(t, g.Hit(p1))
+	err = g.Surrender(p1)
+	if err == nil {
+		t.Errorf("expected error surrendering after hitting")
+	}
+	if g.CanSurrender(p1) {
+		t.Errorf("expected surrender to be unavailable after the first decision")
+	}
+}
+
+func TestBlackjackPayout(t *testing.T) {
+	suit := suit("spade")
+	tests := []struct {
+		name           string
+		payout         Payout
+		bet            int
+		expectedPayout int
+	}{
+		{"three_to_two", PAYOUT_3_2, 10, 25},
+		{"six_to_five", PAYOUT_6_5, 10, 22},
+		{"six_to_five_rounds_down", PAYOUT_6_5, 7, 15},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := GC
+			config.Rules.BlackjackPayout = tt.payout
+			g := NewGame(config)
+			g.DealerHand = &Hand{Cards: []Card{{suit, 10}, {suit, 8}}}
+			payout := g.calculatePayout(&Hand{Cards: []Card{{suit, ACE}, {suit, KING}}, Bet: tt.bet})
+			if payout != tt.expectedPayout {
+				t.Errorf("payout calculation incorrect. expected=%d got=%d", tt.expectedPayout, payout)
+			}
+		})
+	}
+}
+
+func TestDoubleDownRules(t *testing.T) {
+	suit := suit("spade")
+	tests := []struct {
+		name        string
+		rules       RuleSet
+		playerCards []Card
+		expectErr   bool
+	}{
+		{"any_two_cards", DefaultRules(), []Card{{suit, 10}, {suit, 2}}, false},
+		{"nine_to_eleven_allows_nine", RuleSet{DoubleRule: DOUBLE_9_TO_11}, []Card{{suit, 5}, {suit, 4}}, false},
+		{"nine_to_eleven_rejects_twelve", RuleSet{DoubleRule: DOUBLE_9_TO_11}, []Card{{suit, 10}, {suit, 2}}, true},
+		{"ten_to_eleven_rejects_nine", RuleSet{DoubleRule: DOUBLE_10_TO_11}, []Card{{suit, 5}, {suit, 4}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewGame(GameConfig{Rules: tt.rules})
+			g.Deck.Base().Cards = append(append(tt.playerCards, Card{suit, 10}, Card{suit, 7}), g.Deck.Base().Cards...)
+			p1 := &Player{ID: uuid.New(), Wallet: 100}
+			genericErrHelper(t, g.AddPlayer(p1))
+			genericErrHelper(t, g.StartGame())
+			genericErrHelper(t, g.PlaceBet(p1, Bet{Main: 10}))
+			genericErrHelper(t, g.StartRound())
+			genericErrHelper(t, g.DealCards())
+			err := g.DoubleDown(p1)
+			if tt.expectErr && err == nil {
+				t.Errorf("expected error doubling down on %d", p1.Hands[0].GetValue())
+			}
+			if !tt.expectErr && err != nil {
+				t.Errorf("unexpected error doubling down. err=%v", err)
+			}
+		})
+	}
+}
+
+func TestDoubleAfterSplit(t *testing.T) {
+	suit := suit("spade")
+	cards := []Card{
+		{suit, 8}, // player cards
+		{suit, 8},
+		{suit, 10}, // dealer cards
+		{suit, 7},
+		{suit, 3}, // split cards
+		{suit, 2},
+	}
+	for _, das := range []bool{true, false} {
+		config := GC
+		config.Rules.DoubleAfterSplit = das
+		g := NewGame(config)
+		g.Deck.Base().Cards = append(slices.Clone(cards), g.Deck.Base().Cards...)
+		p1 := &Player{ID: uuid.New(), Wallet: 100}
+		genericErrHelper(t, g.AddPlayer(p1))
+		genericErrHelper(t, g.StartGame())
+		genericErrHelper(t, g.PlaceBet(p1, Bet{Main: 10}))
+		genericErrHelper(t, g.StartRound())
+		genericErrHelper(t, g.DealCards())
+		genericErrHelper(t, g.Split(p1))
+		err := g.DoubleDown(p1)
+		if das && err != nil {
+			t.Errorf("unexpected error doubling after split. err=%v", err)
+		}
+		if !das && err == nil {
+			t.Errorf("expected error doubling after split when the table doesn't allow it")
+		}
+	}
+}
+
+func TestTableLimits(t *testing.T) {
+	config := GC
+	config.Rules.MinBet = 5
+	config.Rules.MaxBet = 50
+	g := NewGame(config)
+	p1 := &Player{ID: uuid.New(), Wallet: 100}
+	genericErrHelper(t, g.AddPlayer(p1))
+	genericErrHelper(t, g.StartGame())
+	err := g.PlaceBet(p1, Bet{Main: 4})
+	if err == nil {
+		t.Errorf("expected error betting under the table minimum")
+	}
+	err = g.PlaceBet(p1, Bet{Main: 51})
+	if err == nil {
+		t.Errorf("expected error betting over the table maximum")
+	}
+	genericErrHelper(t, g.PlaceBet(p1, Bet{Main: 50}))
+}
+
+func TestBetErrors(t *testing.T) {
+	tests := []struct {
+		name           string
+		bet            int
+		wallet         int
+		expectedReason BetErrorReason
+		message        string
+	}{
+		{"negative", -5, 100, BET_INVALID, "Bets must be at least 1"},
+		{"zero", 0, 100, BET_INVALID, "Bets must be at least 1"},
+		{"below_min", 5, 100, BET_BELOW_MIN, "Minimum bet at this table is 10"},
+		{"above_max", 60, 100, BET_ABOVE_MAX, "Maximum bet at this table is 50"},
+		{"not_increment", 12, 100, BET_INCREMENT, "Bets at this table must be in multiples of 5"},
+		{"over_wallet", 30, 25, BET_OVER_WALLET, "You can't bet 30 with 25 in your wallet"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := GC
+			config.Rules.MinBet = 10
+			config.Rules.MaxBet = 50
+			config.Rules.BetIncrement = 5
+			g := NewGame(config)
+			p1 := &Player{ID: uuid.New(), Wallet: tt.wallet}
+			genericErrHelper(t, g.AddPlayer(p1))
+			genericErrHelper(t, g.StartGame())
+			err := g.PlaceBet(p1, Bet{Main: tt.bet})
+			var betErr *BetError
+			if !errors.As(err, &betErr) {
+				t.Fatalf("expected a BetError. got=%#v", err)
+			}
+			if betErr.Reason != tt.expectedReason {
+				t.Errorf("bet error reason incorrect. expected=%s got=%s", tt.expectedReason, betErr.Reason)
+			}
+			if err.Error() != tt.message {
+				t.Errorf("bet error message incorrect. expected=%q got=%q", tt.message, err.Error())
+			}
+			if p1.Wallet != tt.wallet {
+				t.Errorf("rejected bet should not touch the wallet. expected=%d got=%d", tt.wallet, p1.Wallet)
+			}
+		})
+	}
+}
+
+func TestReshuffleDiscards(t *testing.T) {
+	suit := suit("spade")
+	deck := &Deck{
+		Cards:     []Card{},
+		UsedCards: []Card{{suit, 2}, {suit, 3}, {suit, KING}, {suit, 4}},
+	}
+	deck.ReshuffleDiscards([]Card{{suit, KING}})
+	if len(deck.Cards) != 3 {
+		t.Fatalf("discards not shuffled back into the shoe. expected=%d got=%d", 3, len(deck.Cards))
+	}
+	if slices.Contains(deck.Cards, Card{suit, KING}) {
+		t.Errorf("card still on the table was shuffled back into the shoe")
+	}
+	if len(deck.UsedCards) != 1 {
+		t.Errorf("cards in play should stay in the discard tray. expected=%d got=%d", 1, len(deck.UsedCards))
+	}
+}
+
+func TestReshuffleBetweenRounds(t *testing.T) {
+	suit := suit("spade")
+	config := GC
+	config.BurnCard = true
+	g := NewGame(config)
+	if len(g.Deck.Base().Cards) != 311 {
+		t.Fatalf("expected a card to be burned off the new shoe. expected=%d got=%d", 311, len(g.Deck.Base().Cards))
+	}
+	g.Deck.Base().Cards = append([]Card{{suit, 10}, {suit, 9}, {suit, 10}, {suit, 7}}, g.Deck.Base().Cards...)
+	p1 := &Player{ID: uuid.New(), Wallet: 100}
+	genericErrHelper(t, g.AddPlayer(p1))
+	genericErrHelper(t, g.StartGame())
+	genericErrHelper(t, g.PlaceBet(p1, Bet{Main: 10}))
+	genericErrHelper(t, g.StartRound())
+	genericErrHelper(t, g.DealCards())
+	genericErrHelper(t, g.Stay(p1))
+	genericErrHelper(t, g.PlayDealer())
+	// pretend the cut card came out during the round
+	g.Deck.Base().Threshold = len(g.Deck.Base().Cards) + 1
+	_, err := g.ResolveBets()
+	genericErrHelper(t, err)
+	if g.ShuffleCount != 1 {
+		t.Fatalf("shoe not shuffled after the cut card. expected=%d got=%d", 1, g.ShuffleCount)
+	}
+	// The shuffle rebuilds a full shoe, so the stacked cards are gone
+	if len(g.Deck.Base().Cards) != 311 || len(g.Deck.Base().UsedCards) != 1 {
+		t.Errorf("shoe incorrect after shuffle and burn. cards=%d used=%d", len(g.Deck.Base().Cards), len(g.Deck.Base().UsedCards))
+	}
+}
+
+func TestShoeRunsOutMidRound(t *testing.T) {
+	g := NewGame(GC)
+	g.Deck.Base().Cards = []Card{
+		{"spade", 10}, // player cards
+		{"spade", 2},
+		{"spade", 10}, // dealer cards
+		{"spade", 7},
+	}
+	g.Deck.Base().UsedCards = []Card{{"heart", 2}, {"heart", 3}, {"heart", 4}, {"heart", 5}}
+	p1 := &Player{ID: uuid.New(), Wallet: 100}
+	genericErrHelper(t, g.AddPlayer(p1))
+	genericErrHelper(t, g.StartGame())
+	genericErrHelper(t, g.PlaceBet(p1, Bet{Main: 10}))
+	genericErrHelper(t, g.StartRound())
+	genericErrHelper(t, g.DealCards())
+	genericErrHelper(t, g.Hit(p1))
+	if g.ShuffleCount != 1 {
+		t.Fatalf("expected the discards to be reshuffled. expected=%d got=%d", 1, g.ShuffleCount)
+	}
+	if len(p1.Hands[0].Cards) != 3 || p1.Hands[0].Cards[2].Suit != "heart" {
+		t.Errorf("hit card should come from the reshuffled discards. got=%#v", p1.Hands[0].Cards)
+	}
+	if len(g.Deck.Base().Cards)+len(g.Deck.Base().UsedCards) != 8 {
+		t.Errorf("cards lost or duplicated in reshuffle. expected=%d got=%d", 8, len(g.Deck.Base().Cards)+len(g.Deck.Base().UsedCards))
+	}
+}
+
+func TestVerifyShoe(t *testing.T) {
+	config := GC
+	config.BurnCard = true
+	config.Entropy = rand.NewChaCha8(Seed{7})
+	g := NewGame(config)
+	p1 := &Player{ID: uuid.New(), Wallet: 100}
+	genericErrHelper(t, g.AddPlayer(p1))
+	genericErrHelper(t, g.StartGame())
+	genericErrHelper(t, g.PlaceBet(p1, Bet{Main: 10}))
+	genericErrHelper(t, g.StartRound())
+	genericErrHelper(t, g.DealCards())
+	if g.State == INSURANCE {
+		genericErrHelper(t, g.EndInsurance())
+	}
+	if g.State == PLAYER_TURN {
+		genericErrHelper(t, g.Stay(p1))
+	}
+	if g.State == DEALER_TURN {
+		genericErrHelper(t, g.PlayDealer())
+	}
+
+	dealt, ok := g.RoundCards()
+	if !ok {
+		t.Fatalf("Expected the round's cards once bets are being resolved. state=%s", g.State)
+	}
+	if dealt.Position != 1 {
+		t.Errorf("Round should start after the burn card. expected=%d got=%d", 1, dealt.Position)
+	}
+	history := ShoeHistory{Decks: 6, Commitment: g.Deck.Base().Seed.Commitment(), Rounds: []DealtCards{dealt}}
+	genericErrHelper(t, VerifyShoe(g.Deck.Base().Seed, history))
+
+	if err := VerifyShoe(Seed{8}, history); err == nil {
+		t.Errorf("Expected a seed that doesn't match the commitment to fail")
+	}
+	history.Commitment = ""
+	if err := VerifyShoe(Seed{8}, history); err == nil {
+		t.Errorf("Expected the wrong seed to fail against the dealt cards")
+	}
+	history.Rounds[0].Cards[0], history.Rounds[0].Cards[1] = history.Rounds[0].Cards[1], history.Rounds[0].Cards[0]
+	if history.Rounds[0].Cards[0] != history.Rounds[0].Cards[1] {
+		if err := VerifyShoe(g.Deck.Base().Seed, history); err == nil {
+			t.Errorf("Expected cards dealt out of order to fail")
+		}
+	}
+}
+
+func TestRoundCardsAfterDiscardReshuffle(t *testing.T) {
+	g := NewGame(GC)
+	g.State = RESOLVING_BETS
+	if _, ok := g.RoundCards(); !ok {
+		t.Fatalf("Expected round cards from an untouched shoe")
+	}
+	g.Deck.ReshuffleDiscards(nil)
+	if _, ok := g.RoundCards(); ok {
+		t.Errorf("Round cards can't be traced back to the seed once the discards are shuffled in")
+	}
+	g.Deck.Shuffle()
+	if _, ok := g.RoundCards(); !ok {
+		t.Errorf("A fresh shoe should be traceable again")
+	}
+}
+
+func TestContinuousShuffleKeepsShoeFull(t *testing.T) {
+	config := GC
+	config.DeckCount = 2
+	config.Rules.ContinuousShuffle = true
+	g := NewGame(config)
+	if _, ok := g.Deck.(*CSM); !ok {
+		t.Fatalf("Expected a continuous shuffling machine. got=%T", g.Deck)
+	}
+	p1 := &Player{ID: uuid.New(), Wallet: 1000}
+	genericErrHelper(t, g.AddPlayer(p1))
+	genericErrHelper(t, g.StartGame())
+
+	seed := g.Deck.Base().Seed
+	for round := range 20 {
+		order := slices.Clone(g.Deck.Base().Cards)
+		genericErrHelper(t, g.PlaceBet(p1, Bet{Main: 10}))
+		genericErrHelper(t, g.StartRound())
+		genericErrHelper(t, g.DealCards())
+		if g.State == INSURANCE {
+			genericErrHelper(t, g.EndInsurance())
+		}
+		if g.State == PLAYER_TURN {
+			genericErrHelper(t, g.Stay(p1))
+		}
+		if g.State == DEALER_TURN {
+			genericErrHelper(t, g.PlayDealer())
+		}
+		_, err := g.ResolveBets()
+		genericErrHelper(t, err)
+
+		deck := g.Deck.Base()
+		if len(deck.Cards) != 104 || len(deck.UsedCards) != 0 {
+			t.Fatalf("round %d: every card should be back in the shoe. cards=%d used=%d", round, len(deck.Cards), len(deck.UsedCards))
+		}
+		if slices.Equal(deck.Cards, order) {
+			t.Fatalf("round %d: the shoe should be reshuffled after every round", round)
+		}
+		if deck.Seed != seed {
+			t.Fatalf("round %d: the machine should keep its seed. expected=%s got=%s", round, seed, deck.Seed)
+		}
+	}
+	if g.ShuffleCount != 20 {
+		t.Errorf("Expected a shuffle after every round. expected=%d got=%d", 20, g.ShuffleCount)
+	}
+}
+
+func TestClassicShoeBuildsPenetration(t *testing.T) {
+	g := NewGame(GC)
+	if _, ok := g.Deck.(*Deck); !ok {
+		t.Fatalf("Expected a classic shoe. got=%T", g.Deck)
+	}
+	p1 := &Player{ID: uuid.New(), Wallet: 1000}
+	genericErrHelper(t, g.AddPlayer(p1))
+	genericErrHelper(t, g.StartGame())
+	genericErrHelper(t, g.PlaceBet(p1, Bet{Main: 10}))
+	genericErrHelper(t, g.StartRound())
+	genericErrHelper(t, g.DealCards())
+	if g.State == INSURANCE {
+		genericErrHelper(t, g.EndInsurance())
+	}
+	if g.State == PLAYER_TURN {
+		genericErrHelper(t, g.Stay(p1))
+	}
+	if g.State == DEALER_TURN {
+		genericErrHelper(t, g.PlayDealer())
+	}
+	_, err := g.ResolveBets()
+	genericErrHelper(t, err)
+	if len(g.Deck.Base().Cards) >= 312 {
+		t.Errorf("A classic shoe should not get the round's cards back. cards=%d", len(g.Deck.Base().Cards))
+	}
+}
+
+func TestEventsRebuildRound(t *testing.T) {
+	suit := suit("spade")
+	g := NewGame(GC)
+	g.Deck.Base().Cards = append(
+		[]Card{
+			{suit, 8}, // player cards
+			{suit, 8},
+			{suit, 10}, // dealer cards
+			{suit, 7},
+			{suit, 3},  // first split hand
+			{suit, 10}, // second split hand
+			{suit, 10}, // hit on first split hand
+		},
+		g.Deck.Base().Cards...,
+	)
+	p1 := &Player{ID: uuid.New(), Name: "p1", Wallet: 100}
+	genericErrHelper(t, g.AddPlayer(p1))
+	genericErrHelper(t, g.StartGame())
+	genericErrHelper(t, g.PlaceBet(p1, Bet{Main: 10}))
+	genericErrHelper(t, g.StartRound())
+	genericErrHelper(t, g.DealCards())
+	genericErrHelper(t, g.Split(p1))
+	genericErrHelper(t, g.Hit(p1))
+	genericErrHelper(t, g.Stay(p1))
+	genericErrHelper(t, g.PlayDealer())
+	dealer := slices.Clone(g.DealerHand.Cards)
+	hands := [][]Card{slices.Clone(p1.Hands[0].Cards), slices.Clone(p1.Hands[1].Cards)}
+	_, err := g.ResolveBets()
+	genericErrHelper(t, err)
+
+	events := g.DrainEvents()
+	if len(g.DrainEvents()) != 0 {
+		t.Fatalf("Draining should empty the event queue")
+	}
+	types := []EventType{}
+	for i, e := range events {
+		if e.Seq != events[0].Seq+i {
+			t.Fatalf("Events out of order. expected seq=%d got=%d", events[0].Seq+i, e.Seq)
+		}
+		if e.Type != e.Data.EventType() {
+			t.Errorf("Event type does not match its data. type=%s data=%T", e.Type, e.Data)
+		}
+		if e.Type != EVENT_SHOE_SHUFFLED && e.Round != 1 {
+			t.Errorf("Event %s should belong to round 1. got=%d", e.Type, e.Round)
+		}
+		types = append(types, e.Type)
+	}
+	expected := []EventType{
+		EVENT_SHOE_SHUFFLED,
+		EVENT_BET_PLACED,
+		EVENT_ROUND_STARTED,
+		EVENT_CARD_DEALT, EVENT_CARD_DEALT, EVENT_CARD_DEALT, EVENT_CARD_DEALT,
+		EVENT_DEALER_PEEKED,
+		EVENT_TURN_CHANGED,
+		EVENT_PLAYER_ACTED, EVENT_CARD_DEALT, EVENT_CARD_DEALT, // split
+		EVENT_PLAYER_ACTED, EVENT_CARD_DEALT, // hit to 21 ends the first hand
+		EVENT_TURN_CHANGED,
+		EVENT_PLAYER_ACTED, // stand
+		EVENT_TURN_CHANGED,
+		EVENT_HOLE_CARD_REVEALED,
+		EVENT_HAND_RESOLVED, EVENT_HAND_RESOLVED,
+		EVENT_ROUND_ENDED,
+	}
+	if !slices.Equal(types, expected) {
+		t.Fatalf("Unexpected events.\nexpected=%v\ngot=%v", expected, types)
+	}
+
+	// rebuild both hands and the dealer from the events alone
+	rebuilt := map[int][]Card{}
+	rebuiltDealer := []Card{}
+	for _, e := range events {
+		switch data := e.Data.(type) {
+		case CardDealt:
+			if data.Dealer {
+				if data.FaceDown != (len(rebuiltDealer) == 1) {
+					t.Errorf("Only the hole card should be dealt face down. got=%#v", data)
+				}
+				rebuiltDealer = append(rebuiltDealer, data.Card)
+				continue
+			}
+			rebuilt[data.Hand] = append(rebuilt[data.Hand], data.Card)
+		case PlayerActed:
+			if data.Action == ACTION_SPLIT {
+				rebuilt[data.Hand+1] = []Card{rebuilt[data.Hand][1]}
+				rebuilt[data.Hand] = rebuilt[data.Hand][:1]
+			}
+		case HoleCardRevealed:
+			if data.Card != dealer[1] {
+				t.Errorf("Wrong hole card revealed. expected=%v got=%v", dealer[1], data.Card)
+			}
+		case HandResolved:
+			if data.PlayerID != p1.ID || data.Bet != 10 {
+				t.Errorf("Hand resolved for the wrong bet. got=%#v", data)
+			}
+		}
+	}
+	if !slices.Equal(rebuiltDealer, dealer) {
+		t.Errorf("Dealer hand incorrect. expected=%v got=%v", dealer, rebuiltDealer)
+	}
+	for i, h := range hands {
+		if !slices.Equal(rebuilt[i], h) {
+			t.Errorf("Hand %d incorrect. expected=%v got=%v", i, h, rebuilt[i])
+		}
+	}
+	if g.Round != 2 {
+		t.Errorf("Round should move on once bets are resolved. expected=%d got=%d", 2, g.Round)
+	}
+}
+
+func TestInsuranceEvents(t *testing.T) {
+	suit := suit("spade")
+	g := NewGame(GC)
+	g.Deck.Base().Cards = append([]Card{{suit, 10}, {suit, 9}, {suit, ACE}, {suit, 10}}, g.Deck.Base().Cards...)
+	p1 := &Player{ID: uuid.New(), Wallet: 100}
+	genericErrHelper(t, g.AddPlayer(p1))
+	genericErrHelper(t, g.StartGame())
+	genericErrHelper(t, g.PlaceBet(p1, Bet{Main: 10}))
+	genericErrHelper(t, g.StartRound())
+	genericErrHelper(t, g.DealCards())
+	g.DrainEvents()
+	genericErrHelper(t, g.EndInsurance())
+
+	events := g.DrainEvents()
+	if len(events) != 3 {
+		t.Fatalf("Expected an automatic decline, the peek and the hole card. got=%v", events)
+	}
+	acted, ok := events[0].Data.(PlayerActed)
+	if !ok || acted.Action != ACTION_DECLINE_INSURANCE || !acted.Automatic {
+		t.Errorf("Expected an automatic insurance decline. got=%#v", events[0].Data)
+	}
+	if peek, ok := events[1].Data.(DealerPeeked); !ok || !peek.Blackjack {
+		t.Errorf("Expected the dealer to peek a blackjack. got=%#v", events[1].Data)
+	}
+	if _, ok := events[2].Data.(HoleCardRevealed); !ok {
+		t.Errorf("Expected the hole card to be revealed. got=%#v", events[2].Data)
+	}
+}
+
+func TestReplayRound(t *testing.T) {
+	suit := suit("spade")
+	g := NewGame(GC)
+	g.Deck.Base().Cards = append(
+		[]Card{
+			{suit, 8}, // player cards
+			{suit, 8},
+			{suit, 10}, // dealer cards
+			{suit, 7},
+			{suit, 3},  // first split hand
+			{suit, 10}, // second split hand
+			{suit, 10}, // hit on first split hand
+		},
+		g.Deck.Base().Cards...,
+	)
+	p1 := &Player{ID: uuid.New(), Name: "p1", Wallet: 100}
+	genericErrHelper(t, g.AddPlayer(p1))
+	genericErrHelper(t, g.StartGame())
+	genericErrHelper(t, g.PlaceBet(p1, Bet{Main: 10}))
+	genericErrHelper(t, g.StartRound())
+	genericErrHelper(t, g.DealCards())
+	genericErrHelper(t, g.Split(p1))
+	genericErrHelper(t, g.Hit(p1))
+	genericErrHelper(t, g.Stay(p1))
+	genericErrHelper(t, g.PlayDealer())
+	dealer := slices.Clone(g.DealerHand.Cards)
+	hands := [][]Card{slices.Clone(p1.Hands[0].Cards), slices.Clone(p1.Hands[1].Cards)}
+	_, err := g.ResolveBets()
+	genericErrHelper(t, err)
+
+	// stored rounds come back out of json
+	data, err := json.Marshal(g.DrainEvents())
+	genericErrHelper(t, err)
+	events := []Event{}
+	genericErrHelper(t, json.Unmarshal(data, &events))
+	if _, ok := events[0].Data.(ShoeShuffled); !ok {
+		t.Fatalf("Expected event data to be decoded to its type. got=%T", events[0].Data)
+	}
+
+	steps, err := ReplayRound(events)
+	genericErrHelper(t, err)
+	if len(steps) != len(events)-2 {
+		t.Errorf("Expected a step for everything but the shuffle and the bet. expected=%d got=%d", len(events)-2, len(steps))
+	}
+	if steps[0].Description != "Round 1 started with p1" {
+		t.Errorf("Unexpected first step. got=%q", steps[0].Description)
+	}
+	for _, step := range steps {
+		if step.Event.Type == EVENT_CARD_DEALT && step.State != DEALING && step.State != PLAYER_TURN {
+			t.Errorf("Cards should be dealt before the dealer's turn. got=%s", step.State)
+		}
+	}
+	final := steps[len(steps)-1]
+	if final.State != RESOLVING_BETS {
+		t.Errorf("Expected the round to end resolving bets. got=%s", final.State)
+	}
+	if !slices.Equal(final.DealerHand.Cards, dealer) {
+		t.Errorf("Dealer hand incorrect. expected=%v got=%v", dealer, final.DealerHand.Cards)
+	}
+	player := final.Players[0]
+	if player.Bet != 20 || len(player.Hands) != 2 {
+		t.Fatalf("Expected two split hands for 20. got bet=%d hands=%d", player.Bet, len(player.Hands))
+	}
+	for i, h := range hands {
+		if !slices.Equal(player.Hands[i].Cards, h) {
+			t.Errorf("Hand %d incorrect. expected=%v got=%v", i, h, player.Hands[i].Cards)
+		}
+	}
+	if len(steps[3].Players[0].Hands[0].Cards) != 2 {
+		t.Errorf("Earlier steps should not change as the round goes on. got=%v", steps[3].Players[0].Hands[0].Cards)
+	}
+}
+
+func TestBasicStrategy(t *testing.T) {
+	suit := suit("spade")
+	hand := func(ranks ...cardRank) *Hand {
+		h := &Hand{}
+		for _, r := range ranks {
+			h.Cards = append(h.Cards, Card{suit, r})
+		}
+		return h
+	}
+	s17 := DefaultRules()
+	h17 := DefaultRules()
+	h17.StandOnSoft17 = false
+	noDAS := DefaultRules()
+	noDAS.DoubleAfterSplit = false
+	late := DefaultRules()
+	late.Surrender = LATE_SURRENDER
+	early := DefaultRules()
+	early.Surrender = EARLY_SURRENDER
+	enhc := DefaultRules()
+	enhc.NoHoleCard = true
+	all := Allowed{Double: true, Split: true, Surrender: true}
+
+	tests := []struct {
+		name     string
+		hand     *Hand
+		up       cardRank
+		rules    RuleSet
+		allowed  Allowed
+		expected PlayerAction
+	}{
+		{"hard 16 vs ten surrenders", hand(10, 6), 10, late, all, ACTION_SURRENDER},
+		{"hard 16 vs ten hits without surrender", hand(10, 6), 10, s17, all, ACTION_HIT},
+		{"eights split instead of surrendering", hand(8, 8), 10, late, all, ACTION_SPLIT},
+		{"early surrender gives up 7 vs ace", hand(5, 2), ACE, early, all, ACTION_SURRENDER},
+		{"late surrender hits 7 vs ace", hand(5, 2), ACE, late, all, ACTION_HIT},
+		{"soft 18 vs 3 doubles", hand(ACE, 7), 3, s17, all, ACTION_DOUBLE},
+		{"soft 18 vs 3 stands when it can't double", hand(ACE, 7), 3, s17, Allowed{}, ACTION_STAND},
+		{"soft 18 vs 9 hits", hand(ACE, 7), 9, s17, all, ACTION_HIT},
+		{"11 vs ace hits when the dealer stands on soft 17", hand(6, 5), ACE, s17, all, ACTION_HIT},
+		{"11 vs ace doubles when the dealer hits soft 17", hand(6, 5), ACE, h17, all, ACTION_DOUBLE},
+		{"12 vs 3 hits", hand(10, 2), 3, s17, all, ACTION_HIT},
+		{"12 vs 4 stands", hand(10, 2), 4, s17, all, ACTION_STAND},
+		{"tens stand", hand(10, 10), 6, s17, all, ACTION_STAND},
+		{"nines stand vs 7", hand(9, 9), 7, s17, all, ACTION_STAND},
+		{"twos split vs 2 with double after split", hand(2, 2), 2, s17, all, ACTION_SPLIT},
+		{"twos hit vs 2 without double after split", hand(2, 2), 2, noDAS, all, ACTION_HIT},
+		{"10 vs ten hits", hand(6, 4), 10, s17, all, ACTION_HIT},
+		{"no hole card 11 vs ten hits", hand(6, 5), 10, enhc, all, ACTION_HIT},
+		{"no hole card eights vs ten hit", hand(8, 8), 10, enhc, all, ACTION_HIT},
+		{"no hole card aces still split vs ten", hand(ACE, ACE), 10, enhc, all, ACTION_SPLIT},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := BasicStrategy(tt.hand, Card{suit, tt.up}, tt.rules, tt.allowed)
+			if got != tt.expected {
+				t.Errorf("expected=%s got=%s", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestStrategyAdvice(t *testing.T) {
+	suit := suit("spade")
+	config := GC
+	config.Rules.Surrender = LATE_SURRENDER
+	g, p1 := dealGameHelper(t, config, []Card{
+		{suit, 10}, // player cards
+		{suit, 6},
+		{suit, 10}, // dealer cards
+		{suit, 8},
+		{suit, 2}, // player hit
+	}, Bet{Main: 10})
+	advice, err := g.Advise(p1)
+	genericErrHelper(t, err)
+	if advice != ACTION_SURRENDER {
+		t.Errorf("advice incorrect. expected=%s got=%s", ACTION_SURRENDER, advice)
+	}
+	g.DrainEvents()
+	genericErrHelper(t, g.Hit(p1))
+	acted, ok := g.DrainEvents()[0].Data.(PlayerActed)
+	if !ok || acted.Action != ACTION_HIT || acted.Advice != ACTION_SURRENDER {
+		t.Errorf("Expected a hit against the advice to surrender. got=%#v", acted)
+	}
+
+	// hard 18 can't surrender any more
+	advice, err = g.Advise(p1)
+	genericErrHelper(t, err)
+	if advice != ACTION_STAND {
+		t.Errorf("advice incorrect. expected=%s got=%s", ACTION_STAND, advice)
+	}
+	genericErrHelper(t, g.Stay(p1))
+	_, err = g.Advise(p1)
+	if err == nil {
+		t.Errorf("Expected an error asking for advice after the player's turn")
+	}
+}
+
+func TestInsuranceAdvice(t *testing.T) {
+	suit := suit("spade")
+	g, p1 := dealGameHelper(t, GC, []Card{
+		{suit, 10}, // player cards
+		{suit, 9},
+		{suit, ACE}, // dealer cards
+		{suit, 7},
+	}, Bet{Main: 10})
+	advice, err := g.Advise(p1)
+	genericErrHelper(t, err)
+	if advice != ACTION_DECLINE_INSURANCE {
+		t.Errorf("advice incorrect. expected=%s got=%s", ACTION_DECLINE_INSURANCE, advice)
+	}
+	g.DrainEvents()
+	genericErrHelper(t, g.PlaceInsurance(p1, 5))
+	acted, ok := g.DrainEvents()[0].Data.(PlayerActed)
+	if !ok || acted.Action != ACTION_INSURANCE || acted.Advice != ACTION_DECLINE_INSURANCE {
+		t.Errorf("Expected insurance against the advice to decline it. got=%#v", acted)
+	}
+}
+
+func TestNeverBust(t *testing.T) {
+	suit := suit("spade")
+	config := GC
+	config.Rules.Surrender = NO_SURRENDER
+	g, p1 := dealGameHelper(t, config, []Card{
+		{suit, ACE}, // player cards
+		{suit, 5},
+		{suit, 10}, // dealer cards
+		{suit, 8},
+		{suit, 6},  // player hit to hard 12
+		{suit, 10}, // would bust
+	}, Bet{Main: 10})
+	s, err := ParseStrategy(STRATEGY_NEVER_BUST)
+	genericErrHelper(t, err)
+	tests := []PlayerAction{ACTION_HIT, ACTION_STAND}
+	for i, expected := range tests {
+		action, err := s.Decide(g, p1)
+		genericErrHelper(t, err)
+		if action != expected {
+			t.Errorf("tests[%d] action incorrect. expected=%s got=%s", i, expected, action)
+		}
+		genericErrHelper(t, g.Act(p1, action))
+	}
+	if g.State != DEALER_TURN || p1.Hands[0].GetValue() != 12 {
+		t.Errorf("Expected to stand on 12. state=%s value=%d", g.State, p1.Hands[0].GetValue())
+	}
+	if _, err = s.Decide(g, p1); err == nil {
+		t.Errorf("Expected an error deciding after the player's turn")
+	}
+}
+
+func TestStrategiesPlayLegally(t *testing.T) {
+	for _, name := range []string{STRATEGY_BASIC, STRATEGY_NEVER_BUST, STRATEGY_RANDOM} {
+		config := GC
+		config.Rules.Surrender = EARLY_SURRENDER
+		g := NewGame(config)
+		p1 := &Player{ID: uuid.New()}
+		genericErrHelper(t, g.AddPlayer(p1))
+		genericErrHelper(t, g.StartGame())
+		s, err := ParseStrategy(name)
+		genericErrHelper(t, err)
+		for range 500 {
+			p1.Wallet = 1000
+			genericErrHelper(t, g.PlaceBet(p1, Bet{Main: 10}))
+			genericErrHelper(t, g.StartRound())
+			genericErrHelper(t, g.DealCards())
+			for g.State == INSURANCE || g.State == PLAYER_TURN {
+				action, err := s.Decide(g, p1)
+				if err != nil {
+					t.Fatalf("%s could not decide in %s: %v", name, g.State, err)
+				}
+				if err := g.Act(p1, action); err != nil {
+					t.Fatalf("%s made an illegal %s: %v", name, action, err)
+				}
+				if g.State == INSURANCE && g.AllInsuranceDecided() {
+					genericErrHelper(t, g.EndInsurance())
+				}
+			}
+			if g.State == DEALER_TURN {
+				genericErrHelper(t, g.PlayDealer())
+			}
+			_, err := g.ResolveBets()
+			genericErrHelper(t, err)
+		}
+	}
+	if _, err := ParseStrategy("martingale"); err == nil {
+		t.Errorf("Expected an error for an unknown strategy")
+	}
+}
+
+func TestVariantDecks(t *testing.T) {
+	tests := []struct {
+		variant  Variant
+		expected int
+	}{
+		{Classic{}, 52},
+		{Spanish21{}, 48},
+		{Pontoon{}, 52},
+		{DoubleExposure{}, 52},
+	}
+	for _, tt := range tests {
+		deck := NewVariantDeck(2, 0, nil, tt.variant)
+		if len(deck.Cards) != tt.expected*2 {
+			t.Errorf("%s shoe has the wrong number of cards. expected=%d got=%d", tt.variant.Name(), tt.expected*2, len(deck.Cards))
+		}
+		for _, c := range deck.Cards {
+			if tt.variant.Name() == VARIANT_SPANISH_21 && c.Rank == 10 {
+				t.Fatalf("Spanish 21 shoe should not have any tens. got=%v", c)
+			}
+		}
+	}
+	if _, err := ParseVariant("baccarat"); err == nil {
+		t.Errorf("Expected an error for an unknown variant")
+	}
+}
+
+func TestVariantPayouts(t *testing.T) {
+	spade := suit("spade")
+	heart := suit("heart")
+	tests := []struct {
+		name           string
+		variant        Variant
+		PlayerHand     *Hand
+		DealerHand     *Hand
+		ExpectedPayout int
+	}{
+		{"spanish21 blackjack beats dealer blackjack", Spanish21{}, &Hand{Cards: []Card{{spade, ACE}, {spade, KING}}}, &Hand{Cards: []Card{{heart, ACE}, {heart, KING}}}, 25},
+		{"spanish21 dealer blackjack", Spanish21{}, &Hand{Cards: []Card{{spade, KING}, {spade, 9}}}, &Hand{Cards: []Card{{heart, ACE}, {heart, KING}}}, 0},
+		{"spanish21 21 beats dealer 21", Spanish21{}, &Hand{Cards: []Card{{spade, KING}, {spade, 9}, {spade, 2}}}, &Hand{Cards: []Card{{heart, KING}, {heart, 5}, {heart, 6}}}, 20},
+		{"spanish21 five card 21", Spanish21{}, &Hand{Cards: []Card{{spade, 2}, {spade, 3}, {heart, 4}, {heart, 5}, {spade, 7}}}, &Hand{Cards: []Card{{heart, KING}, {heart, 8}}}, 25},
+		{"spanish21 six card 21", Spanish21{}, &Hand{Cards: []Card{{spade, 2}, {spade, 3}, {heart, 4}, {heart, 5}, {spade, 2}, {spade, 5}}}, &Hand{Cards: []Card{{heart, KING}, {heart, 8}}}, 30},
+		{"spanish21 seven card 21", Spanish21{}, &Hand{Cards: []Card{{spade, 2}, {spade, 3}, {heart, 4}, {heart, 2}, {spade, 2}, {spade, 5}, {heart, 3}}}, &Hand{Cards: []Card{{heart, KING}, {heart, 8}}}, 40},
+		{"spanish21 mixed 678", Spanish21{}, &Hand{Cards: []Card{{spade, 6}, {heart, 7}, {spade, 8}}}, &Hand{Cards: []Card{{heart, KING}, {heart, 8}}}, 25},
+		{"spanish21 suited 777", Spanish21{}, &Hand{Cards: []Card{{heart, 7}, {heart, 7}, {heart, 7}}}, &Hand{Cards: []Card{{heart, KING}, {heart, 8}}}, 30},
+		{"spanish21 spade 678", Spanish21{}, &Hand{Cards: []Card{{spade, 8}, {spade, 7}, {spade, 6}}}, &Hand{Cards: []Card{{heart, KING}, {heart, 8}}}, 40},
+		{"spanish21 doubled 678", Spanish21{}, &Hand{Cards: []Card{{spade, 6}, {spade, 7}, {spade, 8}}, Doubled: true}, &Hand{Cards: []Card{{heart, KING}, {heart, 8}}}, 20},
+		{"spanish21 push", Spanish21{}, &Hand{Cards: []Card{{spade, KING}, {spade, 8}}}, &Hand{Cards: []Card{{heart, KING}, {heart, 8}}}, 10},
+		{"pontoon", Pontoon{}, &Hand{Cards: []Card{{spade, ACE}, {spade, KING}}}, &Hand{Cards: []Card{{heart, KING}, {heart, 8}}}, 30},
+		{"pontoon loses to banker pontoon", Pontoon{}, &Hand{Cards: []Card{{spade, ACE}, {spade, KING}}}, &Hand{Cards: []Card{{heart, ACE}, {heart, KING}}}, 0},
+		{"pontoon five card trick", Pontoon{}, &Hand{Cards: []Card{{spade, 2}, {spade, 3}, {heart, 4}, {heart, 2}, {spade, 5}}}, &Hand{Cards: []Card{{heart, KING}, {heart, 9}}}, 30},
+		{"pontoon banker wins ties", Pontoon{}, &Hand{Cards: []Card{{spade, KING}, {spade, 8}}}, &Hand{Cards: []Card{{heart, KING}, {heart, 8}}}, 0},
+		{"pontoon win", Pontoon{}, &Hand{Cards: []Card{{spade, KING}, {spade, 9}}}, &Hand{Cards: []Card{{heart, KING}, {heart, 8}}}, 20},
+		{"double exposure blackjack pays even money", DoubleExposure{}, &Hand{Cards: []Card{{spade, ACE}, {spade, KING}}}, &Hand{Cards: []Card{{heart, KING}, {heart, 8}}}, 20},
+		{"double exposure dealer wins ties", DoubleExposure{}, &Hand{Cards: []Card{{spade, KING}, {spade, 8}}}, &Hand{Cards: []Card{{heart, KING}, {heart, 8}}}, 0},
+		{"double exposure dealer blackjack", DoubleExposure{}, &Hand{Cards: []Card{{spade, ACE}, {spade, KING}}}, &Hand{Cards: []Card{{heart, ACE}, {heart, KING}}}, 0},
+		{"double exposure dealer busts", DoubleExposure{}, &Hand{Cards: []Card{{spade, KING}, {spade, 2}}}, &Hand{Cards: []Card{{heart, KING}, {heart, 8}, {heart, 5}}}, 20},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := GC
+			config.Variant = tt.variant
+			g := NewGame(config)
+			g.DealerHand = tt.DealerHand
+			tt.PlayerHand.Bet = 10
+			payout := g.calculatePayout(tt.PlayerHand)
+			if payout != tt.ExpectedPayout {
+				t.Errorf("payout calculation incorrect. expected=%d got=%d", tt.ExpectedPayout, payout)
+			}
+		})
+	}
+}
+
+func TestVariantDealerCards(t *testing.T) {
+	spade := suit("spade")
+	tests := []struct {
+		variant  Variant
+		faceDown int
+	}{
+		{Classic{}, 1},
+		{Pontoon{}, 2},
+		{DoubleExposure{}, 0},
+	}
+	for _, tt := range tests {
+		config := GC
+		config.Variant = tt.variant
+		g := NewGame(config)
+		// an ace up would offer insurance at a classic table
+		g.Deck.Base().Cards = append([]Card{{spade, 10}, {spade, 9}, {spade, ACE}, {spade, 6}}, g.Deck.Base().Cards...)
+		p1 := &Player{ID: uuid.New(), Wallet: 100}
+		genericErrHelper(t, g.AddPlayer(p1))
+		genericErrHelper(t, g.StartGame())
+		genericErrHelper(t, g.PlaceBet(p1, Bet{Main: 10}))
+		genericErrHelper(t, g.StartRound())
+		genericErrHelper(t, g.DealCards())
+
+		faceDown := 0
+		for _, e := range g.DrainEvents() {
+			if data, ok := e.Data.(CardDealt); ok && data.Dealer && data.FaceDown {
+				faceDown++
+			}
+		}
+		if faceDown != tt.faceDown {
+			t.Errorf("%s dealt the wrong number of dealer cards face down. expected=%d got=%d", tt.variant.Name(), tt.faceDown, faceDown)
+		}
+		insurance := tt.variant.UpCards() == 1
+		if (g.State == INSURANCE) != insurance {
+			t.Errorf("%s insurance offered incorrectly. expected=%t got=%s", tt.variant.Name(), insurance, g.State)
+		}
+	}
+}
+
+func TestVerifySpanish21Shoe(t *testing.T) {
+	config := GC
+	config.Variant = Spanish21{}
+	config.Entropy = rand.NewChaCha8(Seed{3})
+	g := NewGame(config)
+	history := ShoeHistory{Decks: 6, Variant: VARIANT_SPANISH_21, Rounds: []DealtCards{{Position: 0, Cards: g.Deck.Base().Cards[:10]}}}
+	genericErrHelper(t, VerifyShoe(g.Deck.Base().Seed, history))
+	history.Variant = ""
+	if err := VerifyShoe(g.Deck.Base().Seed, history); err == nil {
+		t.Errorf("Expected a Spanish 21 shoe not to verify as a classic shoe")
+	}
+}
+
+type testPool struct {
+	amount int
+}
+
+func (tp *testPool) Add(amount int) { tp.amount += amount }
+
+func (tp *testPool) Win(percent int) int {
+	payout := tp.amount * percent / 100
+	tp.amount -= payout
+	return payout
+}
+
+func jackpotConfig(pool *testPool) GameConfig {
+	config := GC
+	config.Jackpot = pool
+	config.Rules.JackpotBet = 5
+	return config
+}
+
+func TestPlaceJackpotBet(t *testing.T) {
+	pool := &testPool{amount: 1000}
+	g := NewGame(jackpotConfig(pool))
+	p1 := &Player{ID: uuid.New(), Wallet: 100}
+	genericErrHelper(t, g.AddPlayer(p1))
+	genericErrHelper(t, g.StartGame())
+	if err := g.PlaceJackpotBet(p1); err == nil {
+		t.Errorf("expected error placing the jackpot bet before the main bet")
+	}
+	genericErrHelper(t, g.PlaceBet(p1, Bet{Main: 10}))
+	genericErrHelper(t, g.PlaceJackpotBet(p1))
+	if err := g.PlaceJackpotBet(p1); err == nil {
+		t.Errorf("expected error placing the jackpot bet twice")
+	}
+	if p1.Wallet != 85 || pool.amount != 1005 {
+		t.Fatalf("jackpot bet not moved into the pool. wallet=%d pool=%d", p1.Wallet, pool.amount)
+	}
+}
+
+func TestJackpotSuited777(t *testing.T) {
+	pool := &testPool{amount: 1000}
+	spade := suit("spade")
+	g, p1 := dealGameHelper(t, jackpotConfig(pool), []Card{
+		{spade, 7}, // player cards
+		{spade, 7},
+		{suit("heart"), 9}, // dealer cards
+		{suit("heart"), 8},
+		{spade, 7}, // player hit
+	}, Bet{Main: 10})
+	genericErrHelper(t, g.Hit(p1))
+	if g.State != DEALER_TURN {
+		t.Fatalf("21 should end the player's turn. got=%s", g.State)
+	}
+	genericErrHelper(t, g.PlayDealer())
+	results, err := g.ResolveBets()
+	genericErrHelper(t, err)
+	// 21 beats 17 for 20 back, plus the whole pool
+	if p1.Wallet != 85+20+1005 || pool.amount != 0 {
+		t.Errorf("jackpot not paid. wallet=%d pool=%d", p1.Wallet, pool.amount)
+	}
+	res := results[p1.ID][0]
+	if res.Jackpot != 5 || res.WalletDelta != 10+1005-5 {
+		t.Errorf("round result does not report the jackpot. got=%+v", res)
+	}
+	if p1.Jackpot != 0 {
+		t.Errorf("jackpot bet should be cleared after the round")
+	}
+}
+
+func TestJackpotSuitedBlackjacks(t *testing.T) {
+	pool := &testPool{amount: 1000}
+	g, p1 := dealGameHelper(t, jackpotConfig(pool), []Card{
+		{suit("spade"), ACE}, // player cards
+		{suit("spade"), KING},
+		{suit("heart"), KING}, // dealer cards
+		{suit("heart"), ACE},
+	}, Bet{Main: 10})
+	if g.State != RESOLVING_BETS {
+		t.Fatalf("dealer blackjack should end the round. got=%s", g.State)
+	}
+	_, err := g.ResolveBets()
+	genericErrHelper(t, err)
+	// the blackjacks push and the jackpot pays 10% of the pool
+	if p1.Wallet != 85+10+100 || pool.amount != 905 {
+		t.Errorf("jackpot not paid. wallet=%d pool=%d", p1.Wallet, pool.amount)
+	}
+}
+
+func TestJackpotMiss(t *testing.T) {
+	pool := &testPool{amount: 1000}
+	g, p1 := dealGameHelper(t, jackpotConfig(pool), []Card{
+		{suit("spade"), 7}, // player cards
+		{suit("heart"), 7},
+		{suit("heart"), 9}, // dealer cards
+		{suit("heart"), 8},
+		{suit("club"), 7}, // player hit
+	}, Bet{Main: 10})
+	genericErrHelper(t, g.Hit(p1))
+	genericErrHelper(t, g.PlayDealer())
+	_, err := g.ResolveBets()
+	genericErrHelper(t, err)
+	if p1.Wallet != 85+20 || pool.amount != 1005 {
+		t.Errorf("jackpot bet should stay in the pool. wallet=%d pool=%d", p1.Wallet, pool.amount)
+	}
+
+	noPool := NewGame(GC)
+	p2 := &Player{ID: uuid.New(), Wallet: 100}
+	noPool.AddPlayer(p2)
+	noPool.StartGame()
+	noPool.PlaceBet(p2, Bet{Main: 10})
+	if err := noPool.PlaceJackpotBet(p2); err == nil {
+		t.Errorf("expected error placing a jackpot bet at a table without the jackpot")
+	}
+}
+
+func TestSideBetHands(t *testing.T) {
+	spade, heart, diamond, club := suit("spade"), suit("heart"), suit("diamond"), suit("club")
+	tests := []struct {
+		sb       SideBet
+		cards    []Card
+		expected SideHand
+	}{
+		{SIDE_BET_PERFECT_PAIRS, []Card{{spade, 8}, {spade, 8}}, PERFECT_PAIR},
+		{SIDE_BET_PERFECT_PAIRS, []Card{{heart, 8}, {diamond, 8}}, COLORED_PAIR},
+		{SIDE_BET_PERFECT_PAIRS, []Card{{heart, 8}, {club, 8}}, MIXED_PAIR},
+		{SIDE_BET_PERFECT_PAIRS, []Card{{heart, 8}, {heart, 9}}, SIDE_HAND_NONE},
+		{SIDE_BET_21_PLUS_3, []Card{{club, QUEEN}, {club, QUEEN}, {club, QUEEN}}, SUITED_TRIPS},
+		{SIDE_BET_21_PLUS_3, []Card{{club, 5}, {club, 3}, {club, 4}}, STRAIGHT_FLUSH},
+		{SIDE_BET_21_PLUS_3, []Card{{club, 5}, {heart, 5}, {spade, 5}}, THREE_OF_A_KIND},
+		{SIDE_BET_21_PLUS_3, []Card{{club, KING}, {heart, ACE}, {spade, QUEEN}}, STRAIGHT},
+		{SIDE_BET_21_PLUS_3, []Card{{club, ACE}, {heart, 2}, {spade, 3}}, STRAIGHT},
+		{SIDE_BET_21_PLUS_3, []Card{{club, KING}, {heart, ACE}, {spade, 2}}, SIDE_HAND_NONE},
+		{SIDE_BET_21_PLUS_3, []Card{{club, 2}, {club, 9}, {club, JACK}}, FLUSH},
+	}
+	gc := GC
+	gc.Rules.SidePayouts = DefaultSidePayouts()
+	g := NewGame(gc)
+	for _, tt := range tests {
+		h := &Hand{Cards: tt.cards[:2]}
+		g.DealerHand = &Hand{Cards: tt.cards[2:]}
+		if got := g.sideHand(tt.sb, h); got != tt.expected {
+			t.Errorf("%s on %v incorrect. expected=%q got=%q", tt.sb, tt.cards, tt.expected, got)
+		}
+	}
+}
+
+func TestSideBetsSettleOnTheDeal(t *testing.T) {
+	spade := suit("spade")
+	config := GC
+	config.Rules.SidePayouts = DefaultSidePayouts()
+	g, p1 := dealGameHelper(t, config, []Card{
+		{spade, 8}, // player cards
+		{spade, 8},
+		{suit("heart"), 9}, // dealer cards
+		{spade, 10},
+	}, Bet{Main: 10, SideBets: map[SideBet]int{SIDE_BET_PERFECT_PAIRS: 5, SIDE_BET_21_PLUS_3: 5}})
+	if g.State != PLAYER_TURN {
+		t.Fatalf("side bets should settle before the players act. got=%s", g.State)
+	}
+	// the perfect pair pays 25 to 1 and 8-8-9 makes nothing
+	if p1.Wallet != 80+130 {
+		t.Fatalf("side bets not paid on the deal. got=%d", p1.Wallet)
+	}
+	genericErrHelper(t, g.Stay(p1))
+	genericErrHelper(t, g.PlayDealer())
+	results, err := g.ResolveBets()
+	genericErrHelper(t, err)
+	res := results[p1.ID][0]
+	expected := []store.SideBetResult{
+		{Name: "perfect_pairs", Bet: 5, Payout: 130},
+		{Name: "21+3", Bet: 5, Payout: 0},
+	}
+	if !slices.Equal(res.SideBets, expected) {
+		t.Errorf("round result does not report the side bets. got=%+v", res.SideBets)
+	}
+	// 16 loses to 19, the side bets are up 120
+	if res.WalletDelta != -10+120 {
+		t.Errorf("wallet delta incorrect. got=%d", res.WalletDelta)
+	}
+	if p1.SideBets != nil || p1.SideBetResults != nil {
+		t.Errorf("side bets should be cleared after the round")
+	}
+}
+
+func TestSideBetErrors(t *testing.T) {
+	gc := GC
+	gc.Rules.SidePayouts = SidePayouts{SIDE_BET_PERFECT_PAIRS: DefaultSidePayouts()[SIDE_BET_PERFECT_PAIRS]}
+	g := NewGame(gc)
+	p1 := &Player{ID: uuid.New(), Wallet: 20}
+	g.AddPlayer(p1)
+	g.StartGame()
+	tests := []struct {
+		bet    Bet
+		reason BetErrorReason
+	}{
+		{Bet{Main: 10, SideBets: map[SideBet]int{SIDE_BET_21_PLUS_3: 5}}, BET_SIDE_NOT_OFFERED},
+		{Bet{Main: 5, SideBets: map[SideBet]int{SIDE_BET_PERFECT_PAIRS: 10}}, BET_SIDE_OVER_MAIN},
+		{Bet{Main: 5, SideBets: map[SideBet]int{SIDE_BET_PERFECT_PAIRS: -1}}, BET_INVALID},
+		{Bet{Main: 15, SideBets: map[SideBet]int{SIDE_BET_PERFECT_PAIRS: 10}}, BET_OVER_WALLET},
+	}
+	for _, tt := range tests {
+		err := g.PlaceBet(p1, tt.bet)
+		var betErr *BetError
+		if !errors.As(err, &betErr) || betErr.Reason != tt.reason {
+			t.Errorf("expected %s placing %+v. got=%v", tt.reason, tt.bet, err)
+		}
+	}
+	if p1.Wallet != 20 {
+		t.Errorf("a rejected bet shouldn't touch the wallet. got=%d", p1.Wallet)
+	}
+
+	gc.Rules.SidePayouts = DefaultSidePayouts()
+	gc.Variant = Pontoon{}
+	if NewGame(gc).SideBetOffered(SIDE_BET_21_PLUS_3) {
+		t.Errorf("21+3 needs a dealer up card")
+	}
+}
+
+func TestSpotsShareAWallet(t *testing.T) {
+	g := NewGame(GC)
+	g.Deck.Base().Cards = append([]Card{
+		{suit("spade"), 10}, // first spot
+		{suit("heart"), 9},  // second spot
+		{suit("spade"), 10},
+		{suit("heart"), 8},
+		{suit("club"), 10}, // dealer cards
+		{suit("club"), 9},
+	}, g.Deck.Base().Cards...)
+	p1 := NewPlayer(uuid.New(), 100)
+	genericErrHelper(t, g.AddPlayer(p1))
+	spot, err := g.AddSpot(p1)
+	genericErrHelper(t, err)
+	if _, err := g.AddSpot(spot); err == nil {
+		t.Errorf("expected error taking more than %d spots", MAX_SPOTS)
+	}
+	if spots := g.Spots(p1.ID); len(spots) != 2 || spots[1] != spot || spot.OwnerID() != p1.ID {
+		t.Fatalf("spot not seated for the player. got=%v", spots)
+	}
+	genericErrHelper(t, g.StartGame())
+	if g.ActingSpot(p1.ID) != p1 {
+		t.Errorf("first spot should take the first bet")
+	}
+	genericErrHelper(t, g.PlaceBet(g.ActingSpot(p1.ID), Bet{Main: 10}))
+	if g.ActingSpot(p1.ID) != spot {
+		t.Errorf("second spot should take the next bet")
+	}
+	genericErrHelper(t, g.PlaceBet(spot, Bet{Main: 20}))
+	if p1.Wallet != 70 || spot.Wallet != 70 {
+		t.Fatalf("bets should come out of one wallet. got=%d %d", p1.Wallet, spot.Wallet)
+	}
+	genericErrHelper(t, g.StartRound())
+	genericErrHelper(t, g.DealCards())
+	if g.ActingSpot(p1.ID) != p1 {
+		t.Errorf("first spot should act first")
+	}
+	genericErrHelper(t, g.Stay(g.ActingSpot(p1.ID)))
+	if g.ActingSpot(p1.ID) != spot {
+		t.Errorf("second spot should act once the first is done")
+	}
+	genericErrHelper(t, g.Stay(g.ActingSpot(p1.ID)))
+	genericErrHelper(t, g.PlayDealer())
+	results, err := g.ResolveBets()
+	genericErrHelper(t, err)
+	// 20 beats 19 and 17 loses to it
+	if p1.Wallet != 90 || spot.Wallet != 90 {
+		t.Errorf("payouts should go into one wallet. got=%d %d", p1.Wallet, spot.Wallet)
+	}
+	if results[p1.ID][0].Wallet != 90 || results[spot.ID][0].Wallet != 90 {
+		t.Errorf("every spot should report the wallet after the whole round. got=%+v %+v", results[p1.ID], results[spot.ID])
+	}
+
+	if err := g.RemoveSpot(p1); err == nil {
+		t.Errorf("expected error giving up the first spot")
+	}
+	genericErrHelper(t, g.RemoveSpot(spot))
+	if len(g.Spots(p1.ID)) != 1 {
+		t.Errorf("spot should be given up")
+	}
+}
+
+func TestHouseRulePayouts(t *testing.T) {
+	spade, heart := suit("spade"), suit("heart")
+	cards := func(ranks ...cardRank) []Card {
+		out := []Card{}
+		for i, r := range ranks {
+			// alternate suits so nothing is accidentally suited
+			out = append(out, Card{[]suit{spade, heart}[i%2], r})
+		}
+		return out
+	}
+	rules := DefaultRules()
+	rules.Charlie = 5
+	rules.DealerWinsTies = true
+	rules.Bonus678 = 2
+	rules.Bonus777 = 3
+	tests := []struct {
+		name    string
+		player  []Card
+		doubled bool
+		dealer  []Card
+		state   HandState
+		payout  int
+	}{
+		{"five card charlie beats 20", cards(2, 3, 2, 4, 3), false, cards(10, KING), CHARLIE, 20},
+		{"charlie loses to dealer blackjack", cards(2, 3, 2, 4, 3), false, cards(ACE, KING), CHARLIE, 0},
+		{"four cards is not a charlie", cards(2, 3, 2, 4), false, cards(10, 7), LIVE, 0},
+		{"6-7-8 bonus", cards(8, 6, 7), false, cards(10, 7), SIX_SEVEN_EIGHT, 30},
+		{"7-7-7 bonus", cards(7, 7, 7), false, cards(10, 7), SEVEN_SEVEN_SEVEN, 40},
+		{"doubled 6-7-8 is a plain 21", cards(6, 7, 8), true, cards(10, 7), TWENTYONE, 20},
+		{"dealer wins a tie on 18", cards(10, 8), false, cards(9, 9), LIVE, 0},
+		{"tie on 21 goes to the dealer", cards(10, 5, 6), false, cards(7, 4, KING), TWENTYONE, 0},
+		{"blackjacks still push", cards(ACE, KING), false, cards(ACE, QUEEN), BLACKJACK, 10},
+		{"ties under 17 push", cards(10, 6), false, cards(9, 7), LIVE, 10},
+	}
+	g := NewGame(GameConfig{DeckCount: 1, Rules: rules})
+	for _, tt := range tests {
+		h := &Hand{Cards: tt.player, Bet: 10, Doubled: tt.doubled, Rules: rules.HandRules()}
+		g.DealerHand = &Hand{Cards: tt.dealer}
+		if state := h.GetState(); state != tt.state {
+			t.Errorf("%s: hand state incorrect. expected=%s got=%s", tt.name, tt.state, state)
+		}
+		if payout := g.calculatePayout(h); payout != tt.payout {
+			t.Errorf("%s: payout incorrect. expected=%d got=%d", tt.name, tt.payout, payout)
+		}
+	}
+
+	// none of it applies without the house rules
+	h := &Hand{Cards: cards(2, 3, 2, 4, 3), Bet: 10}
+	if h.GetState() != LIVE {
+		t.Errorf("five cards should be a live hand without the Charlie rule. got=%s", h.GetState())
+	}
+}
+
+func TestCharlieEndsTheHand(t *testing.T) {
+	gc := GC
+	gc.Rules.Charlie = 5
+	g := NewGame(gc)
+	g.Deck.Base().Cards = append([]Card{
+		{suit("spade"), 2}, // player cards
+		{suit("spade"), 3},
+		{suit("heart"), 10}, // dealer cards
+		{suit("heart"), 8},
+		{suit("club"), 2}, // player hits
+		{suit("club"), 4},
+		{suit("diamond"), 3},
+	}, g.Deck.Base().Cards...)
+	p1 := &Player{ID: uuid.New(), Wallet: 100}
+	genericErrHelper(t, g.AddPlayer(p1))
+	genericErrHelper(t, g.StartGame())
+	genericErrHelper(t, g.PlaceBet(p1, Bet{Main: 10}))
+	genericErrHelper(t, g.StartRound())
+	genericErrHelper(t, g.DealCards())
+	for range 3 {
+		genericErrHelper(t, g.Hit(p1))
+	}
+	if g.State != DEALER_TURN {
+		t.Fatalf("a Charlie should end the player's turn. got=%s", g.State)
+	}
+	genericErrHelper(t, g.PlayDealer())
+	_, err := g.ResolveBets()
+	genericErrHelper(t, err)
+	// 14 in five cards beats the dealer's 18
+	if p1.Wallet != 110 {
+		t.Errorf("Charlie not paid. got=%d", p1.Wallet)
+	}
+}
+
+func TestAutoPlayAndRebet(t *testing.T) {
+	g := NewGame(GC)
+	g.Deck.Base().Cards = append([]Card{
+		{suit("spade"), 9}, // player cards
+		{suit("spade"), 3},
+		{suit("heart"), 2}, // dealer cards
+		{suit("heart"), 10},
+		{suit("club"), 7}, // player hits
+	}, g.Deck.Base().Cards...)
+	p1 := NewPlayer(uuid.New(), 100)
+	genericErrHelper(t, g.AddPlayer(p1))
+	genericErrHelper(t, g.StartGame())
+	if err := g.Rebet(p1); err == nil {
+		t.Errorf("expected error repeating a bet that was never made")
+	}
+	genericErrHelper(t, g.PlaceBet(p1, Bet{Main: 10}))
+	genericErrHelper(t, g.StartRound())
+	genericErrHelper(t, g.DealCards())
+	g.DrainEvents()
+
+	// 12 against a 2 hits, then 19 stands
+	genericErrHelper(t, g.AutoPlay(p1, BasicStrategyPlayer{}))
+	genericErrHelper(t, g.AutoPlay(p1, BasicStrategyPlayer{}))
+	if g.State != DEALER_TURN {
+		t.Fatalf("the hand should be played out. got=%s", g.State)
+	}
+	acted := []PlayerActed{}
+	for _, e := range g.DrainEvents() {
+		if data, ok := e.Data.(PlayerActed); ok {
+			acted = append(acted, data)
+		}
+	}
+	if len(acted) != 2 || acted[0].Action != ACTION_HIT || acted[1].Action != ACTION_STAND {
+		t.Fatalf("expected a hit then a stand. got=%+v", acted)
+	}
+	for _, a := range acted {
+		if !a.Automatic || a.Advice != "" {
+			t.Errorf("plays the game makes should be automatic and not reviewed. got=%+v", a)
+		}
+	}
+	genericErrHelper(t, g.PlayDealer())
+	_, err := g.ResolveBets()
+	genericErrHelper(t, err)
+	g.DrainEvents()
+
+	wallet := p1.Wallet
+	genericErrHelper(t, g.Rebet(p1))
+	if p1.Bet != 10 || p1.Wallet != wallet-10 {
+		t.Errorf("last bet should be put down again. got bet=%d wallet=%d", p1.Bet, p1.Wallet)
+	}
+	events := g.DrainEvents()
+	if placed, ok := events[len(events)-1].Data.(BetPlaced); !ok || !placed.Automatic {
+		t.Errorf("a repeated bet should be automatic. got=%+v", events[len(events)-1].Data)
+	}
+}
+
+func TestSittingOutDoesNotHoldUpBets(t *testing.T) {
+	g := NewGame(GC)
+	p1 := NewPlayer(uuid.New(), 100)
+	p2 := NewPlayer(uuid.New(), 100)
+	genericErrHelper(t, g.AddPlayer(p1))
+	genericErrHelper(t, g.AddPlayer(p2))
+	genericErrHelper(t, g.StartGame())
+	genericErrHelper(t, g.PlaceBet(p1, Bet{Main: 10}))
+	p2.SittingOut = true
+	if !g.AllPlayersBet() {
+		t.Errorf("players sitting out shouldn't hold up the round")
+	}
+	genericErrHelper(t, g.StartRound())
+	if p2.IsActive() {
+		t.Errorf("players sitting out shouldn't be dealt in")
+	}
+}
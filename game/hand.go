@@ -1,5 +1,7 @@
 package game
 
+import "slices"
+
 type HandState int
 
 const (
@@ -7,6 +9,11 @@ const (
 	BUST
 	BLACKJACK
 	TWENTYONE
+
+	// only at tables that play the house rule
+	CHARLIE           // an unbusted hand with the table's Charlie number of cards
+	SIX_SEVEN_EIGHT   // a three card 21 of 6-7-8
+	SEVEN_SEVEN_SEVEN // a three card 21 of 7-7-7
 )
 
 func (hs HandState) String() string {
@@ -19,18 +26,63 @@ func (hs HandState) String() string {
 		return "BlackJack"
 	case TWENTYONE:
 		return "TwentyOne"
+	case CHARLIE:
+		return "Charlie"
+	case SIX_SEVEN_EIGHT:
+		return "SixSevenEight"
+	case SEVEN_SEVEN_SEVEN:
+		return "SevenSevenSeven"
 	}
 	return ""
 }
 
+// finished is true for a hand that can't take another card
+func (hs HandState) finished() bool {
+	switch hs {
+	case BUST, TWENTYONE, CHARLIE, SIX_SEVEN_EIGHT, SEVEN_SEVEN_SEVEN:
+		return true
+	}
+	return false
+}
+
+// HandRules are the house rules that change what a hand is. The game sets them on every hand it deals
+type HandRules struct {
+	Charlie  int  `json:"charlie,omitempty"`   // an unbusted hand with this many cards wins. 0 turns it off
+	Bonus678 bool `json:"bonus_678,omitempty"` // a three card 6-7-8 21 pays a bonus
+	Bonus777 bool `json:"bonus_777,omitempty"` // a three card 7-7-7 21 pays a bonus
+}
+
 type Hand struct {
 	Cards []Card
+	Bet   int  // Amount wagered on this hand. Splitting and doubling give each hand its own bet
+	Split bool // Hand was created by splitting a pair. A split hand can't be a blackjack
+
+	Doubled bool // Player doubled down and took one last card
+
+	EvenMoney   bool // Player took even money on a blackjack when the dealer showed an ace
+	Surrendered bool // Player gave up the hand for half of the bet
+
+	Rules HandRules
 }
 
 func NewHand() *Hand {
 	return &Hand{Cards: []Card{}}
 }
 
+// IsPair reports whether the hand is two cards of the same value and can be split
+func (h *Hand) IsPair() bool {
+	if len(h.Cards) != 2 {
+		return false
+	}
+	v1, _ := calculateValue(h.Cards[:1])
+	v2, _ := calculateValue(h.Cards[1:])
+	return v1 == v2
+}
+
+func (h *Hand) IsSplitAces() bool {
+	return h.Split && len(h.Cards) > 0 && h.Cards[0].Rank == ACE
+}
+
 func (h *Hand) AddCard(c Card) {
 	h.Cards = append(h.Cards, c)
 }
@@ -46,13 +98,27 @@ func (h *Hand) GetValue() int {
 }
 
 func (h *Hand) GetState() HandState {
+	if h.GetValue() > 21 {
+		return BUST
+	}
+	if h.Rules.Charlie > 0 && len(h.Cards) >= h.Rules.Charlie {
+		return CHARLIE
+	}
 	if h.GetValue() == 21 {
-		if len(h.Cards) == 2 {
+		if len(h.Cards) == 2 && !h.Split {
 			return BLACKJACK
 		}
+		if len(h.Cards) == 3 && !h.Doubled {
+			ranks := []cardRank{h.Cards[0].Rank, h.Cards[1].Rank, h.Cards[2].Rank}
+			slices.Sort(ranks)
+			if h.Rules.Bonus678 && slices.Equal(ranks, []cardRank{6, 7, 8}) {
+				return SIX_SEVEN_EIGHT
+			}
+			if h.Rules.Bonus777 && slices.Equal(ranks, []cardRank{7, 7, 7}) {
+				return SEVEN_SEVEN_SEVEN
+			}
+		}
 		return TWENTYONE
-	} else if h.GetValue() > 21 {
-		return BUST
 	}
 	return LIVE
 }
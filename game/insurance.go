@@ -0,0 +1,144 @@
+package game
+
+import (
+	"fmt"
+	"log/slog"
+)
+
+// The insurance window opens after the deal when the dealer's up card is an ace.
+// Players can insure their hand for up to half of their bet, take even money on a
+// blackjack, or decline. Insurance pays 2:1 if the dealer has blackjack. The dealer
+// peeks at the hole card once the window closes. Tables with early surrender also open
+// the window on a ten so players can surrender before the peek.
+
+func (g *Game) StartInsurance() error {
+	err := g.checkState(DEALING, "StartInsurance")
+	if err != nil {
+		return err
+	}
+	g.State = INSURANCE
+	for _, p := range g.activePlayers {
+		p.Insurance = 0
+		p.InsuranceDecided = false
+	}
+	g.emit(InsuranceOffered{UpCard: g.DealerHand.Cards[0]})
+	return nil
+}
+
+func (g *Game) PlaceInsurance(p *Player, amount int) error {
+	err := g.checkInsuranceDecision(p, "PlaceInsurance")
+	if err != nil {
+		return err
+	}
+	if !g.insuranceOffered() {
+		return fmt.Errorf("Insurance is only offered when the dealer shows an ace")
+	}
+	hand := p.Hands[0]
+	if hand.GetState() == BLACKJACK {
+		return fmt.Errorf("You have blackjack. Take even money instead")
+	}
+	if amount < 1 {
+		return fmt.Errorf("Insurance must be at least 1")
+	}
+	if amount > hand.Bet/2 {
+		return fmt.Errorf("Insurance cannot be more than half of your bet (%d)", hand.Bet/2)
+	}
+	if amount > p.Wallet {
+		return fmt.Errorf("Insurance cannot be higher than current wallet amount")
+	}
+	advice := g.advice(p)
+	g.pay(p, -amount)
+	p.Insurance = amount
+	p.InsuranceDecided = true
+	g.emit(PlayerActed{PlayerID: p.ID, Action: ACTION_INSURANCE, Amount: amount, Advice: advice})
+	return nil
+}
+
+func (g *Game) TakeEvenMoney(p *Player) error {
+	err := g.checkInsuranceDecision(p, "TakeEvenMoney")
+	if err != nil {
+		return err
+	}
+	if !g.insuranceOffered() {
+		return fmt.Errorf("Even money is only offered when the dealer shows an ace")
+	}
+	hand := p.Hands[0]
+	if hand.GetState() != BLACKJACK {
+		return fmt.Errorf("Even money is only offered on a blackjack")
+	}
+	advice := g.advice(p)
+	hand.EvenMoney = true
+	p.InsuranceDecided = true
+	g.emit(PlayerActed{PlayerID: p.ID, Action: ACTION_EVEN_MONEY, Advice: advice})
+	return nil
+}
+
+func (g *Game) DeclineInsurance(p *Player) error {
+	err := g.checkInsuranceDecision(p, "DeclineInsurance")
+	if err != nil {
+		return err
+	}
+	advice := g.advice(p)
+	p.InsuranceDecided = true
+	g.emit(PlayerActed{PlayerID: p.ID, Action: ACTION_DECLINE_INSURANCE, Advice: advice})
+	return nil
+}
+
+// AllInsuranceDecided is true once every connected player in the round has made an insurance decision
+func (g *Game) AllInsuranceDecided() bool {
+	for _, p := range g.activePlayers {
+		if !p.DisconnectedAt.IsZero() {
+			continue
+		}
+		if !p.InsuranceDecided {
+			return false
+		}
+	}
+	return true
+}
+
+// EndInsurance closes the insurance window. Anybody who hasn't decided yet declines
+func (g *Game) EndInsurance() error {
+	err := g.checkState(INSURANCE, "EndInsurance")
+	if err != nil {
+		return err
+	}
+	for _, p := range g.activePlayers {
+		if !p.InsuranceDecided {
+			slog.Debug("Player did not decide on insurance. Declining", "player", p.ID)
+			p.InsuranceDecided = true
+			g.emit(PlayerActed{PlayerID: p.ID, Action: ACTION_DECLINE_INSURANCE, Automatic: true})
+		}
+	}
+	return g.startPlay()
+}
+
+// insuranceOffered is true when the dealer's only up card is an ace. Players who can see the
+// whole dealer hand, or none of it, have no use for insurance
+func (g *Game) insuranceOffered() bool {
+	return g.Config.Variant.UpCards() == 1 && g.DealerHand.Cards[0].Rank == ACE
+}
+
+func (g *Game) checkInsuranceDecision(p *Player, method string) error {
+	err := g.checkState(INSURANCE, method)
+	if err != nil {
+		return err
+	}
+	if p == nil || len(p.Hands) == 0 || !p.IsActive() {
+		return fmt.Errorf("You are not playing this round")
+	}
+	if p.InsuranceDecided {
+		return fmt.Errorf("You already made your insurance decision")
+	}
+	return nil
+}
+
+func (g *Game) calculateInsurancePayout(p *Player) int {
+	if p.Insurance == 0 {
+		return 0
+	}
+	if g.DealerHand.GetState() == BLACKJACK {
+		return p.Insurance * 3
+	}
+	return 0
+}
@@ -0,0 +1,109 @@
+package game
+
+import "fmt"
+
+// The progressive jackpot is an optional side bet for a fixed amount that goes straight into a
+// pool shared with other tables. It is placed after the main bet and pays a share of the pool
+// when the round ends on one of the jackpot hands. The pool itself lives outside the game
+
+// JackpotPool is where jackpot side bets go and where jackpots are paid from
+type JackpotPool interface {
+	Add(amount int)      // a side bet went into the pool
+	Win(percent int) int // pays out a share of the pool and returns what was paid
+}
+
+type JackpotHand string
+
+const (
+	JACKPOT_NONE              JackpotHand = ""
+	JACKPOT_SUITED_777        JackpotHand = "suited_777"        // the player's first three cards are 7s of one suit
+	JACKPOT_SUITED_BLACKJACKS JackpotHand = "suited_blackjacks" // the player and the dealer both have a suited blackjack
+)
+
+// JackpotShares is the percent of the pool each jackpot hand pays
+var JackpotShares = map[JackpotHand]int{
+	JACKPOT_SUITED_777:        100,
+	JACKPOT_SUITED_BLACKJACKS: 10,
+}
+
+func (jh JackpotHand) String() string {
+	switch jh {
+	case JACKPOT_SUITED_777:
+		return "suited 7-7-7"
+	case JACKPOT_SUITED_BLACKJACKS:
+		return "suited blackjacks"
+	}
+	return "no jackpot"
+}
+
+// JackpotOffered is true if the table takes jackpot side bets
+func (g *Game) JackpotOffered() bool {
+	return g.Config.Jackpot != nil && g.Config.Rules.JackpotBet > 0
+}
+
+// PlaceJackpotBet puts the table's jackpot bet into the pool for the player's next round
+func (g *Game) PlaceJackpotBet(p *Player) error {
+	err := g.checkState(WAITING_FOR_BETS, "PlaceJackpotBet")
+	if err != nil {
+		return err
+	}
+	if !g.JackpotOffered() {
+		return fmt.Errorf("This table doesn't offer the jackpot")
+	}
+	if p == nil || p.State != BETS_MADE {
+		return fmt.Errorf("Place your bet before the jackpot bet")
+	}
+	if p.Jackpot > 0 {
+		return fmt.Errorf("Jackpot bet already made")
+	}
+	amount := g.Config.Rules.JackpotBet
+	if amount > p.Wallet {
+		return fmt.Errorf("Jackpot bet cannot be higher than current wallet amount")
+	}
+	g.pay(p, -amount)
+	p.Jackpot = amount
+	g.Config.Jackpot.Add(amount)
+	return nil
+}
+
+// jackpotHand is the best jackpot hand the player's first hand made. A hand that was split
+// no longer holds the cards it was dealt
+func (g *Game) jackpotHand(p *Player) JackpotHand {
+	if len(p.Hands) == 0 || p.Hands[0].Split {
+		return JACKPOT_NONE
+	}
+	h := p.Hands[0]
+	if len(h.Cards) >= 3 && suited(h.Cards[:3]) && h.Cards[0].Rank == 7 && h.Cards[1].Rank == 7 && h.Cards[2].Rank == 7 {
+		return JACKPOT_SUITED_777
+	}
+	if h.GetState() == BLACKJACK && suited(h.Cards) && g.DealerHand.GetState() == BLACKJACK && suited(g.DealerHand.Cards) {
+		return JACKPOT_SUITED_BLACKJACKS
+	}
+	return JACKPOT_NONE
+}
+
+// resolveJackpot pays the player's jackpot side bet out of the pool
+func (g *Game) resolveJackpot(p *Player) int {
+	if p.Jackpot == 0 || g.Config.Jackpot == nil {
+		return 0
+	}
+	hand := g.jackpotHand(p)
+	payout := 0
+	if hand != JACKPOT_NONE {
+		payout = g.Config.Jackpot.Win(JackpotShares[hand])
+	}
+	g.emit(JackpotResolved{PlayerID: p.ID, Amount: p.Jackpot, Hand: hand, Payout: payout})
+	return payout
+}
+
+func suited(cards []Card) bool {
+	if len(cards) == 0 {
+		return false
+	}
+	for _, c := range cards[1:] {
+		if c.Suit != cards[0].Suit {
+			return false
+		}
+	}
+	return true
+}
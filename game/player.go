@@ -1,10 +1,10 @@
 package game
 
 import (
-	"fmt"
 	"log/slog"
 	"time"
 
+	"github.com/dylanmccormick/blackjack-tui/store"
 	"github.com/google/uuid"
 )
 
@@ -13,10 +13,28 @@ const DISCONNECT_TIMEOUT = 3 * time.Minute
 type Player struct {
 	Name   string
 	ID     uuid.UUID
+	Owner  uuid.UUID // the player this spot belongs to. Every spot an owner plays shares one wallet
 	State  PlayerState
-	Bet    int // Used per round. How much the player is betting that round
+	Bet    int // Used per round. How much the player is betting that round across all hands
 	Wallet int // Used for a session. How much the player has at a session
-	Hand   *Hand
+	Hands  []*Hand
+	Bot    bool // played by the server. Bots have no user behind them
+
+	// Timeouts. The table decides what happens when a player runs out of time
+	LastBet    Bet  // the last bet the player put down. The table can put it down again for them
+	Misses     int  // timeouts in a row. Starts over when the player plays or bets themselves
+	SittingOut bool // the table stopped waiting on the player's bets until they bet again
+	AutoPlay   bool // the player asked the table to bet and play for them
+
+	// Insurance side bet. Only offered when the dealer shows an ace
+	Insurance        int
+	InsuranceDecided bool
+
+	Jackpot int // progressive jackpot side bet
+
+	// Side bets settled on the deal
+	SideBets       map[SideBet]int
+	SideBetResults []store.SideBetResult
 
 	// Connection logic
 	ConnectedAt           time.Time
@@ -28,7 +46,8 @@ func NewPlayer(id uuid.UUID, wallet int) *Player {
 	slog.Debug("Creating new player")
 	return &Player{
 		ID:                    id,
-		Hand:                  &Hand{},
+		Owner:                 id,
+		Hands:                 []*Hand{},
 		Bet:                   0,
 		Wallet:                wallet,
 		Name:                  "placeholder",
@@ -67,14 +86,31 @@ func (ps PlayerState) String() string {
 
 func (p *Player) ValidateBet(bet int) error {
 	if bet < 1 {
-		return fmt.Errorf("Bets cannot be negative")
+		return &BetError{Reason: BET_INVALID, Bet: bet}
 	}
 	if bet > p.Wallet {
-		return fmt.Errorf("Bet cannot be higher than current wallet amount")
+		return &BetError{Reason: BET_OVER_WALLET, Bet: bet, Wallet: p.Wallet}
 	}
 	return nil
 }
 
+// SideBetTotal is what the player has on Perfect Pairs and 21+3 this round
+func (p *Player) SideBetTotal() int {
+	total := 0
+	for _, amount := range p.SideBets {
+		total += amount
+	}
+	return total
+}
+
+// OwnerID is who is playing the spot. A player's first spot is their own
+func (p *Player) OwnerID() uuid.UUID {
+	if p.Owner == uuid.Nil {
+		return p.ID
+	}
+	return p.Owner
+}
+
 func (p *Player) IsActive() bool {
 	return p.State != INACTIVE
 }
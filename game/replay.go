@@ -0,0 +1,242 @@
+package game
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// RoundStep is the table as it looked right after one event of a round
+type RoundStep struct {
+	Event       Event
+	Description string
+	State       GameState
+	DealerHand  *Hand
+	Players     []*Player // seat order. Only the players in the round
+	CurrentHand int       // hand being played by the player in PLAYING_TURN
+	Variant     Variant
+}
+
+// ReplayRound rebuilds a round from its events, one step per event that changed the table.
+// Shuffles, burns and bets placed before the deal are skipped. The bets are in RoundStarted
+func ReplayRound(events []Event) ([]RoundStep, error) {
+	r := &replay{state: DEALING, dealer: NewHand(), players: []*Player{}, variant: Classic{}}
+	steps := []RoundStep{}
+	for _, e := range events {
+		desc, err := r.apply(e)
+		if err != nil {
+			return steps, fmt.Errorf("Event %d: %w", e.Seq, err)
+		}
+		if desc == "" {
+			continue
+		}
+		steps = append(steps, r.snapshot(e, desc))
+	}
+	return steps, nil
+}
+
+type replay struct {
+	state       GameState
+	dealer      *Hand
+	players     []*Player
+	currentHand int
+	variant     Variant
+}
+
+func (r *replay) apply(e Event) (string, error) {
+	switch data := e.Data.(type) {
+	case RoundStarted:
+		variant, err := ParseVariant(data.Variant)
+		if err != nil {
+			return "", err
+		}
+		r.variant = variant
+		names := []string{}
+		for _, rp := range data.Players {
+			p := &Player{ID: rp.PlayerID, Name: rp.Name, Owner: rp.Owner, Bot: rp.Bot, Bet: rp.Bet, Jackpot: rp.Jackpot, SideBets: rp.SideBets, State: WAITING_FOR_TURN}
+			p.Hands = []*Hand{{Cards: []Card{}, Bet: rp.Bet, Rules: data.Rules}}
+			r.players = append(r.players, p)
+			names = append(names, rp.Name)
+		}
+		return fmt.Sprintf("Round %d started with %s", e.Round, strings.Join(names, ", ")), nil
+	case CardDealt:
+		if data.Dealer {
+			r.dealer.Cards = append(r.dealer.Cards, data.Card)
+			if data.FaceDown {
+				return "Dealer takes the hole card", nil
+			}
+			return fmt.Sprintf("Dealer gets the %s", cardName(data.Card)), nil
+		}
+		p, h, err := r.hand(data.PlayerID, data.Hand)
+		if err != nil {
+			return "", err
+		}
+		h.Cards = append(h.Cards, data.Card)
+		return fmt.Sprintf("%s gets the %s", p.Name, cardName(data.Card)), nil
+	case InsuranceOffered:
+		r.state = INSURANCE
+		return fmt.Sprintf("Dealer shows the %s", cardName(data.UpCard)), nil
+	case DealerPeeked:
+		if data.Blackjack {
+			return "Dealer peeks and has blackjack", nil
+		}
+		return "Dealer peeks. No blackjack", nil
+	case TurnChanged:
+		for _, p := range r.players {
+			if p.State == PLAYING_TURN {
+				p.State = DONE
+			}
+		}
+		if data.Dealer {
+			r.state = DEALER_TURN
+			return "Dealer's turn", nil
+		}
+		p, _, err := r.hand(data.PlayerID, data.Hand)
+		if err != nil {
+			return "", err
+		}
+		r.state = PLAYER_TURN
+		p.State = PLAYING_TURN
+		r.currentHand = data.Hand
+		return fmt.Sprintf("%s's turn on hand %d", p.Name, data.Hand+1), nil
+	case PlayerActed:
+		return r.act(data)
+	case HoleCardRevealed:
+		r.state = DEALER_TURN
+		return fmt.Sprintf("Dealer turns over the %s", cardName(data.Card)), nil
+	case HandResolved:
+		r.state = RESOLVING_BETS
+		p, _, err := r.hand(data.PlayerID, data.Hand)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("%s %s hand %d. Bet %d, paid %d", p.Name, data.Outcome, data.Hand+1, data.Bet, data.Payout), nil
+	case InsuranceResolved:
+		p := r.player(data.PlayerID)
+		if p == nil {
+			return "", fmt.Errorf("Player %s is not in the round", data.PlayerID)
+		}
+		return fmt.Sprintf("%s's insurance of %d paid %d", p.Name, data.Amount, data.Payout), nil
+	case SideBetResolved:
+		p := r.player(data.PlayerID)
+		if p == nil {
+			return "", fmt.Errorf("Player %s is not in the round", data.PlayerID)
+		}
+		if data.Hand == SIDE_HAND_NONE {
+			return fmt.Sprintf("%s's %s bet of %d lost", p.Name, data.SideBet, data.Amount), nil
+		}
+		return fmt.Sprintf("%s's %s bet of %d made a %s and paid %d", p.Name, data.SideBet, data.Amount, data.Hand, data.Payout), nil
+	case JackpotResolved:
+		p := r.player(data.PlayerID)
+		if p == nil {
+			return "", fmt.Errorf("Player %s is not in the round", data.PlayerID)
+		}
+		if data.Hand == JACKPOT_NONE {
+			return fmt.Sprintf("%s's jackpot bet of %d lost", p.Name, data.Amount), nil
+		}
+		return fmt.Sprintf("%s hit the jackpot with %s for %d", p.Name, data.Hand, data.Payout), nil
+	case RoundEnded:
+		r.state = RESOLVING_BETS
+		for _, p := range r.players {
+			p.State = DONE
+		}
+		return fmt.Sprintf("Round over. Dealer has %d", data.DealerValue), nil
+	}
+	return "", nil
+}
+
+func (r *replay) act(a PlayerActed) (string, error) {
+	p, h, err := r.hand(a.PlayerID, a.Hand)
+	if err != nil {
+		return "", err
+	}
+	var desc string
+	switch a.Action {
+	case ACTION_HIT:
+		desc = fmt.Sprintf("%s hits", p.Name)
+	case ACTION_STAND:
+		desc = fmt.Sprintf("%s stands", p.Name)
+	case ACTION_DOUBLE:
+		h.Bet += a.Amount
+		h.Doubled = true
+		p.Bet += a.Amount
+		desc = fmt.Sprintf("%s doubles for %d", p.Name, a.Amount)
+	case ACTION_SPLIT:
+		if len(h.Cards) != 2 {
+			return "", fmt.Errorf("%s split a hand of %d cards", p.Name, len(h.Cards))
+		}
+		newHand := &Hand{Cards: []Card{h.Cards[1]}, Bet: a.Amount, Split: true, Rules: h.Rules}
+		h.Cards = h.Cards[:1]
+		h.Split = true
+		p.Bet += a.Amount
+		p.Hands = slices.Insert(p.Hands, a.Hand+1, newHand)
+		desc = fmt.Sprintf("%s splits", p.Name)
+	case ACTION_SURRENDER:
+		h.Surrendered = true
+		desc = fmt.Sprintf("%s surrenders", p.Name)
+	case ACTION_INSURANCE:
+		p.Insurance = a.Amount
+		desc = fmt.Sprintf("%s takes insurance for %d", p.Name, a.Amount)
+	case ACTION_EVEN_MONEY:
+		h.EvenMoney = true
+		desc = fmt.Sprintf("%s takes even money", p.Name)
+	case ACTION_DECLINE_INSURANCE:
+		desc = fmt.Sprintf("%s declines insurance", p.Name)
+	default:
+		return "", fmt.Errorf("Unknown action %q", a.Action)
+	}
+	if a.Automatic {
+		desc += " (automatic)"
+	}
+	return desc, nil
+}
+
+func (r *replay) player(id uuid.UUID) *Player {
+	for _, p := range r.players {
+		if p.ID == id {
+			return p
+		}
+	}
+	return nil
+}
+
+func (r *replay) hand(id uuid.UUID, hand int) (*Player, *Hand, error) {
+	p := r.player(id)
+	if p == nil {
+		return nil, nil, fmt.Errorf("Player %s is not in the round", id)
+	}
+	if hand < 0 || hand >= len(p.Hands) {
+		return nil, nil, fmt.Errorf("%s has no hand %d", p.Name, hand)
+	}
+	return p, p.Hands[hand], nil
+}
+
+// snapshot copies the table so later events don't change earlier steps
+func (r *replay) snapshot(e Event, desc string) RoundStep {
+	players := []*Player{}
+	for _, p := range r.players {
+		cp := *p
+		cp.Hands = []*Hand{}
+		for _, h := range p.Hands {
+			cp.Hands = append(cp.Hands, copyHand(h))
+		}
+		players = append(players, &cp)
+	}
+	return RoundStep{
+		Event:       e,
+		Description: desc,
+		State:       r.state,
+		DealerHand:  copyHand(r.dealer),
+		Players:     players,
+		CurrentHand: r.currentHand,
+		Variant:     r.variant,
+	}
+}
+
+func copyHand(h *Hand) *Hand {
+	cp := *h
+	cp.Cards = slices.Clone(h.Cards)
+	return &cp
+}
@@ -0,0 +1,138 @@
+package game
+
+import (
+	"fmt"
+	"strings"
+)
+
+// RuleSet is the house rules for a single table
+type RuleSet struct {
+	StandOnSoft17   bool   // Dealer stands on soft 17. Otherwise the dealer hits it
+	BlackjackPayout Payout // 3:2 or 6:5
+
+	// Double rules
+	DoubleRule       DoubleRule // Which starting totals can be doubled
+	DoubleAfterSplit bool       // Split hands can be doubled
+
+	// Split rules
+	MaxSplits    int  // How many times a player can split in one round. 0 uses MAX_SPLITS
+	ResplitAces  bool // Split aces can be split again when another ace is dealt
+	HitSplitAces bool // Split aces get one card each unless hitting is allowed
+
+	MaxSpots int // How many spots one player can play at once. 0 uses MAX_SPOTS
+
+	// European no hole card rule. The dealer only takes a second card once the players are done,
+	// so there is nothing to peek at
+	NoHoleCard bool
+
+	Surrender SurrenderRule
+
+	// Optional house rules the engine checks on every hand
+	Charlie        int  // An unbusted hand with this many cards wins, usually 5 or 6. 0 turns it off
+	DealerWinsTies bool // The dealer wins a tie on 17 or more. Blackjacks still push
+	Bonus678       int  // What a three card 21 of 6-7-8 pays, to 1. 0 turns the bonus off
+	Bonus777       int  // What a three card 21 of 7-7-7 pays, to 1. 0 turns the bonus off
+
+	// Deal from a continuous shuffling machine instead of a shoe with a cut card
+	ContinuousShuffle bool
+
+	// Table limits
+	MinBet       int
+	MaxBet       int // 0 means the only limit is the player's wallet
+	BetIncrement int // Bets have to be a multiple of this. 0 or 1 allows any amount
+
+	JackpotBet int // What the progressive jackpot side bet costs. 0 means the table doesn't offer it
+
+	SidePayouts SidePayouts // Pay tables for Perfect Pairs and 21+3. A side bet without one isn't offered
+}
+
+// DefaultRules are the rules a table gets unless it is configured otherwise
+func DefaultRules() RuleSet {
+	return RuleSet{
+		StandOnSoft17:    true,
+		BlackjackPayout:  PAYOUT_3_2,
+		DoubleRule:       DOUBLE_ANY,
+		DoubleAfterSplit: true,
+		MaxSplits:        MAX_SPLITS,
+		MaxSpots:         MAX_SPOTS,
+		Surrender:        NO_SURRENDER,
+		MinBet:           1,
+	}
+}
+
+// HandRules are the house rules every hand dealt at the table carries
+func (r RuleSet) HandRules() HandRules {
+	return HandRules{Charlie: r.Charlie, Bonus678: r.Bonus678 > 0, Bonus777: r.Bonus777 > 0}
+}
+
+type Payout struct {
+	Numerator   int
+	Denominator int
+}
+
+var (
+	PAYOUT_3_2 = Payout{3, 2}
+	PAYOUT_6_5 = Payout{6, 5}
+)
+
+func (p Payout) String() string {
+	return fmt.Sprintf("%d:%d", p.Numerator, p.Denominator)
+}
+
+// Pay is the winnings on a bet, rounded down. The bet itself is not included
+func (p Payout) Pay(bet int) int {
+	return bet * p.Numerator / p.Denominator
+}
+
+func ParsePayout(s string) (Payout, error) {
+	switch s {
+	case "", "3:2":
+		return PAYOUT_3_2, nil
+	case "6:5":
+		return PAYOUT_6_5, nil
+	}
+	return PAYOUT_3_2, fmt.Errorf("unknown blackjack payout %q", s)
+}
+
+type DoubleRule int
+
+const (
+	DOUBLE_ANY DoubleRule = iota
+	DOUBLE_9_TO_11
+	DOUBLE_10_TO_11
+)
+
+func (dr DoubleRule) String() string {
+	switch dr {
+	case DOUBLE_ANY:
+		return "any"
+	case DOUBLE_9_TO_11:
+		return "9-11"
+	case DOUBLE_10_TO_11:
+		return "10-11"
+	}
+	return ""
+}
+
+func ParseDoubleRule(s string) (DoubleRule, error) {
+	switch strings.ToLower(s) {
+	case "", "any":
+		return DOUBLE_ANY, nil
+	case "9-11":
+		return DOUBLE_9_TO_11, nil
+	case "10-11":
+		return DOUBLE_10_TO_11, nil
+	}
+	return DOUBLE_ANY, fmt.Errorf("unknown double rule %q", s)
+}
+
+// Allows reports whether a hand with this value can be doubled
+func (dr DoubleRule) Allows(value int) bool {
+	switch dr {
+	case DOUBLE_9_TO_11:
+		return value >= 9 && value <= 11
+	case DOUBLE_10_TO_11:
+		return value >= 10 && value <= 11
+	}
+	return true
+}
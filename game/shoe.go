@@ -0,0 +1,57 @@
+package game
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"io"
+	"math/rand/v2"
+)
+
+// Shoe is what the game deals from. Deck is the classic shoe with a cut card,
+// CSM is a continuous shuffling machine
+type Shoe interface {
+	DrawCard() (Card, error)
+	Burn() error
+	Shuffle()
+	NeedsReshuffle() bool
+	ReshuffleDiscards(inPlay []Card)
+	Base() *Deck // the cards behind the shoe
+}
+
+// CSM is a continuous shuffling machine. The cards from every round go straight back in
+// and the shoe is reshuffled, so penetration never builds up and there's nothing to count.
+// The machine keeps one seed for its lifetime and every round's order comes from it
+type CSM struct {
+	*Deck
+	rounds int // shuffles since the seed was drawn
+}
+
+func NewCSM(numDecks int, entropy io.Reader, variant Variant) *CSM {
+	return &CSM{Deck: NewVariantDeck(numDecks, 0, entropy, variant)}
+}
+
+// NeedsReshuffle is always true so the game shuffles between every round
+func (c *CSM) NeedsReshuffle() bool {
+	return true
+}
+
+// Shuffle brings every card back in and shuffles them in the order for the next round.
+// The seed stays the same
+func (c *CSM) Shuffle() {
+	c.rounds++
+	c.rng = rand.New(rand.NewChaCha8(roundSeed(c.Seed, c.rounds)))
+	c.Cards = Shuffle(orderedCards(c.DeckCount, c.variant), c.rng)
+	c.UsedCards = []Card{}
+	// the order no longer follows the seed on its own
+	c.mixed = true
+}
+
+// roundSeed derives a round's shuffle from the machine's seed and the round number
+func roundSeed(seed Seed, round int) Seed {
+	b := binary.BigEndian.AppendUint64(seed[:], uint64(round))
+	return sha256.Sum256(b)
+}
+
+func (d *Deck) Base() *Deck {
+	return d
+}
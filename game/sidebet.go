@@ -0,0 +1,222 @@
+package game
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/dylanmccormick/blackjack-tui/store"
+)
+
+// Side bets are placed with the main bet and settled as soon as the cards are dealt, before
+// anyone plays. Perfect Pairs looks at the player's two cards and 21+3 makes a three card poker
+// hand out of the player's two cards and the dealer's up card. What each hand pays is part of the
+// table rules and a side bet without a payout table isn't offered
+
+type SideBet string
+
+const (
+	SIDE_BET_PERFECT_PAIRS SideBet = "perfect_pairs"
+	SIDE_BET_21_PLUS_3     SideBet = "21+3"
+)
+
+// SideHand is a hand a side bet pays on
+type SideHand string
+
+const (
+	SIDE_HAND_NONE SideHand = ""
+
+	// Perfect Pairs
+	PERFECT_PAIR SideHand = "perfect_pair" // same rank and suit
+	COLORED_PAIR SideHand = "colored_pair" // same rank and color
+	MIXED_PAIR   SideHand = "mixed_pair"   // same rank
+
+	// 21+3
+	SUITED_TRIPS    SideHand = "suited_trips"
+	STRAIGHT_FLUSH  SideHand = "straight_flush"
+	THREE_OF_A_KIND SideHand = "three_of_a_kind"
+	STRAIGHT        SideHand = "straight"
+	FLUSH           SideHand = "flush"
+)
+
+// sideHands is every hand a side bet can make, best first
+var sideHands = map[SideBet][]SideHand{
+	SIDE_BET_PERFECT_PAIRS: {PERFECT_PAIR, COLORED_PAIR, MIXED_PAIR},
+	SIDE_BET_21_PLUS_3:     {SUITED_TRIPS, STRAIGHT_FLUSH, THREE_OF_A_KIND, STRAIGHT, FLUSH},
+}
+
+// SideBets lists the side bets in the order they are settled
+var SideBets = []SideBet{SIDE_BET_PERFECT_PAIRS, SIDE_BET_21_PLUS_3}
+
+func (sb SideBet) String() string {
+	switch sb {
+	case SIDE_BET_PERFECT_PAIRS:
+		return "Perfect Pairs"
+	case SIDE_BET_21_PLUS_3:
+		return "21+3"
+	}
+	return string(sb)
+}
+
+func (sh SideHand) String() string {
+	if sh == SIDE_HAND_NONE {
+		return "nothing"
+	}
+	return strings.ReplaceAll(string(sh), "_", " ")
+}
+
+// SidePayouts is what each side bet hand pays, to 1
+type SidePayouts map[SideBet]map[SideHand]int
+
+// DefaultSidePayouts is the usual pay table for both side bets
+func DefaultSidePayouts() SidePayouts {
+	return SidePayouts{
+		SIDE_BET_PERFECT_PAIRS: {PERFECT_PAIR: 25, COLORED_PAIR: 12, MIXED_PAIR: 6},
+		SIDE_BET_21_PLUS_3:     {SUITED_TRIPS: 100, STRAIGHT_FLUSH: 40, THREE_OF_A_KIND: 30, STRAIGHT: 10, FLUSH: 5},
+	}
+}
+
+// ParseSidePayouts reads a pay table for one side bet from hand names
+func ParseSidePayouts(sb SideBet, payouts map[string]int) (map[SideHand]int, error) {
+	hands, ok := sideHands[sb]
+	if !ok {
+		return nil, fmt.Errorf("Unknown side bet %q", sb)
+	}
+	out := map[SideHand]int{}
+	for name, pays := range payouts {
+		hand := SideHand(name)
+		if !slices.Contains(hands, hand) {
+			return nil, fmt.Errorf("%s doesn't pay on %q", sb, name)
+		}
+		if pays < 1 {
+			return nil, fmt.Errorf("%s %s has to pay at least 1 to 1", sb, name)
+		}
+		out[hand] = pays
+	}
+	return out, nil
+}
+
+// Bet is everything a player puts down before the deal
+type Bet struct {
+	Main     int
+	SideBets map[SideBet]int
+}
+
+// Total is the main bet and every side bet
+func (b Bet) Total() int {
+	total := b.Main
+	for _, amount := range b.SideBets {
+		total += amount
+	}
+	return total
+}
+
+// SideBetOffered is true if the table takes the side bet. 21+3 needs a dealer card the players can see
+func (g *Game) SideBetOffered(sb SideBet) bool {
+	if len(g.Config.Rules.SidePayouts[sb]) == 0 {
+		return false
+	}
+	if sb == SIDE_BET_21_PLUS_3 && g.Config.Variant.UpCards() == 0 {
+		return false
+	}
+	return true
+}
+
+// validateSideBets checks the side bets against what the table offers. A side bet can't be
+// more than the main bet
+func (g *Game) validateSideBets(bet Bet) error {
+	for sb, amount := range bet.SideBets {
+		if amount == 0 {
+			continue
+		}
+		if amount < 0 {
+			return &BetError{Reason: BET_INVALID, Bet: amount, SideBet: sb}
+		}
+		if !g.SideBetOffered(sb) {
+			return &BetError{Reason: BET_SIDE_NOT_OFFERED, Bet: amount, SideBet: sb}
+		}
+		if amount > bet.Main {
+			return &BetError{Reason: BET_SIDE_OVER_MAIN, Bet: amount, SideBet: sb}
+		}
+	}
+	return nil
+}
+
+// settleSideBets pays every side bet on the cards that were just dealt
+func (g *Game) settleSideBets() {
+	for _, p := range g.activePlayers {
+		for _, sb := range SideBets {
+			amount := p.SideBets[sb]
+			if amount == 0 {
+				continue
+			}
+			hand := g.sideHand(sb, p.Hands[0])
+			payout := 0
+			if hand != SIDE_HAND_NONE {
+				payout = amount * (g.Config.Rules.SidePayouts[sb][hand] + 1)
+			}
+			g.pay(p, payout)
+			p.SideBetResults = append(p.SideBetResults, store.SideBetResult{Name: string(sb), Bet: amount, Payout: payout})
+			g.emit(SideBetResolved{PlayerID: p.ID, SideBet: sb, Amount: amount, Hand: hand, Payout: payout})
+		}
+	}
+}
+
+// sideHand is the best hand the side bet made that the table pays on
+func (g *Game) sideHand(sb SideBet, h *Hand) SideHand {
+	cards := slices.Clone(h.Cards[:2])
+	if sb == SIDE_BET_21_PLUS_3 {
+		cards = append(cards, g.DealerHand.Cards[0])
+	}
+	for _, hand := range sideHands[sb] {
+		if g.Config.Rules.SidePayouts[sb][hand] > 0 && makesSideHand(hand, cards) {
+			return hand
+		}
+	}
+	return SIDE_HAND_NONE
+}
+
+func makesSideHand(hand SideHand, cards []Card) bool {
+	sameRank := true
+	for _, c := range cards[1:] {
+		sameRank = sameRank && c.Rank == cards[0].Rank
+	}
+	switch hand {
+	case PERFECT_PAIR, SUITED_TRIPS:
+		return sameRank && suited(cards)
+	case COLORED_PAIR:
+		return sameRank && redSuit(cards[0].Suit) == redSuit(cards[1].Suit)
+	case MIXED_PAIR, THREE_OF_A_KIND:
+		return sameRank
+	case STRAIGHT_FLUSH:
+		return straight(cards) && suited(cards)
+	case STRAIGHT:
+		return straight(cards)
+	case FLUSH:
+		return suited(cards)
+	}
+	return false
+}
+
+func redSuit(s suit) bool {
+	return s == "heart" || s == "diamond"
+}
+
+// straight is true if the ranks run in order. Aces are high or low but don't wrap around
+func straight(cards []Card) bool {
+	ranks := []int{}
+	for _, c := range cards {
+		ranks = append(ranks, int(c.Rank))
+	}
+	slices.Sort(ranks)
+	if ranks[0] == int(ACE) && ranks[1] == int(QUEEN) {
+		// Q-K-A
+		ranks = append(ranks[1:], int(KING)+1)
+	}
+	for i := 1; i < len(ranks); i++ {
+		if ranks[i] != ranks[i-1]+1 {
+			return false
+		}
+	}
+	return true
+}
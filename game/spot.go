@@ -0,0 +1,89 @@
+package game
+
+import (
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+// A player can play more than one spot at the table. Every spot is a seat of its own with its
+// own bets and hands, but they all belong to the player who took them and play from their wallet
+
+// Spots are the seats a player is playing, in seat order
+func (g *Game) Spots(owner uuid.UUID) []*Player {
+	spots := []*Player{}
+	for _, p := range g.Players {
+		if p != nil && p.OwnerID() == owner {
+			spots = append(spots, p)
+		}
+	}
+	return spots
+}
+
+// AddSpot seats another spot for whoever is playing p. It sits out until it gets a bet
+func (g *Game) AddSpot(p *Player) (*Player, error) {
+	if p == nil || g.GetPlayer(p.ID) == nil {
+		return nil, fmt.Errorf("Sit down before taking another spot")
+	}
+	if len(g.Spots(p.OwnerID())) >= g.Config.Rules.MaxSpots {
+		return nil, fmt.Errorf("You can only play %d spots at this table", g.Config.Rules.MaxSpots)
+	}
+	spot := NewPlayer(uuid.New(), p.Wallet)
+	spot.Owner = p.OwnerID()
+	spot.Name = p.Name
+	spot.Bot = p.Bot
+	err := g.AddPlayer(spot)
+	if err != nil {
+		return nil, err
+	}
+	return spot, nil
+}
+
+// RemoveSpot gives up a spot the player took. The player's first spot goes when they leave the table
+func (g *Game) RemoveSpot(spot *Player) error {
+	if spot == nil || g.GetPlayer(spot.ID) == nil {
+		return fmt.Errorf("Spot is not at this table")
+	}
+	if spot.ID == spot.OwnerID() {
+		return fmt.Errorf("Leave the table to give up your first spot")
+	}
+	if spot.Bet > 0 {
+		return fmt.Errorf("You can't give up a spot with a bet on it")
+	}
+	return g.RemovePlayer(spot.ID)
+}
+
+// ActingSpot is the player's spot that the game is waiting on. Outside of a decision it is their first spot
+func (g *Game) ActingSpot(owner uuid.UUID) *Player {
+	spots := g.Spots(owner)
+	if len(spots) == 0 {
+		return nil
+	}
+	switch g.State {
+	case WAITING_FOR_BETS:
+		for _, p := range spots {
+			if p.Bet == 0 {
+				return p
+			}
+		}
+	case INSURANCE:
+		for _, p := range spots {
+			if p.IsActive() && !p.InsuranceDecided {
+				return p
+			}
+		}
+	case PLAYER_TURN:
+		if p := g.CurrentPlayer(); p.OwnerID() == owner {
+			return p
+		}
+	}
+	return spots[0]
+}
+
+// pay moves money in or out of the player's wallet. Every spot the owner plays sees the same wallet
+func (g *Game) pay(p *Player, amount int) {
+	p.Wallet += amount
+	for _, spot := range g.Spots(p.OwnerID()) {
+		spot.Wallet = p.Wallet
+	}
+}
@@ -0,0 +1,208 @@
+package game
+
+import "fmt"
+
+// Basic strategy is the play that loses the least for every hand against every dealer up card.
+// It follows the usual multi-deck charts and adjusts for the table's soft 17, double after split,
+// surrender and no hole card rules. Insurance and even money are never taken.
+
+// Allowed is which of the optional plays a hand could make right now. Hitting and standing always are
+type Allowed struct {
+	Double    bool
+	Split     bool
+	Surrender bool
+}
+
+// BasicStrategy is the best play for a hand against the dealer's up card. It only
+// suggests a double, split or surrender when it is allowed
+func BasicStrategy(h *Hand, upCard Card, rules RuleSet, allowed Allowed) PlayerAction {
+	up, _ := calculateValue([]Card{upCard})
+	value, soft := calculateValue(h.Cards)
+	if rules.NoHoleCard && up >= 10 {
+		// the dealer could still turn up a blackjack and take the extra money as well
+		allowed.Double = false
+		allowed.Split = allowed.Split && h.Cards[0].Rank == ACE && up == 10
+	}
+	switch {
+	case allowed.Surrender && rules.Surrender != NO_SURRENDER && shouldSurrender(h, value, soft, up, rules):
+		return ACTION_SURRENDER
+	case allowed.Split && h.IsPair() && shouldSplit(h, up, rules):
+		return ACTION_SPLIT
+	case soft:
+		return softTotal(value, up, !rules.StandOnSoft17, allowed.Double)
+	}
+	return hardTotal(value, up, !rules.StandOnSoft17, allowed.Double)
+}
+
+func shouldSurrender(h *Hand, value int, soft bool, up int, rules RuleSet) bool {
+	if soft {
+		return false
+	}
+	eights := h.IsPair() && value == 16
+	if rules.Surrender == EARLY_SURRENDER {
+		// nothing is known about the hole card yet, so a lot more gives up against a ten or an ace
+		switch up {
+		case 11:
+			return (value >= 5 && value <= 7) || (value >= 12 && value <= 17)
+		case 10:
+			return value >= 14 && value <= 16
+		}
+	}
+	switch up {
+	case 9:
+		return value == 16 && !eights
+	case 10:
+		return value == 15 || (value == 16 && !eights)
+	case 11:
+		if !rules.StandOnSoft17 {
+			return value >= 15 && value <= 17
+		}
+		return value == 16 && !eights
+	}
+	return false
+}
+
+func shouldSplit(h *Hand, up int, rules RuleSet) bool {
+	pair, _ := calculateValue(h.Cards[:1])
+	das := rules.DoubleAfterSplit
+	switch pair {
+	case 11, 8:
+		return true
+	case 9:
+		return up <= 9 && up != 7
+	case 7:
+		return up <= 7
+	case 6:
+		return (up >= 3 && up <= 6) || (das && up == 2)
+	case 4:
+		return das && (up == 5 || up == 6)
+	case 3, 2:
+		return (up >= 4 && up <= 7) || (das && up <= 3)
+	}
+	// tens and fives are played as 20 and 10
+	return false
+}
+
+func softTotal(value, up int, h17, canDouble bool) PlayerAction {
+	double := func(otherwise PlayerAction) PlayerAction {
+		if canDouble {
+			return ACTION_DOUBLE
+		}
+		return otherwise
+	}
+	switch {
+	case value >= 20:
+		return ACTION_STAND
+	case value == 19:
+		if h17 && up == 6 {
+			return double(ACTION_STAND)
+		}
+		return ACTION_STAND
+	case value == 18:
+		if (up >= 3 && up <= 6) || (h17 && up == 2) {
+			return double(ACTION_STAND)
+		}
+		if up <= 8 {
+			return ACTION_STAND
+		}
+		return ACTION_HIT
+	case value == 17:
+		if up >= 3 && up <= 6 {
+			return double(ACTION_HIT)
+		}
+	case value >= 15:
+		if up >= 4 && up <= 6 {
+			return double(ACTION_HIT)
+		}
+	case value >= 13:
+		if up == 5 || up == 6 {
+			return double(ACTION_HIT)
+		}
+	}
+	return ACTION_HIT
+}
+
+func hardTotal(value, up int, h17, canDouble bool) PlayerAction {
+	double := ACTION_HIT
+	if canDouble {
+		double = ACTION_DOUBLE
+	}
+	switch {
+	case value >= 17:
+		return ACTION_STAND
+	case value >= 13:
+		if up <= 6 {
+			return ACTION_STAND
+		}
+	case value == 12:
+		if up >= 4 && up <= 6 {
+			return ACTION_STAND
+		}
+	case value == 11:
+		if up != 11 || h17 {
+			return double
+		}
+	case value == 10:
+		if up <= 9 {
+			return double
+		}
+	case value == 9:
+		if up >= 3 && up <= 6 {
+			return double
+		}
+	}
+	return ACTION_HIT
+}
+
+// Advise is basic strategy's play for the decision a player has in front of them. During the
+// insurance window that is either an early surrender or declining insurance
+func (g *Game) Advise(p *Player) (PlayerAction, error) {
+	if !g.charted() {
+		return "", fmt.Errorf("Basic strategy is only charted for classic blackjack")
+	}
+	err := g.checkDecision(p)
+	if err != nil {
+		return "", err
+	}
+	upCard := g.DealerHand.Cards[0]
+	switch g.State {
+	case INSURANCE:
+		hand := p.Hands[0]
+		if g.CanSurrender(p) && BasicStrategy(hand, upCard, g.Config.Rules, Allowed{Surrender: true}) == ACTION_SURRENDER {
+			return ACTION_SURRENDER, nil
+		}
+		return ACTION_DECLINE_INSURANCE, nil
+	case PLAYER_TURN:
+		hand := g.CurrentHand()
+		rules := g.Config.Rules
+		if rules.Surrender == EARLY_SURRENDER && !rules.NoHoleCard {
+			// the dealer has peeked by now. What is left is a late surrender
+			rules.Surrender = LATE_SURRENDER
+		}
+		allowed := Allowed{Double: g.CanDouble(p), Split: g.CanSplit(p), Surrender: g.CanSurrender(p)}
+		action := BasicStrategy(hand, upCard, rules, allowed)
+		if !g.canHit(hand) && action != ACTION_SPLIT {
+			// split aces can't take another card
+			return ACTION_STAND, nil
+		}
+		return action, nil
+	}
+	return "", fmt.Errorf("There is nothing to decide right now")
+}
+
+// charted is true when the charts fit the table. They are worked out for classic blackjack only
+func (g *Game) charted() bool {
+	return g.Config.Variant.Name() == VARIANT_CLASSIC
+}
+
+// advice is Advise for the events. Empty when there was no decision to make or the game made it
+func (g *Game) advice(p *Player) PlayerAction {
+	if g.automatic {
+		return ""
+	}
+	action, err := g.Advise(p)
+	if err != nil {
+		return ""
+	}
+	return action
+}
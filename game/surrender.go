@@ -0,0 +1,123 @@
+package game
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Surrender gives up a hand for half of its bet. It is only allowed as the first decision on
+// the two cards that were dealt. Late surrender happens after the dealer peeks for blackjack.
+// Early surrender is offered before the peek, during the same window as insurance, so it
+// also saves half the bet against a dealer blackjack.
+
+type SurrenderRule int
+
+const (
+	NO_SURRENDER SurrenderRule = iota
+	LATE_SURRENDER
+	EARLY_SURRENDER
+)
+
+func (sr SurrenderRule) String() string {
+	switch sr {
+	case NO_SURRENDER:
+		return "none"
+	case LATE_SURRENDER:
+		return "late"
+	case EARLY_SURRENDER:
+		return "early"
+	}
+	return ""
+}
+
+func ParseSurrenderRule(s string) (SurrenderRule, error) {
+	switch strings.ToLower(s) {
+	case "", "none":
+		return NO_SURRENDER, nil
+	case "late":
+		return LATE_SURRENDER, nil
+	case "early":
+		return EARLY_SURRENDER, nil
+	}
+	return NO_SURRENDER, fmt.Errorf("unknown surrender rule %q", s)
+}
+
+func (g *Game) Surrender(p *Player) error {
+	err := g.checkStates("Surrender", INSURANCE, PLAYER_TURN)
+	if err != nil {
+		return err
+	}
+	err = g.checkSurrender(p)
+	if err != nil {
+		return err
+	}
+	advice := g.advice(p)
+	p.Hands[0].Surrendered = true
+	g.emit(PlayerActed{PlayerID: p.ID, Hand: 0, Action: ACTION_SURRENDER, Advice: advice})
+	if g.State == INSURANCE {
+		// surrendering is this player's decision for the early window
+		p.InsuranceDecided = true
+		return nil
+	}
+	g.endHand(p)
+	return nil
+}
+
+// CanSurrender is true if the player could surrender their hand right now
+func (g *Game) CanSurrender(p *Player) bool {
+	return g.checkSurrender(p) == nil
+}
+
+func (g *Game) checkSurrender(p *Player) error {
+	if g.Config.Rules.Surrender == NO_SURRENDER {
+		return fmt.Errorf("Surrender is not allowed at this table")
+	}
+	if p == nil || len(p.Hands) == 0 || !p.IsActive() {
+		return fmt.Errorf("You are not playing this round")
+	}
+	switch g.State {
+	case INSURANCE:
+		if g.Config.Rules.Surrender != EARLY_SURRENDER {
+			return fmt.Errorf("You can only surrender after the dealer checks for blackjack")
+		}
+		if p.InsuranceDecided {
+			return fmt.Errorf("You already made your decision")
+		}
+	case PLAYER_TURN:
+		if p != g.CurrentPlayer() {
+			return fmt.Errorf("It is not Player %d's turn", p.ID)
+		}
+	default:
+		return fmt.Errorf("You can't surrender right now")
+	}
+	hand := p.Hands[0]
+	if len(p.Hands) > 1 || hand.Split || len(hand.Cards) != 2 {
+		return fmt.Errorf("You can only surrender as your first decision")
+	}
+	if hand.Surrendered {
+		return fmt.Errorf("You already surrendered")
+	}
+	if hand.GetState() == BLACKJACK {
+		return fmt.Errorf("You can't surrender a blackjack")
+	}
+	return nil
+}
+
+// earlySurrenderOffered is true when the dealer shows a ten and the table lets players
+// surrender before the peek. An ace opens the insurance window anyway
+func (g *Game) earlySurrenderOffered() bool {
+	if g.Config.Rules.Surrender != EARLY_SURRENDER || g.Config.Rules.NoHoleCard || g.Config.Variant.UpCards() == 0 {
+		// opening the window would give away a face down ten
+		return false
+	}
+	upValue, _ := calculateValue(g.DealerHand.Cards[:1])
+	return upValue == 10
+}
+
+func (g *Game) calculateSurrenderPayout(h *Hand) int {
+	if g.Config.Rules.NoHoleCard && g.Config.Rules.Surrender == LATE_SURRENDER && g.DealerHand.GetState() == BLACKJACK {
+		// without a hole card the dealer blackjack only shows up after a late surrender
+		return 0
+	}
+	return h.Bet / 2
+}
@@ -0,0 +1,200 @@
+package game
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// A variant is a family of blackjack played with its own shoe, its own view of the dealer's
+// cards and its own payouts. The table's RuleSet still applies on top of it.
+//
+// Spanish 21 deals from decks with the tens taken out. A player blackjack or 21 always wins and
+// a 21 made with five or more cards, 6-7-8 or 7-7-7 pays a bonus unless the hand was doubled.
+// Pontoon deals both of the dealer's cards face down. A pontoon or a five card trick pays 2:1
+// and the dealer wins every tie.
+// Double Exposure deals both of the dealer's cards face up. Blackjack pays even money, there is
+// no insurance and the dealer wins every tie.
+
+const (
+	VARIANT_CLASSIC         = "classic"
+	VARIANT_SPANISH_21      = "spanish21"
+	VARIANT_PONTOON         = "pontoon"
+	VARIANT_DOUBLE_EXPOSURE = "double_exposure"
+)
+
+type Variant interface {
+	Name() string
+	// Deck is a single deck in its unshuffled order
+	Deck() []Card
+	// UpCards is how many of the dealer's first two cards are dealt face up
+	UpCards() int
+	// Payout is what a hand that didn't bust, surrender or take even money gets back, bet included
+	Payout(h, dealer *Hand, rules RuleSet) int
+}
+
+func ParseVariant(s string) (Variant, error) {
+	switch strings.ToLower(s) {
+	case "", VARIANT_CLASSIC:
+		return Classic{}, nil
+	case VARIANT_SPANISH_21:
+		return Spanish21{}, nil
+	case VARIANT_PONTOON:
+		return Pontoon{}, nil
+	case VARIANT_DOUBLE_EXPOSURE:
+		return DoubleExposure{}, nil
+	}
+	return Classic{}, fmt.Errorf("unknown variant %q", s)
+}
+
+func standardDeck() []Card {
+	cards := make([]Card, 0, 52)
+	for _, s := range []suit{"club", "diamond", "heart", "spade"} {
+		for _, v := range []cardRank{2, 3, 4, 5, 6, 7, 8, 9, 10, JACK, QUEEN, KING, ACE} {
+			cards = append(cards, Card{Suit: s, Rank: v})
+		}
+	}
+	return cards
+}
+
+type Classic struct{}
+
+func (Classic) Name() string { return VARIANT_CLASSIC }
+func (Classic) Deck() []Card { return standardDeck() }
+func (Classic) UpCards() int { return 1 }
+
+func (Classic) Payout(h, dealer *Hand, rules RuleSet) int {
+	pVal := h.GetValue()
+	pState := h.GetState()
+	dVal := dealer.GetValue()
+	dState := dealer.GetState()
+	if pState == BLACKJACK && dState == BLACKJACK {
+		return h.Bet
+	}
+	if pVal == dVal && dState != BLACKJACK {
+		if pState == BLACKJACK && dState != BLACKJACK {
+			return rules.BlackjackPayout.Pay(h.Bet) + h.Bet
+		}
+		return h.Bet // push
+	}
+
+	// Win Conditions. Player > dealer. Player is live when dealer busts. BlackJack, but not if dealer also gets blackjack
+	if pVal > dVal || dState == BUST {
+		if pState == BLACKJACK {
+			// player won with blackjack
+			return rules.BlackjackPayout.Pay(h.Bet) + h.Bet
+		}
+		// player won regular style
+		return h.Bet * 2
+	}
+	// player did not win
+	return 0
+}
+
+type Spanish21 struct{}
+
+func (Spanish21) Name() string { return VARIANT_SPANISH_21 }
+
+func (Spanish21) Deck() []Card {
+	cards := []Card{}
+	for _, c := range standardDeck() {
+		if c.Rank != 10 {
+			cards = append(cards, c)
+		}
+	}
+	return cards
+}
+
+func (Spanish21) UpCards() int { return 1 }
+
+func (Spanish21) Payout(h, dealer *Hand, rules RuleSet) int {
+	pVal := h.GetValue()
+	switch {
+	case h.GetState() == BLACKJACK:
+		// beats a dealer blackjack too
+		return rules.BlackjackPayout.Pay(h.Bet) + h.Bet
+	case dealer.GetState() == BLACKJACK:
+		return 0
+	case pVal == 21:
+		return h.Bet + spanish21Bonus(h)
+	case dealer.GetState() == BUST || pVal > dealer.GetValue():
+		return h.Bet * 2
+	case pVal == dealer.GetValue():
+		return h.Bet
+	}
+	return 0
+}
+
+// spanish21Bonus is what a 21 wins. Doubled hands only ever get even money
+func spanish21Bonus(h *Hand) int {
+	even := h.Bet
+	if h.Doubled {
+		return even
+	}
+	switch n := len(h.Cards); {
+	case n >= 7:
+		return h.Bet * 3
+	case n == 6:
+		return h.Bet * 2
+	case n == 5:
+		return PAYOUT_3_2.Pay(h.Bet)
+	case n == 3:
+		ranks := []cardRank{h.Cards[0].Rank, h.Cards[1].Rank, h.Cards[2].Rank}
+		slices.Sort(ranks)
+		if !slices.Equal(ranks, []cardRank{6, 7, 8}) && !slices.Equal(ranks, []cardRank{7, 7, 7}) {
+			return even
+		}
+		suited := h.Cards[0].Suit == h.Cards[1].Suit && h.Cards[1].Suit == h.Cards[2].Suit
+		switch {
+		case suited && h.Cards[0].Suit == "spade":
+			return h.Bet * 3
+		case suited:
+			return h.Bet * 2
+		}
+		return PAYOUT_3_2.Pay(h.Bet)
+	}
+	return even
+}
+
+type Pontoon struct{}
+
+func (Pontoon) Name() string { return VARIANT_PONTOON }
+func (Pontoon) Deck() []Card { return standardDeck() }
+func (Pontoon) UpCards() int { return 0 }
+
+func (Pontoon) Payout(h, dealer *Hand, rules RuleSet) int {
+	pVal := h.GetValue()
+	dState := dealer.GetState()
+	switch {
+	case dState == BLACKJACK:
+		// the dealer's pontoon beats everything, a player's pontoon included
+		return 0
+	case h.GetState() == BLACKJACK || len(h.Cards) >= 5:
+		// pontoon or a five card trick
+		return h.Bet * 3
+	case dState == BUST || pVal > dealer.GetValue():
+		return h.Bet * 2
+	}
+	return 0
+}
+
+type DoubleExposure struct{}
+
+func (DoubleExposure) Name() string { return VARIANT_DOUBLE_EXPOSURE }
+func (DoubleExposure) Deck() []Card { return standardDeck() }
+func (DoubleExposure) UpCards() int { return 2 }
+
+func (DoubleExposure) Payout(h, dealer *Hand, rules RuleSet) int {
+	pVal := h.GetValue()
+	dState := dealer.GetState()
+	switch {
+	case dState == BLACKJACK:
+		return 0
+	case h.GetState() == BLACKJACK:
+		// blackjack only pays even money
+		return h.Bet * 2
+	case dState == BUST || pVal > dealer.GetValue():
+		return h.Bet * 2
+	}
+	return 0
+}
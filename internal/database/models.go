@@ -8,19 +8,62 @@ import (
 	"time"
 )
 
+type Jackpot struct {
+	ID        int64
+	Amount    int64
+	UpdatedAt time.Time
+}
+
+type Round struct {
+	ID          int64
+	TableID     string
+	RoundNumber int64
+	ShoeSeed    string
+	DealerHand  string
+	Events      string
+	PlayedAt    time.Time
+}
+
+type RoundPlayer struct {
+	RoundID  int64
+	GithubID string
+	Seat     int64
+	Bet      int64
+	Payout   int64
+	Hands    string
+}
+
+type TournamentResult struct {
+	ID         int64
+	Tournament string
+	StartedAt  time.Time
+	GithubID   string
+	Place      int64
+	Chips      int64
+	Prize      int64
+}
+
 type User struct {
-	GithubID           string
-	CreatedAt          time.Time
-	UpdatedAt          time.Time
-	Wallet             int64
-	AmountBetLifetime  int64
-	AmountWonLifetime  int64
-	AmountLostLifetime int64
-	HandsPlayed        int64
-	HandsWon           int64
-	HandsLost          int64
-	GithubStarred      bool
-	LastLogin          time.Time
-	LoginStreak        int64
-	Blackjacks         int64
+	GithubID            string
+	CreatedAt           time.Time
+	UpdatedAt           time.Time
+	Wallet              int64
+	AmountBetLifetime   int64
+	AmountWonLifetime   int64
+	AmountLostLifetime  int64
+	HandsPlayed         int64
+	HandsWon            int64
+	HandsLost           int64
+	GithubStarred       bool
+	LastLogin           time.Time
+	LoginStreak         int64
+	Blackjacks          int64
+	HandsSurrendered    int64
+	StrategyDecisions   int64
+	StrategyDeviations  int64
+	CountQuizzes        int64
+	CountQuizzesCorrect int64
+	SideBets            int64
+	SideBetsWon         int64
+	Timeouts            int64
 }
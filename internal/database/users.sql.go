@@ -13,7 +13,7 @@ import (
 const createUser = `-- name: CreateUser :one
 INSERT INTO users(github_id, created_at, updated_at, last_login)
 VALUES (?, ?, ?, ?)
-RETURNING github_id, created_at, updated_at, wallet, amount_bet_lifetime, amount_won_lifetime, amount_lost_lifetime, hands_played, hands_won, hands_lost, github_starred, last_login, login_streak, blackjacks
+RETURNING github_id, created_at, updated_at, wallet, amount_bet_lifetime, amount_won_lifetime, amount_lost_lifetime, hands_played, hands_won, hands_lost, github_starred, last_login, login_streak, blackjacks, hands_surrendered, strategy_decisions, strategy_deviations, count_quizzes, count_quizzes_correct, side_bets, side_bets_won, timeouts
 `
 
 type CreateUserParams struct {
@@ -46,12 +46,20 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, e
 		&i.LastLogin,
 		&i.LoginStreak,
 		&i.Blackjacks,
+		&i.HandsSurrendered,
+		&i.StrategyDecisions,
+		&i.StrategyDeviations,
+		&i.CountQuizzes,
+		&i.CountQuizzesCorrect,
+		&i.SideBets,
+		&i.SideBetsWon,
+		&i.Timeouts,
 	)
 	return i, err
 }
 
 const getUserByUsername = `-- name: GetUserByUsername :one
-select github_id, created_at, updated_at, wallet, amount_bet_lifetime, amount_won_lifetime, amount_lost_lifetime, hands_played, hands_won, hands_lost, github_starred, last_login, login_streak, blackjacks
+select github_id, created_at, updated_at, wallet, amount_bet_lifetime, amount_won_lifetime, amount_lost_lifetime, hands_played, hands_won, hands_lost, github_starred, last_login, login_streak, blackjacks, hands_surrendered, strategy_decisions, strategy_deviations, count_quizzes, count_quizzes_correct, side_bets, side_bets_won, timeouts
 from users
 where github_id = ?
 `
@@ -74,6 +82,60 @@ func (q *Queries) GetUserByUsername(ctx context.Context, githubID string) (User,
 		&i.LastLogin,
 		&i.LoginStreak,
 		&i.Blackjacks,
+		&i.HandsSurrendered,
+		&i.StrategyDecisions,
+		&i.StrategyDeviations,
+		&i.CountQuizzes,
+		&i.CountQuizzesCorrect,
+		&i.SideBets,
+		&i.SideBetsWon,
+		&i.Timeouts,
+	)
+	return i, err
+}
+
+const updateCountStats = `-- name: UpdateCountStats :one
+;
+
+UPDATE users
+SET updated_at = CURRENT_TIMESTAMP,
+count_quizzes = count_quizzes + 1,
+count_quizzes_correct = count_quizzes_correct + ?
+WHERE github_id = ?
+RETURNING github_id, created_at, updated_at, wallet, amount_bet_lifetime, amount_won_lifetime, amount_lost_lifetime, hands_played, hands_won, hands_lost, github_starred, last_login, login_streak, blackjacks, hands_surrendered, strategy_decisions, strategy_deviations, count_quizzes, count_quizzes_correct, side_bets, side_bets_won, timeouts
+`
+
+type UpdateCountStatsParams struct {
+	CountQuizzesCorrect int64
+	GithubID            string
+}
+
+func (q *Queries) UpdateCountStats(ctx context.Context, arg UpdateCountStatsParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, updateCountStats, arg.CountQuizzesCorrect, arg.GithubID)
+	var i User
+	err := row.Scan(
+		&i.GithubID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Wallet,
+		&i.AmountBetLifetime,
+		&i.AmountWonLifetime,
+		&i.AmountLostLifetime,
+		&i.HandsPlayed,
+		&i.HandsWon,
+		&i.HandsLost,
+		&i.GithubStarred,
+		&i.LastLogin,
+		&i.LoginStreak,
+		&i.Blackjacks,
+		&i.HandsSurrendered,
+		&i.StrategyDecisions,
+		&i.StrategyDeviations,
+		&i.CountQuizzes,
+		&i.CountQuizzesCorrect,
+		&i.SideBets,
+		&i.SideBetsWon,
+		&i.Timeouts,
 	)
 	return i, err
 }
@@ -84,7 +146,7 @@ UPDATE users
 SET updated_at = CURRENT_TIMESTAMP,
 github_starred = ?
 WHERE github_id = ?
-RETURNING github_id, created_at, updated_at, wallet, amount_bet_lifetime, amount_won_lifetime, amount_lost_lifetime, hands_played, hands_won, hands_lost, github_starred, last_login, login_streak, blackjacks
+RETURNING github_id, created_at, updated_at, wallet, amount_bet_lifetime, amount_won_lifetime, amount_lost_lifetime, hands_played, hands_won, hands_lost, github_starred, last_login, login_streak, blackjacks, hands_surrendered, strategy_decisions, strategy_deviations, count_quizzes, count_quizzes_correct, side_bets, side_bets_won, timeouts
 `
 
 type UpdateGithubStarredParams struct {
@@ -110,6 +172,14 @@ func (q *Queries) UpdateGithubStarred(ctx context.Context, arg UpdateGithubStarr
 		&i.LastLogin,
 		&i.LoginStreak,
 		&i.Blackjacks,
+		&i.HandsSurrendered,
+		&i.StrategyDecisions,
+		&i.StrategyDeviations,
+		&i.CountQuizzes,
+		&i.CountQuizzesCorrect,
+		&i.SideBets,
+		&i.SideBetsWon,
+		&i.Timeouts,
 	)
 	return i, err
 }
@@ -122,7 +192,7 @@ SET updated_at = CURRENT_TIMESTAMP,
 last_login = CURRENT_TIMESTAMP,
 login_streak = ?
 WHERE github_id = ?
-RETURNING github_id, created_at, updated_at, wallet, amount_bet_lifetime, amount_won_lifetime, amount_lost_lifetime, hands_played, hands_won, hands_lost, github_starred, last_login, login_streak, blackjacks
+RETURNING github_id, created_at, updated_at, wallet, amount_bet_lifetime, amount_won_lifetime, amount_lost_lifetime, hands_played, hands_won, hands_lost, github_starred, last_login, login_streak, blackjacks, hands_surrendered, strategy_decisions, strategy_deviations, count_quizzes, count_quizzes_correct, side_bets, side_bets_won, timeouts
 `
 
 type UpdateLoginStreakParams struct {
@@ -148,6 +218,60 @@ func (q *Queries) UpdateLoginStreak(ctx context.Context, arg UpdateLoginStreakPa
 		&i.LastLogin,
 		&i.LoginStreak,
 		&i.Blackjacks,
+		&i.HandsSurrendered,
+		&i.StrategyDecisions,
+		&i.StrategyDeviations,
+		&i.CountQuizzes,
+		&i.CountQuizzesCorrect,
+		&i.SideBets,
+		&i.SideBetsWon,
+		&i.Timeouts,
+	)
+	return i, err
+}
+
+const updateStrategyStats = `-- name: UpdateStrategyStats :one
+;
+
+UPDATE users
+SET updated_at = CURRENT_TIMESTAMP,
+strategy_decisions = strategy_decisions + 1,
+strategy_deviations = strategy_deviations + ?
+WHERE github_id = ?
+RETURNING github_id, created_at, updated_at, wallet, amount_bet_lifetime, amount_won_lifetime, amount_lost_lifetime, hands_played, hands_won, hands_lost, github_starred, last_login, login_streak, blackjacks, hands_surrendered, strategy_decisions, strategy_deviations, count_quizzes, count_quizzes_correct, side_bets, side_bets_won, timeouts
+`
+
+type UpdateStrategyStatsParams struct {
+	StrategyDeviations int64
+	GithubID           string
+}
+
+func (q *Queries) UpdateStrategyStats(ctx context.Context, arg UpdateStrategyStatsParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, updateStrategyStats, arg.StrategyDeviations, arg.GithubID)
+	var i User
+	err := row.Scan(
+		&i.GithubID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Wallet,
+		&i.AmountBetLifetime,
+		&i.AmountWonLifetime,
+		&i.AmountLostLifetime,
+		&i.HandsPlayed,
+		&i.HandsWon,
+		&i.HandsLost,
+		&i.GithubStarred,
+		&i.LastLogin,
+		&i.LoginStreak,
+		&i.Blackjacks,
+		&i.HandsSurrendered,
+		&i.StrategyDecisions,
+		&i.StrategyDeviations,
+		&i.CountQuizzes,
+		&i.CountQuizzesCorrect,
+		&i.SideBets,
+		&i.SideBetsWon,
+		&i.Timeouts,
 	)
 	return i, err
 }
@@ -160,7 +284,7 @@ SET updated_at = CURRENT_TIMESTAMP,
 last_login = CURRENT_TIMESTAMP,
 wallet = wallet + ?
 WHERE github_id = ?
-RETURNING github_id, created_at, updated_at, wallet, amount_bet_lifetime, amount_won_lifetime, amount_lost_lifetime, hands_played, hands_won, hands_lost, github_starred, last_login, login_streak, blackjacks
+RETURNING github_id, created_at, updated_at, wallet, amount_bet_lifetime, amount_won_lifetime, amount_lost_lifetime, hands_played, hands_won, hands_lost, github_starred, last_login, login_streak, blackjacks, hands_surrendered, strategy_decisions, strategy_deviations, count_quizzes, count_quizzes_correct, side_bets, side_bets_won, timeouts
 `
 
 type UpdateUserAddIncomeParams struct {
@@ -186,6 +310,14 @@ func (q *Queries) UpdateUserAddIncome(ctx context.Context, arg UpdateUserAddInco
 		&i.LastLogin,
 		&i.LoginStreak,
 		&i.Blackjacks,
+		&i.HandsSurrendered,
+		&i.StrategyDecisions,
+		&i.StrategyDeviations,
+		&i.CountQuizzes,
+		&i.CountQuizzesCorrect,
+		&i.SideBets,
+		&i.SideBetsWon,
+		&i.Timeouts,
 	)
 	return i, err
 }
@@ -195,16 +327,19 @@ const updateUserStats = `-- name: UpdateUserStats :one
 
 UPDATE users
 SET updated_at = CURRENT_TIMESTAMP,
-wallet = ?,
+wallet = wallet + ?,
 amount_bet_lifetime = amount_bet_lifetime + ?,
 amount_won_lifetime = amount_won_lifetime + ?,
 amount_lost_lifetime = amount_lost_lifetime + ?,
 hands_played = hands_played + 1,
 hands_won = hands_won + ?,
 hands_lost = hands_lost + ?,
-blackjacks = blackjacks + ?
+hands_surrendered = hands_surrendered + ?,
+blackjacks = blackjacks + ?,
+side_bets = side_bets + ?,
+side_bets_won = side_bets_won + ?
 WHERE github_id = ?
-RETURNING github_id, created_at, updated_at, wallet, amount_bet_lifetime, amount_won_lifetime, amount_lost_lifetime, hands_played, hands_won, hands_lost, github_starred, last_login, login_streak, blackjacks
+RETURNING github_id, created_at, updated_at, wallet, amount_bet_lifetime, amount_won_lifetime, amount_lost_lifetime, hands_played, hands_won, hands_lost, github_starred, last_login, login_streak, blackjacks, hands_surrendered, strategy_decisions, strategy_deviations, count_quizzes, count_quizzes_correct, side_bets, side_bets_won, timeouts
 `
 
 type UpdateUserStatsParams struct {
@@ -214,7 +349,10 @@ type UpdateUserStatsParams struct {
 	AmountLostLifetime int64
 	HandsWon           int64
 	HandsLost          int64
+	HandsSurrendered   int64
 	Blackjacks         int64
+	SideBets           int64
+	SideBetsWon        int64
 	GithubID           string
 }
 
@@ -226,7 +364,10 @@ func (q *Queries) UpdateUserStats(ctx context.Context, arg UpdateUserStatsParams
 		arg.AmountLostLifetime,
 		arg.HandsWon,
 		arg.HandsLost,
+		arg.HandsSurrendered,
 		arg.Blackjacks,
+		arg.SideBets,
+		arg.SideBetsWon,
 		arg.GithubID,
 	)
 	var i User
@@ -245,6 +386,54 @@ func (q *Queries) UpdateUserStats(ctx context.Context, arg UpdateUserStatsParams
 		&i.LastLogin,
 		&i.LoginStreak,
 		&i.Blackjacks,
+		&i.HandsSurrendered,
+		&i.StrategyDecisions,
+		&i.StrategyDeviations,
+		&i.CountQuizzes,
+		&i.CountQuizzesCorrect,
+		&i.SideBets,
+		&i.SideBetsWon,
+		&i.Timeouts,
+	)
+	return i, err
+}
+
+const updateTimeoutStats = `-- name: UpdateTimeoutStats :one
+;
+
+UPDATE users
+SET updated_at = CURRENT_TIMESTAMP,
+timeouts = timeouts + 1
+WHERE github_id = ?
+RETURNING github_id, created_at, updated_at, wallet, amount_bet_lifetime, amount_won_lifetime, amount_lost_lifetime, hands_played, hands_won, hands_lost, github_starred, last_login, login_streak, blackjacks, hands_surrendered, strategy_decisions, strategy_deviations, count_quizzes, count_quizzes_correct, side_bets, side_bets_won, timeouts
+`
+
+func (q *Queries) UpdateTimeoutStats(ctx context.Context, githubID string) (User, error) {
+	row := q.db.QueryRowContext(ctx, updateTimeoutStats, githubID)
+	var i User
+	err := row.Scan(
+		&i.GithubID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Wallet,
+		&i.AmountBetLifetime,
+		&i.AmountWonLifetime,
+		&i.AmountLostLifetime,
+		&i.HandsPlayed,
+		&i.HandsWon,
+		&i.HandsLost,
+		&i.GithubStarred,
+		&i.LastLogin,
+		&i.LoginStreak,
+		&i.Blackjacks,
+		&i.HandsSurrendered,
+		&i.StrategyDecisions,
+		&i.StrategyDeviations,
+		&i.CountQuizzes,
+		&i.CountQuizzesCorrect,
+		&i.SideBets,
+		&i.SideBetsWon,
+		&i.Timeouts,
 	)
 	return i, err
 }
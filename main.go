@@ -1,13 +1,26 @@
 package main
 
 import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
+	"io"
+	"log/slog"
+	"math"
 	"os"
+	"runtime"
+	"text/tabwriter"
+	"time"
 
 	"github.com/alecthomas/kong"
 	"github.com/dylanmccormick/blackjack-tui/client"
+	"github.com/dylanmccormick/blackjack-tui/protocol"
 	"github.com/dylanmccormick/blackjack-tui/server"
+	"github.com/dylanmccormick/blackjack-tui/sim"
+	"github.com/dylanmccormick/blackjack-tui/store"
 )
 
 var CLI struct {
@@ -15,6 +28,23 @@ var CLI struct {
 		Mock bool `help:"Run in mock mode"`
 	} `cmd:"Run the blackjack TUI"`
 	Server struct{} `cmd:"Run the blackjack Server"`
+	Verify struct {
+		History string `arg:"" type:"existingfile" help:"Hand history JSON saved by the TUI"`
+		Seed    string `help:"Revealed shoe seed. Defaults to the seed saved in the history"`
+	} `cmd:"Check a shoe's hand history against its revealed seed"`
+	Replay struct {
+		Round int64         `arg:"" help:"Id of the recorded round"`
+		DB    string        `name:"db" type:"existingfile" env:"SQLITE_DB" required:"" help:"SQLite file with the hand history. A copy of the server's works"`
+		Speed time.Duration `default:"1s" help:"Time between each card or decision"`
+	} `cmd:"Play back a recorded round"`
+	Simulate struct {
+		Rules    string `default:"config.yaml" type:"path" help:"Config file with the table rules. Same format as the server's config.yaml"`
+		Hands    int    `default:"1000000" help:"Rounds to play"`
+		Strategy string `default:"basic" enum:"basic,never_bust,random" help:"How the player decides (basic, never_bust or random)"`
+		Workers  int    `help:"Goroutines to play on. Defaults to one per CPU"`
+		Format   string `default:"table" enum:"table,json" help:"Output format (table or json)"`
+		Seed     uint64 `help:"Deal the same shoes every run. Defaults to new shoes each time"`
+	} `cmd:"Play hands offline and report the house edge for a set of rules"`
 }
 
 func main() {
@@ -26,8 +56,107 @@ func main() {
 	case "server":
 		s := server.InitializeServer()
 		s.Run()
+	case "verify <history>":
+		if err := verifyShoe(CLI.Verify.History, CLI.Verify.Seed); err != nil {
+			fmt.Fprintf(os.Stderr, "Verification failed: %s\n", err)
+			os.Exit(1)
+		}
+	case "replay <round>":
+		if err := replayRound(CLI.Replay.DB, CLI.Replay.Round, CLI.Replay.Speed); err != nil {
+			fmt.Fprintf(os.Stderr, "Replay failed: %s\n", err)
+			os.Exit(1)
+		}
+	case "simulate":
+		if err := simulate(CLI.Simulate.Rules, CLI.Simulate.Hands, CLI.Simulate.Strategy, CLI.Simulate.Workers, CLI.Simulate.Format, CLI.Simulate.Seed); err != nil {
+			fmt.Fprintf(os.Stderr, "Simulation failed: %s\n", err)
+			os.Exit(1)
+		}
 	default:
 		fmt.Fprintf(os.Stderr, "Unknown command: %s\n", ctx.Command())
 		os.Exit(1)
 	}
 }
+
+func verifyShoe(path, seed string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	history := protocol.ShoeHistoryDTO{}
+	if err := json.Unmarshal(data, &history); err != nil {
+		return fmt.Errorf("Unable to read hand history: %w", err)
+	}
+	if seed == "" {
+		seed = history.Seed
+	}
+	if seed == "" {
+		return fmt.Errorf("No seed given and none saved in the history")
+	}
+	if err := protocol.VerifyShoeHistory(seed, history); err != nil {
+		return err
+	}
+	cards := 0
+	for _, r := range history.Rounds {
+		cards += len(r.Cards)
+	}
+	fmt.Printf("Shoe verified: %d rounds and %d cards match seed %s\n", len(history.Rounds), cards, seed)
+	return nil
+}
+
+func replayRound(dbPath string, id int64, speed time.Duration) error {
+	db, err := store.OpenStore(dbPath)
+	if err != nil {
+		return err
+	}
+	rr, err := db.GetRound(context.Background(), id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("No round %d in %s", id, dbPath)
+	}
+	if err != nil {
+		return err
+	}
+	round, err := protocol.RoundToDTO(rr)
+	if err != nil {
+		return fmt.Errorf("Unable to replay round %d: %w", id, err)
+	}
+	return client.RunReplay(round, speed)
+}
+
+func simulate(rulesPath string, hands int, strategy string, workers int, format string, seed uint64) error {
+	config, err := server.ReadConfig(rulesPath)
+	if err != nil {
+		return err
+	}
+	if workers < 1 {
+		workers = runtime.NumCPU()
+	}
+	// the engine logs every round. That's a lot of noise a million rounds in
+	slog.SetDefault(slog.New(slog.NewTextHandler(io.Discard, nil)))
+	start := time.Now()
+	res, err := sim.Run(sim.Config{
+		Game:     config.GameConfig(),
+		Hands:    hands,
+		Strategy: strategy,
+		Workers:  workers,
+		Seed:     seed,
+	})
+	if err != nil {
+		return err
+	}
+	if format == "json" {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(res)
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "Rules\t%s\n", res.Rules)
+	fmt.Fprintf(w, "Strategy\t%s\n", res.Strategy)
+	fmt.Fprintf(w, "Rounds\t%d (%d hands) in %s\n", res.Rounds, res.Hands, time.Since(start).Round(time.Millisecond))
+	fmt.Fprintf(w, "House edge\t%.3f%% ± %.3f%%\n", 100*res.HouseEdge, 100*res.StdDev/math.Sqrt(float64(res.Rounds)))
+	fmt.Fprintf(w, "Variance\t%.4f (std dev %.4f bets)\n", res.Variance, res.StdDev)
+	fmt.Fprintf(w, "Blackjacks\t%.2f%% of rounds\n", 100*res.BlackjackRate)
+	fmt.Fprintf(w, "Player busts\t%.2f%% of hands\n", 100*res.PlayerBustRate)
+	fmt.Fprintf(w, "Dealer busts\t%.2f%% of rounds played out\n", 100*res.DealerBustRate)
+	fmt.Fprintf(w, "Surrenders\t%.2f%% of rounds\n", 100*res.SurrenderRate)
+	return w.Flush()
+}
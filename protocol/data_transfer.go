@@ -1,16 +1,23 @@
 package protocol
 
 import (
+	"encoding/json"
 	"log/slog"
+	"slices"
+	"strconv"
+	"time"
 
 	"github.com/dylanmccormick/blackjack-tui/game"
 	"github.com/dylanmccormick/blackjack-tui/internal/database"
+	"github.com/dylanmccormick/blackjack-tui/store"
 )
 
 type HandDTO struct {
-	Cards []CardDTO `json:"cards"`
-	Value int       `json:"value"`
-	State string    `json:"state"`
+	Cards  []CardDTO `json:"cards"`
+	Value  int       `json:"value"`
+	State  string    `json:"state"`
+	Bet    int       `json:"bet"`
+	Hidden int       `json:"hidden,omitempty"` // face down cards that aren't in Cards
 }
 
 type CardDTO struct {
@@ -19,23 +26,200 @@ type CardDTO struct {
 }
 
 type PlayerDTO struct {
-	Bet           int     `json:"bet"`
-	Wallet        int     `json:"wallet"`
-	Hand          HandDTO `json:"hand"`
-	Name          string  `json:"name"`
-	CurrentPlayer bool    `json:"current"`
+	Bet           int       `json:"bet"`
+	Wallet        int       `json:"wallet"`
+	Hands         []HandDTO `json:"hands"`
+	CurrentHand   int       `json:"current_hand"`
+	Insurance     int       `json:"insurance"`
+	Jackpot       int       `json:"jackpot,omitempty"`   // jackpot side bet for the round
+	SideBets      int       `json:"side_bets,omitempty"` // Perfect Pairs and 21+3 for the round
+	Name          string    `json:"name"`
+	CurrentPlayer bool      `json:"current"`
+	CanSurrender  bool      `json:"can_surrender"`
+	Bot           bool      `json:"bot"`
+	Spot          int       `json:"spot,omitempty"`   // which of the player's spots this is, in seat order
+	Acting        bool      `json:"acting,omitempty"` // the player's spot the game is waiting on
+	AutoPlay      bool      `json:"auto_play,omitempty"`
+	SittingOut    bool      `json:"sitting_out,omitempty"` // the table stopped waiting on the player's bets
 }
 
 type GameDTO struct {
-	State  string
-	Players    []PlayerDTO
-	DealerHand HandDTO
+	State       string
+	Players     []PlayerDTO
+	DealerHand  HandDTO
+	Rules       RulesDTO
+	Variant     string
+	Dealt       *DealtDTO // every card dealt this round in shoe order. Only sent once the round is over
+	Round       int
+	CardsInShoe int            // cards left to deal from the shoe. Counters need it for the true count
+	Tournament  *TournamentDTO // the leaderboard when the table is playing a tournament
 }
 
 type TableDTO struct {
 	Id             string
 	Capacity       int
 	CurrentPlayers int
+	Rules          RulesDTO
+	Variant        string
+	Tournament     bool
+	Profile        string `json:",omitempty"` // the rule profile the table was created with
+}
+
+// TournamentDTO is a scheduled tournament. Standings are in sign up order until it starts and
+// leaderboard order after
+type TournamentDTO struct {
+	Name            string        `json:"name"`
+	State           string        `json:"state"` // sign_up, running or finished
+	StartsAt        time.Time     `json:"starts_at"`
+	Chips           int           `json:"chips"`
+	Hands           int           `json:"hands"`
+	HandsLeft       int           `json:"hands_left"`
+	NextElimination int           `json:"next_elimination,omitempty"` // hands until the shortest stack is knocked out
+	Prizes          []int         `json:"prizes"`
+	Capacity        int           `json:"capacity"`
+	SignedUp        bool          `json:"signed_up"` // whether the player the list was sent to has signed up
+	Standings       []StandingDTO `json:"standings"`
+}
+
+type StandingDTO struct {
+	Name  string `json:"name"`
+	Chips int    `json:"chips"`
+	Place int    `json:"place,omitempty"` // set once the player is knocked out or the tournament is over
+	Prize int    `json:"prize,omitempty"`
+}
+
+type RulesDTO struct {
+	StandOnSoft17     bool                      `json:"stand_on_soft_17"`
+	BlackjackPayout   string                    `json:"blackjack_payout"`
+	DoubleRule        string                    `json:"double_rule"`
+	DoubleAfterSplit  bool                      `json:"double_after_split"`
+	MaxSplits         int                       `json:"max_splits"`
+	NoHoleCard        bool                      `json:"no_hole_card"`
+	Surrender         string                    `json:"surrender"`
+	Charlie           int                       `json:"charlie,omitempty"`
+	DealerWinsTies    bool                      `json:"dealer_wins_ties,omitempty"`
+	Bonus678          int                       `json:"bonus_678,omitempty"`
+	Bonus777          int                       `json:"bonus_777,omitempty"`
+	ContinuousShuffle bool                      `json:"continuous_shuffle"`
+	MinBet            int                       `json:"min_bet"`
+	MaxBet            int                       `json:"max_bet"`
+	BetIncrement      int                       `json:"bet_increment"`
+	MaxSpots          int                       `json:"max_spots,omitempty"`
+	JackpotBet        int                       `json:"jackpot_bet,omitempty"`  // 0 when the table doesn't offer the jackpot
+	SidePayouts       map[string]map[string]int `json:"side_payouts,omitempty"` // what each side bet hand pays, to 1
+}
+
+// JackpotDTO is the progressive jackpot pool every table shares
+type JackpotDTO struct {
+	Amount int `json:"amount"`
+}
+
+// CountQuizDTO is a count trainer quiz. The client sends one without a guess when it asks the
+// player and another with the guess once they answer. The server grades the guess against its own
+// count of the table and sends the result back
+type CountQuizDTO struct {
+	Guess     *int    `json:"guess,omitempty"`
+	Count     int     `json:"count"`      // the running count the quiz asked for
+	TrueCount float64 `json:"true_count"` // the running count per deck left in the shoe
+	Correct   bool    `json:"correct"`
+}
+
+// CreateTableDTO asks the lobby for a new table. Older clients only send the name, as a value message
+type CreateTableDTO struct {
+	Name    string `json:"value"`
+	Profile string `json:"profile,omitempty"` // the rule profile the table is dealt with. Empty for the server's rules
+}
+
+// BetDTO is a main bet and its side bets, keyed by side bet name
+type BetDTO struct {
+	Main     int            `json:"main"`
+	SideBets map[string]int `json:"side_bets,omitempty"`
+	Value    string         `json:"value,omitempty"` // a main bet on its own, the way older clients send it
+	Spot     *int           `json:"spot,omitempty"`  // index of the player's spot the bet goes on. nil for their next spot without a bet
+}
+
+type BetErrorDTO struct {
+	Reason       string `json:"reason"`
+	Message      string `json:"message"`
+	Bet          int    `json:"bet"`
+	MinBet       int    `json:"min_bet"`
+	MaxBet       int    `json:"max_bet"`
+	BetIncrement int    `json:"bet_increment"`
+	SideBet      string `json:"side_bet,omitempty"`
+}
+
+type ShuffleDTO struct {
+	CardsInShoe int `json:"cards_in_shoe"`
+}
+
+// ShoeCommitDTO is published before any card is dealt from a shoe
+type ShoeCommitDTO struct {
+	Shoe       int    `json:"shoe"`
+	Decks      int    `json:"decks"`
+	Variant    string `json:"variant,omitempty"`
+	Commitment string `json:"commitment"`
+}
+
+// ShoeRevealDTO gives away a shoe's seed once the shoe is finished so players can check the deal
+type ShoeRevealDTO struct {
+	Shoe       int    `json:"shoe"`
+	Decks      int    `json:"decks"`
+	Variant    string `json:"variant,omitempty"`
+	Commitment string `json:"commitment"`
+	Seed       string `json:"seed"`
+}
+
+type DealtDTO struct {
+	Position int       `json:"position"`
+	Cards    []CardDTO `json:"cards"`
+}
+
+// ShoeHistoryDTO is the hand history the verify command reads
+type ShoeHistoryDTO struct {
+	Decks      int        `json:"decks"`
+	Variant    string     `json:"variant,omitempty"`
+	Commitment string     `json:"commitment"`
+	Seed       string     `json:"seed,omitempty"`
+	Rounds     []DealtDTO `json:"rounds"`
+}
+
+// HistoryDTO is a page of the player's own rounds, newest first
+type HistoryDTO struct {
+	Page   int               `json:"page"`
+	Rounds []RoundSummaryDTO `json:"rounds"`
+}
+
+type RoundSummaryDTO struct {
+	ID       int64     `json:"id"`
+	Table    string    `json:"table"`
+	Round    int       `json:"round"`
+	PlayedAt time.Time `json:"played_at"`
+	Bet      int       `json:"bet"`
+	Payout   int       `json:"payout"`
+}
+
+// RoundDetailDTO is a round from the hand history with the table after every card and decision.
+// Only the shoe's commitment is sent. The seed could still be in use
+type RoundDetailDTO struct {
+	ID         int64          `json:"id"`
+	Table      string         `json:"table"`
+	Round      int            `json:"round"`
+	PlayedAt   time.Time      `json:"played_at"`
+	Commitment string         `json:"commitment"`
+	Steps      []RoundStepDTO `json:"steps"`
+}
+
+type RoundStepDTO struct {
+	Description string  `json:"description"`
+	Game        GameDTO `json:"game"`
+}
+
+// StrategyDTO is what basic strategy says about a decision. Action is the play that was made
+// and is empty when the player asked for a hint before deciding
+type StrategyDTO struct {
+	Hand   int    `json:"hand"`
+	Action string `json:"action,omitempty"`
+	Advice string `json:"advice"`
 }
 
 type PopUpDTO struct {
@@ -52,7 +236,17 @@ type StatsDTO struct {
 	HandsPlayed   int `json:"hands_played"`
 	HandsWon      int `json:"hands_won"`
 	HandsLost     int `json:"hands_lost"`
+	Surrendered   int `json:"hands_surrendered"`
 	WinPercentage int `json:"win_percentage"`
+	SideBets      int `json:"side_bets"`
+	SideBetsWon   int `json:"side_bets_won"`
+	Timeouts      int `json:"timeouts"` // times the table had to play or bet for the player
+
+	StrategyDecisions int `json:"strategy_decisions"`
+	StrategyAccuracy  int `json:"strategy_accuracy"` // percent of decisions that matched basic strategy
+
+	CountQuizzes  int `json:"count_quizzes"`
+	CountAccuracy int `json:"count_accuracy"` // percent of count trainer quizzes answered correctly
 }
 
 func CardToDTO(c game.Card) CardDTO {
@@ -70,6 +264,14 @@ func UserToStatsDTO(u *database.User) StatsDTO {
 	} else {
 		winPercentage = 0
 	}
+	var strategyAccuracy int
+	if u.StrategyDecisions > 0 {
+		strategyAccuracy = int(100 * (u.StrategyDecisions - u.StrategyDeviations) / u.StrategyDecisions)
+	}
+	var countAccuracy int
+	if u.CountQuizzes > 0 {
+		countAccuracy = int(100 * u.CountQuizzesCorrect / u.CountQuizzes)
+	}
 	return StatsDTO{
 		LifetimeBet:   int(u.AmountBetLifetime),
 		LifetimeLoss:  int(u.AmountLostLifetime),
@@ -79,7 +281,17 @@ func UserToStatsDTO(u *database.User) StatsDTO {
 		HandsPlayed:   int(u.HandsPlayed),
 		HandsWon:      int(u.HandsWon),
 		HandsLost:     int(u.HandsLost),
+		Surrendered:   int(u.HandsSurrendered),
 		WinPercentage: winPercentage,
+		SideBets:      int(u.SideBets),
+		SideBetsWon:   int(u.SideBetsWon),
+		Timeouts:      int(u.Timeouts),
+
+		StrategyDecisions: int(u.StrategyDecisions),
+		StrategyAccuracy:  strategyAccuracy,
+
+		CountQuizzes:  int(u.CountQuizzes),
+		CountAccuracy: countAccuracy,
 	}
 }
 
@@ -92,16 +304,27 @@ func HandToDTO(h *game.Hand) HandDTO {
 		Cards: cards,
 		Value: h.GetValue(),
 		State: h.GetState().String(),
+		Bet:   h.Bet,
 	}
 }
 
 func PlayerToDTO(p *game.Player) PlayerDTO {
+	hands := []HandDTO{}
+	for _, h := range p.Hands {
+		hands = append(hands, HandToDTO(h))
+	}
 	return PlayerDTO{
 		Bet:           p.Bet,
 		Wallet:        p.Wallet,
-		Hand:          HandToDTO(p.Hand),
+		Hands:         hands,
+		Insurance:     p.Insurance,
+		Jackpot:       p.Jackpot,
+		SideBets:      p.SideBetTotal(),
 		Name:          p.Name,
 		CurrentPlayer: (p.State == game.PLAYING_TURN),
+		Bot:           p.Bot,
+		AutoPlay:      p.AutoPlay,
+		SittingOut:    p.SittingOut,
 	}
 }
 
@@ -109,26 +332,199 @@ func GameToDTO(g *game.Game) GameDTO {
 	players := []PlayerDTO{}
 	for _, p := range g.Players {
 		if p != nil {
-			players = append(players, PlayerToDTO(p))
+			player := PlayerToDTO(p)
+			if player.CurrentPlayer {
+				player.CurrentHand = g.CurrentHandIndex
+			}
+			player.CanSurrender = g.CanSurrender(p)
+			spots := g.Spots(p.OwnerID())
+			player.Spot = slices.Index(spots, p)
+			player.Acting = g.ActingSpot(p.OwnerID()) == p
+			players = append(players, player)
 		} else {
 			// Send empty spaces for table
 			players = append(players, PlayerDTO{})
 		}
 	}
+	dto := GameDTO{
+		State:       g.State.String(),
+		DealerHand:  DealerToDTO(g.State, g.DealerHand, g.Config.Variant),
+		Players:     players,
+		Rules:       RulesToDTO(g.Config.Rules),
+		Variant:     g.Config.Variant.Name(),
+		Round:       g.Round,
+		CardsInShoe: len(g.Deck.Base().Cards),
+	}
+	if dealt, ok := g.RoundCards(); ok {
+		d := DealtToDTO(dealt)
+		dto.Dealt = &d
+	}
+	return dto
+}
+
+func RoundSummaryToDTO(r store.RoundSummary) RoundSummaryDTO {
+	return RoundSummaryDTO{
+		ID:       r.ID,
+		Table:    r.TableID,
+		Round:    r.Round,
+		PlayedAt: r.PlayedAt,
+		Bet:      r.Bet,
+		Payout:   r.Payout,
+	}
+}
+
+// RoundToDTO replays a stored round into the steps the history page shows
+func RoundToDTO(rr store.RoundRecord) (RoundDetailDTO, error) {
+	events := []game.Event{}
+	err := json.Unmarshal(rr.Events, &events)
+	if err != nil {
+		return RoundDetailDTO{}, err
+	}
+	steps, err := game.ReplayRound(events)
+	if err != nil {
+		return RoundDetailDTO{}, err
+	}
+	seed, err := game.ParseSeed(rr.ShoeSeed)
+	if err != nil {
+		return RoundDetailDTO{}, err
+	}
+	dto := RoundDetailDTO{
+		ID:         rr.ID,
+		Table:      rr.TableID,
+		Round:      rr.Round,
+		PlayedAt:   rr.PlayedAt,
+		Commitment: seed.Commitment(),
+		Steps:      []RoundStepDTO{},
+	}
+	for _, step := range steps {
+		dto.Steps = append(dto.Steps, RoundStepDTO{Description: step.Description, Game: RoundStepToDTO(step)})
+	}
+	return dto, nil
+}
+
+func RoundStepToDTO(s game.RoundStep) GameDTO {
+	players := []PlayerDTO{}
+	for _, p := range s.Players {
+		player := PlayerToDTO(p)
+		if player.CurrentPlayer {
+			player.CurrentHand = s.CurrentHand
+		}
+		players = append(players, player)
+	}
 	return GameDTO{
-		State:  g.State.String(),
-		DealerHand: DealerToDTO(g.State, g.DealerHand),
+		State:      s.State.String(),
+		DealerHand: DealerToDTO(s.State, s.DealerHand, s.Variant),
 		Players:    players,
 	}
 }
 
-func DealerToDTO(state game.GameState, h *game.Hand) HandDTO {
-	hand := HandToDTO(h)
-	if state != game.DEALER_TURN && state != game.RESOLVING_BETS && state != game.WAITING_FOR_BETS && len(hand.Cards) > 0 {
-		hand.Cards = hand.Cards[:1]
+func DealtToDTO(d game.DealtCards) DealtDTO {
+	cards := []CardDTO{}
+	for _, c := range d.Cards {
+		cards = append(cards, CardToDTO(c))
+	}
+	return DealtDTO{Position: d.Position, Cards: cards}
+}
+
+func ShoeHistoryFromDTO(h ShoeHistoryDTO) game.ShoeHistory {
+	rounds := []game.DealtCards{}
+	for _, r := range h.Rounds {
+		cards := []game.Card{}
+		for _, c := range r.Cards {
+			cards = append(cards, game.NewCard(c.Suit, c.Rank))
+		}
+		rounds = append(rounds, game.DealtCards{Position: r.Position, Cards: cards})
+	}
+	return game.ShoeHistory{Decks: h.Decks, Variant: h.Variant, Commitment: h.Commitment, Rounds: rounds}
+}
+
+// VerifyShoeHistory checks a hand history against the revealed seed
+func VerifyShoeHistory(seed string, h ShoeHistoryDTO) error {
+	s, err := game.ParseSeed(seed)
+	if err != nil {
+		return err
+	}
+	return game.VerifyShoe(s, ShoeHistoryFromDTO(h))
+}
+
+func RulesToDTO(r game.RuleSet) RulesDTO {
+	return RulesDTO{
+		StandOnSoft17:     r.StandOnSoft17,
+		BlackjackPayout:   r.BlackjackPayout.String(),
+		DoubleRule:        r.DoubleRule.String(),
+		DoubleAfterSplit:  r.DoubleAfterSplit,
+		MaxSplits:         r.MaxSplits,
+		NoHoleCard:        r.NoHoleCard,
+		Surrender:         r.Surrender.String(),
+		Charlie:           r.Charlie,
+		DealerWinsTies:    r.DealerWinsTies,
+		Bonus678:          r.Bonus678,
+		Bonus777:          r.Bonus777,
+		ContinuousShuffle: r.ContinuousShuffle,
+		MinBet:            r.MinBet,
+		MaxBet:            r.MaxBet,
+		BetIncrement:      r.BetIncrement,
+		MaxSpots:          r.MaxSpots,
+		JackpotBet:        r.JackpotBet,
+		SidePayouts:       SidePayoutsToDTO(r.SidePayouts),
+	}
+}
+
+func SidePayoutsToDTO(sp game.SidePayouts) map[string]map[string]int {
+	if len(sp) == 0 {
+		return nil
+	}
+	out := map[string]map[string]int{}
+	for sb, hands := range sp {
+		if len(hands) == 0 {
+			continue
+		}
+		out[string(sb)] = map[string]int{}
+		for hand, pays := range hands {
+			out[string(sb)][string(hand)] = pays
+		}
+	}
+	return out
+}
+
+func BetErrorToDTO(e *game.BetError) BetErrorDTO {
+	return BetErrorDTO{
+		Reason:       string(e.Reason),
+		Message:      e.Error(),
+		Bet:          e.Bet,
+		MinBet:       e.MinBet,
+		MaxBet:       e.MaxBet,
+		BetIncrement: e.Increment,
+		SideBet:      string(e.SideBet),
+	}
+}
+
+func DTOToBet(b BetDTO) (game.Bet, error) {
+	bet := game.Bet{Main: b.Main, SideBets: map[game.SideBet]int{}}
+	for name, amount := range b.SideBets {
+		bet.SideBets[game.SideBet(name)] = amount
+	}
+	if b.Value == "" {
+		return bet, nil
+	}
+	main, err := strconv.Atoi(b.Value)
+	bet.Main = main
+	return bet, err
+}
+
+func DealerToDTO(state game.GameState, h *game.Hand, variant game.Variant) HandDTO {
+	if variant == nil {
+		variant = game.Classic{}
+	}
+	if state != game.DEALER_TURN && state != game.RESOLVING_BETS && state != game.WAITING_FOR_BETS && len(h.Cards) > 0 && variant.UpCards() < 2 {
+		// only build the DTO from the up cards. The hand state would give the hole cards away
+		up := min(variant.UpCards(), len(h.Cards))
+		hand := HandToDTO(&game.Hand{Cards: h.Cards[:up]})
 		hand.Value = -1
+		hand.Hidden = len(h.Cards) - up
+		return hand
 	}
-	return hand
+	return HandToDTO(h)
 }
 
 func MessageToDTO(message string, lvl PopUpType) PopUpDTO {
@@ -22,15 +22,32 @@ const (
 
 const (
 	// server to client
-	MsgGameState = "game_state"
-	MsgTableList = "table_list"
-	MsgPopUp     = "pop_up"
-	MsgUserStats = "user_stats"
+	MsgGameState      = "game_state"
+	MsgTableList      = "table_list"
+	MsgPopUp          = "pop_up"
+	MsgUserStats      = "user_stats"
+	MsgBetError       = "bet_error"
+	MsgShuffle        = "shuffle"
+	MsgShoeCommit     = "shoe_commit"
+	MsgShoeReveal     = "shoe_reveal"
+	MsgHistory        = "history"
+	MsgRound          = "round"
+	MsgStrategy       = "strategy"
+	MsgTournamentList = "tournament_list" // also sent by the client to ask for the list
+	MsgJackpot        = "jackpot"         // also sent by the client to ask for the pool
 
 	// client to server
 	MsgPlaceBet    = "place_bet"
 	MsgHit         = "hit"
 	MsgStand       = "stand"
+	MsgDoubleDown  = "double_down"
+	MsgSplit       = "split"
+	MsgInsurance   = "insurance"
+	MsgEvenMoney   = "even_money"
+	MsgNoInsurance = "no_insurance"
+	MsgSurrender   = "surrender"
+	MsgHint        = "hint"
+	MsgCountQuiz   = "count_quiz" // a count trainer quiz. See CountQuizDTO
 	MsgJoinTable   = "join_table"
 	MsgLeaveTable  = "leave_table"
 	MsgCreateTable = "create_table"
@@ -39,6 +56,14 @@ const (
 	MsgDealCards   = "deal_cards"
 	MsgGetState    = "get_state"
 	MsgGetStats    = "get_stats"
+	MsgGetHistory  = "get_history" // value is the page, starting at 0
+	MsgGetRound    = "get_round"   // value is the round id
+	MsgSignUp      = "sign_up"     // value is the tournament name
+	MsgWithdraw    = "withdraw"    // value is the tournament name
+	MsgJackpotBet  = "jackpot_bet"
+	MsgAddSpot     = "add_spot"
+	MsgRemoveSpot  = "remove_spot" // value is the spot, starting at 0
+	MsgAutoPlay    = "auto_play"   // value is whether the table should bet and play for the player
 
 	MsgLogin      = "login"
 	MsgAuthStatus = "auth_status"
@@ -64,11 +89,58 @@ func PackageMessage(dto any) (*TransportMessage, error) {
 		message.Type = MsgPopUp
 	case StatsDTO:
 		message.Type = MsgUserStats
+	case BetErrorDTO:
+		message.Type = MsgBetError
+	case ShuffleDTO:
+		message.Type = MsgShuffle
+	case ShoeCommitDTO:
+		message.Type = MsgShoeCommit
+	case ShoeRevealDTO:
+		message.Type = MsgShoeReveal
+	case HistoryDTO:
+		message.Type = MsgHistory
+	case RoundDetailDTO:
+		message.Type = MsgRound
+	case StrategyDTO:
+		message.Type = MsgStrategy
+	case []TournamentDTO:
+		message.Type = MsgTournamentList
+	case JackpotDTO:
+		message.Type = MsgJackpot
+	case CountQuizDTO:
+		message.Type = MsgCountQuiz
 	}
 
 	return &message, nil
 }
 
+// PackageBet wraps a bet and its side bets for the server
+func PackageBet(bet BetDTO) *TransportMessage {
+	data, err := json.Marshal(bet)
+	if err != nil {
+		return &TransportMessage{}
+	}
+	return &TransportMessage{Type: MsgPlaceBet, Data: data}
+}
+
+// PackageCountQuiz asks the server to hold the count for a quiz, or answers it with a guess
+func PackageCountQuiz(guess *int) *TransportMessage {
+	data, err := json.Marshal(CountQuizDTO{Guess: guess})
+	if err != nil {
+		return &TransportMessage{}
+	}
+	return &TransportMessage{Type: MsgCountQuiz, Data: data}
+}
+
+// PackageCreateTable asks for a new table, dealt with one of the server's rule profiles if one is given
+func PackageCreateTable(name, profile string) *TransportMessage {
+	data, err := json.Marshal(CreateTableDTO{Name: name, Profile: profile})
+	if err != nil {
+		return &TransportMessage{}
+	}
+	return &TransportMessage{Type: MsgCreateTable, Data: data}
+}
+
 func PackageClientMessage(typ, val string) *TransportMessage {
 	message := TransportMessage{}
 	if val != "" {
@@ -0,0 +1,274 @@
+package server
+
+import (
+	"cmp"
+	"fmt"
+	"math/rand/v2"
+	"time"
+
+	"github.com/dylanmccormick/blackjack-tui/game"
+	"github.com/dylanmccormick/blackjack-tui/store"
+	"github.com/google/uuid"
+)
+
+const (
+	BOT_THINK_TIME = 750 // milliseconds a bot waits before each play so people can follow along
+	BOT_BET        = 10
+	BOT_WALLET     = 1000
+
+	BETTING_FLAT       = "flat"
+	BETTING_RANDOM     = "random"
+	BETTING_MARTINGALE = "martingale"
+)
+
+type BotConfig struct {
+	Name     string `yaml:"name"`     // shown as bot_<name>. Defaults to the bot's number
+	Strategy string `yaml:"strategy"` // basic, never_bust or random
+	Betting  string `yaml:"betting"`  // flat, random or martingale
+	Bet      int    `yaml:"bet"`      // the base bet
+	Wallet   int    `yaml:"wallet"`   // what the bot buys back in for when it goes broke
+}
+
+// bot is a seat the server plays. Bots only bet while a person is sitting at the table so an
+// empty table still times out
+type bot struct {
+	player   *game.Player
+	strategy game.Strategy
+	betting  bettingProfile
+	wallet   int
+}
+
+// bettingProfile decides how much a bot puts down each round
+type bettingProfile interface {
+	nextBet() int
+	settle(net int) // how the last round went for the bot
+}
+
+// flatBetting bets the same every round
+type flatBetting struct {
+	bet int
+}
+
+func (b *flatBetting) nextBet() int { return b.bet }
+func (b *flatBetting) settle(int)   {}
+
+// randomBetting bets between one and four times the base bet
+type randomBetting struct {
+	bet int
+}
+
+func (b *randomBetting) nextBet() int { return b.bet * (1 + rand.IntN(4)) }
+func (b *randomBetting) settle(int)   {}
+
+// martingale doubles the bet after every loss and goes back to the base bet after a win
+type martingale struct {
+	bet  int
+	next int
+}
+
+func (b *martingale) nextBet() int { return b.next }
+
+func (b *martingale) settle(net int) {
+	switch {
+	case net < 0:
+		b.next *= 2
+	case net > 0:
+		b.next = b.bet
+	}
+}
+
+func newBettingProfile(name string, bet int) (bettingProfile, error) {
+	switch name {
+	case "", BETTING_FLAT:
+		return &flatBetting{bet: bet}, nil
+	case BETTING_RANDOM:
+		return &randomBetting{bet: bet}, nil
+	case BETTING_MARTINGALE:
+		return &martingale{bet: bet, next: bet}, nil
+	}
+	return nil, fmt.Errorf("Unknown betting profile %q", name)
+}
+
+func newBot(i int, config BotConfig) (*bot, error) {
+	strategy, err := game.ParseStrategy(cmp.Or(config.Strategy, game.STRATEGY_BASIC))
+	if err != nil {
+		return nil, err
+	}
+	betting, err := newBettingProfile(config.Betting, cmp.Or(config.Bet, BOT_BET))
+	if err != nil {
+		return nil, err
+	}
+	wallet := cmp.Or(config.Wallet, BOT_WALLET)
+	// underscores aren't allowed in GitHub usernames so a bot can never be mistaken for a user
+	p := game.NewPlayer(uuid.New(), wallet)
+	p.Name = "bot_" + cmp.Or(config.Name, fmt.Sprint(i+1))
+	p.Bot = true
+	return &bot{player: p, strategy: strategy, betting: betting, wallet: wallet}, nil
+}
+
+// seatBots fills the empty seats with the configured bots
+func (t *Table) seatBots() {
+	for i, config := range t.Config.Bots {
+		b, err := newBot(i, config)
+		if err != nil {
+			t.log.Error("Unable to create bot", "bot", i, "error", err)
+			continue
+		}
+		err = t.game.AddPlayer(b.player)
+		if err != nil {
+			t.log.Info("No seat left for bot", "bot", b.player.Name)
+			return
+		}
+		t.bots[b.player.ID] = b
+		t.log.Info("Bot sat down", "bot", b.player.Name)
+	}
+}
+
+// standUpBot frees a seat for a person. Bots sitting out the round get up first. A bot can only
+// leave between rounds, so mid-round it stands and gets up once the round is over.
+// False if no seat is free yet
+func (t *Table) standUpBot() bool {
+	var leaving *bot
+	for _, b := range t.bots {
+		if !b.player.DisconnectedAt.IsZero() {
+			// already getting up for someone else
+			continue
+		}
+		if leaving == nil || !b.player.IsActive() {
+			leaving = b
+		}
+	}
+	if leaving == nil {
+		return false
+	}
+	p := leaving.player
+	t.log.Info("Bot got up for a player", "bot", p.Name)
+	p.MarkDisconnected(true)
+	if t.game.State == game.PLAYER_TURN && t.game.CurrentPlayer() == p {
+		t.game.AutoStay(p)
+	}
+	if !t.betweenRounds() {
+		return false
+	}
+	t.removeInactivePlayers()
+	return true
+}
+
+// humanSeated is true when a person is at the table to play with the bots
+func (t *Table) humanSeated() bool {
+	for _, p := range t.game.Players {
+		if p != nil && !p.Bot && p.DisconnectedAt.IsZero() {
+			return true
+		}
+	}
+	return false
+}
+
+func (t *Table) placeBotBets() {
+	if len(t.bots) == 0 || !t.humanSeated() {
+		return
+	}
+	rules := t.game.Config.Rules
+	for _, b := range t.bots {
+		p := b.player
+		if p.Bet > 0 {
+			continue
+		}
+		bet := fitBet(rules, b.betting.nextBet(), p.Wallet)
+		if bet < max(rules.MinBet, 1) {
+			// broke. Buy back in and start the progression over
+			p.Wallet = b.wallet
+			b.betting.settle(1)
+			bet = fitBet(rules, b.betting.nextBet(), p.Wallet)
+		}
+		err := t.game.PlaceBet(p, game.Bet{Main: bet})
+		if err != nil {
+			t.log.Warn("Bot could not bet", "bot", p.Name, "bet", bet, "error", err)
+		}
+	}
+}
+
+// fitBet brings a bet inside the table limits and the wallet
+func fitBet(rules game.RuleSet, bet, wallet int) int {
+	bet = max(bet, rules.MinBet)
+	if rules.MaxBet > 0 {
+		bet = min(bet, rules.MaxBet)
+	}
+	bet = min(bet, wallet)
+	if rules.BetIncrement > 1 {
+		bet -= bet % rules.BetIncrement
+	}
+	return bet
+}
+
+// settleBots tells the betting profiles how the round went
+func (t *Table) settleBots(results map[uuid.UUID][]store.RoundResult) {
+	for id, b := range t.bots {
+		playerResults, ok := results[id]
+		if !ok {
+			continue
+		}
+		net := 0
+		for _, r := range playerResults {
+			net += r.WalletDelta
+		}
+		b.betting.settle(net)
+	}
+}
+
+// botInsurance makes every bot's insurance decision as soon as the window opens
+func (t *Table) botInsurance() {
+	for _, b := range t.bots {
+		p := b.player
+		if !p.IsActive() || len(p.Hands) == 0 {
+			continue
+		}
+		t.botAct(b)
+	}
+}
+
+// scheduleBotTurn gives the bot whose turn it is a moment before it plays. Players on auto-play
+// get the same. False if it is a person's turn to play
+func (t *Table) scheduleBotTurn() bool {
+	p := t.game.CurrentPlayer()
+	if _, ok := t.bots[p.ID]; !ok && !p.AutoPlay {
+		return false
+	}
+	if !t.botThinking {
+		t.botThinking = true
+		t.botTimer.Reset(time.Duration(cmp.Or(t.Config.BotThinkTime, BOT_THINK_TIME)) * time.Millisecond)
+	}
+	return true
+}
+
+// playBotTurn makes the play for the bot whose turn it is
+func (t *Table) playBotTurn() {
+	t.botThinking = false
+	if t.game.State != game.PLAYER_TURN {
+		return
+	}
+	p := t.game.CurrentPlayer()
+	if b, ok := t.bots[p.ID]; ok {
+		t.botAct(b)
+	} else if p.AutoPlay {
+		t.game.AutoPlay(p, autoPlayer)
+	} else {
+		return
+	}
+	t.actionTimer.Reset(time.Duration(t.Config.TableActionTimeout) * time.Second)
+}
+
+func (t *Table) botAct(b *bot) {
+	action, err := b.strategy.Decide(t.game, b.player)
+	if err == nil {
+		err = t.game.Act(b.player, action)
+	}
+	if err != nil {
+		t.log.Error("Bot could not play", "bot", b.player.Name, "action", action, "error", err)
+		if t.game.State == game.INSURANCE {
+			t.game.DeclineInsurance(b.player)
+		} else {
+			t.game.AutoStay(b.player)
+		}
+	}
+}
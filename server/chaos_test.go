@@ -222,6 +222,8 @@ func (c *ChaosClient) ActGolden() {
 	case "WAITING_FOR_BETS":
 		slog.Info("Acting on business. (I read the messsages", "clientUser", c.username)
 		c.conn.WriteJSON(protocol.PackageClientMessage(protocol.MsgPlaceBet, "5"))
+	case "INSURANCE":
+		c.conn.WriteJSON(protocol.PackageClientMessage(protocol.MsgNoInsurance, ""))
 	case "PLAYER_TURN":
 		if c.isMyTurn() {
 			slog.Info("MY TURN. STANDING", "chaosClientNum", c.username)
@@ -0,0 +1,126 @@
+package server
+
+import (
+	"context"
+
+	"github.com/dylanmccormick/blackjack-tui/game"
+	"github.com/dylanmccormick/blackjack-tui/protocol"
+	"github.com/google/uuid"
+)
+
+// hiLoCount is the table's Hi-Lo count of every card that has shown since the shoe was shuffled.
+// Count trainer quizzes are graded against it, never against what the client says the count is
+type hiLoCount struct {
+	running int // every card that has shown in this shoe
+	round   int // cards that have shown this round
+
+	missed  map[uuid.UUID]int // count of the cards that were gone before the client sat down
+	quizzes map[uuid.UUID]int // the client's count when they were asked
+}
+
+func newHiLoCount() *hiLoCount {
+	return &hiLoCount{missed: make(map[uuid.UUID]int), quizzes: make(map[uuid.UUID]int)}
+}
+
+func hiLo(c game.Card) int {
+	switch {
+	case c.Rank >= 2 && c.Rank <= 6:
+		return 1
+	case c.Rank == game.ACE || c.Rank >= 10:
+		return -1
+	}
+	return 0
+}
+
+// observe counts the cards in a game event as they show
+func (hc *hiLoCount) observe(e game.Event, continuous bool) {
+	switch data := e.Data.(type) {
+	case game.CardDealt:
+		if !data.FaceDown {
+			hc.add(data.Card)
+		}
+	case game.HoleCardRevealed:
+		hc.add(data.Card)
+	case game.ShoeShuffled:
+		// cards still on the table stay out of the shoe when the discards go back in mid-round
+		hc.running = hc.round
+		clear(hc.missed)
+	case game.RoundEnded:
+		if continuous {
+			// the machine takes the round's cards straight back
+			hc.running = 0
+			clear(hc.missed)
+		}
+		hc.round = 0
+	}
+}
+
+func (hc *hiLoCount) add(c game.Card) {
+	hc.running += hiLo(c)
+	hc.round += hiLo(c)
+}
+
+// join starts a client's count from the cards on the table. Anything dealt before that is
+// left out of the count they are graded on
+func (hc *hiLoCount) join(id uuid.UUID) {
+	hc.missed[id] = hc.running - hc.round
+	delete(hc.quizzes, id)
+}
+
+func (hc *hiLoCount) leave(id uuid.UUID) {
+	delete(hc.missed, id)
+	delete(hc.quizzes, id)
+}
+
+// count is the running count of every card the client has been able to see
+func (hc *hiLoCount) count(id uuid.UUID) int {
+	return hc.running - hc.missed[id]
+}
+
+// ask keeps the count a quiz is asking for, so cards dealt while the player thinks don't change it
+func (hc *hiLoCount) ask(id uuid.UUID) {
+	hc.quizzes[id] = hc.count(id)
+}
+
+// grade checks a guess against the count the quiz asked for. A guess without a quiz is
+// checked against the count now
+func (hc *hiLoCount) grade(id uuid.UUID, guess int) (int, bool) {
+	count, ok := hc.quizzes[id]
+	if !ok {
+		count = hc.count(id)
+	}
+	delete(hc.quizzes, id)
+	return count, guess == count
+}
+
+// trueCount is a running count per deck left in the shoe
+func trueCount(running, cardsInShoe, deckSize int) float64 {
+	decks := float64(cardsInShoe) / float64(max(deckSize, 1))
+	if decks < 0.5 {
+		// the last few cards would blow the true count up
+		decks = 0.5
+	}
+	return float64(running) / decks
+}
+
+// gradeCountQuiz checks a player's answer against the table's count, saves the result with their
+// stats and tells them how they did
+func (t *Table) gradeCountQuiz(c *Client, guess int) {
+	count, correct := t.count.grade(c.id, guess)
+	err := t.db.RecordCountQuiz(context.Background(), c.username, correct)
+	if err != nil {
+		t.log.Error("Unable to record count quiz", "username", c.username, "error", err)
+	}
+	result := protocol.CountQuizDTO{
+		Guess:     &guess,
+		Count:     count,
+		TrueCount: trueCount(count, len(t.game.Deck.Base().Cards), len(t.game.Config.Variant.Deck())),
+		Correct:   correct,
+	}
+	msg, err := protocol.PackageMessage(result)
+	if err != nil {
+		t.log.Error("Unable to package count quiz", "error", err)
+		return
+	}
+	c.send <- msg
+}
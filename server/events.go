@@ -0,0 +1,63 @@
+package server
+
+import (
+	"github.com/dylanmccormick/blackjack-tui/game"
+	"github.com/dylanmccormick/blackjack-tui/protocol"
+)
+
+// handleEvents works through everything the game did since the last call, in order
+func (t *Table) handleEvents() {
+	for _, e := range t.game.DrainEvents() {
+		t.handleEvent(e)
+	}
+}
+
+func (t *Table) handleEvent(e game.Event) {
+	t.log.Debug("Game event", "seq", e.Seq, "round", e.Round, "type", e.Type)
+	if len(t.roundEvents) > 0 && t.roundEvents[0].Round != e.Round {
+		// the round never got dealt. Nothing to keep
+		t.roundEvents = nil
+	}
+	t.roundEvents = append(t.roundEvents, e)
+	t.count.observe(e, t.game.Config.Rules.ContinuousShuffle)
+	switch data := e.Data.(type) {
+	case game.ShoeShuffled:
+		t.log.Info("Shoe shuffled", "discards", data.Discards, "cards", data.Cards)
+		if !data.Discards {
+			t.Metrics.ShoeShuffles.Inc()
+			t.recordShoe(data.Seed)
+		}
+		if t.game.Config.Rules.ContinuousShuffle {
+			// the machine shuffles every round. No need to tell anyone
+			return
+		}
+		t.broadcast(protocol.ShuffleDTO{CardsInShoe: data.Cards})
+	case game.BetPlaced:
+		t.Metrics.BetAmount.Observe(float64(data.Amount))
+		if !data.Automatic {
+			t.playerActed(data.PlayerID)
+		}
+	case game.PlayerActed:
+		if !data.Automatic {
+			t.playerActed(data.PlayerID)
+		}
+		if data.Advice != "" {
+			t.reviewDecision(data)
+		}
+	case game.DealerPeeked:
+		if data.Blackjack {
+			t.announceDealerBlackjack()
+		}
+	case game.HandResolved:
+		t.Metrics.HandsTotal.WithLabelValues(data.Outcome.String()).Inc()
+	case game.SideBetResolved:
+		t.announceSideBet(data)
+	case game.JackpotResolved:
+		if data.Payout > 0 {
+			t.announceJackpot(data)
+		}
+	case game.RoundEnded:
+		t.recordRound(t.roundEvents)
+		t.roundEvents = nil
+	}
+}
@@ -0,0 +1,119 @@
+package server
+
+import (
+	"context"
+	"log/slog"
+	"maps"
+	"slices"
+	"sync"
+
+	"github.com/dylanmccormick/blackjack-tui/store"
+)
+
+// jackpot is the progressive jackpot pool every table in the lobby shares. Tables add side bets
+// and pay jackpots from their own goroutines, so everything goes through the mutex. The lobby and
+// every table subscribe to the pool and push changes to their own clients
+type jackpot struct {
+	mu          sync.Mutex
+	saveMu      sync.Mutex // one save at a time, so the last one always has the latest pool
+	amount      int
+	seed        int // what the house puts back in after the pool is won
+	db          *store.Store
+	subscribers map[chan int]bool
+	log         *slog.Logger
+}
+
+// newJackpot picks the pool up where it was left
+func newJackpot(db *store.Store) *jackpot {
+	j := &jackpot{
+		db:          db,
+		subscribers: make(map[chan int]bool),
+		log:         slog.With("component", "jackpot"),
+	}
+	amount, err := db.JackpotAmount(context.Background())
+	if err != nil {
+		j.log.Warn("Unable to load jackpot. Starting from empty", "error", err)
+	}
+	j.amount = amount
+	return j
+}
+
+// setSeed tops the pool up to the seed if it is below it
+func (j *jackpot) setSeed(seed int) {
+	j.mu.Lock()
+	j.seed = seed
+	topUp := j.amount < seed
+	if topUp {
+		j.amount = seed
+	}
+	j.mu.Unlock()
+	if topUp {
+		j.changed()
+	}
+}
+
+func (j *jackpot) Amount() int {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.amount
+}
+
+func (j *jackpot) Add(amount int) {
+	j.mu.Lock()
+	j.amount += amount
+	j.mu.Unlock()
+	j.changed()
+}
+
+func (j *jackpot) Win(percent int) int {
+	j.mu.Lock()
+	payout := j.amount * percent / 100
+	j.amount = max(j.amount-payout, j.seed)
+	j.log.Info("Jackpot won", "percent", percent, "payout", payout, "pool", j.amount)
+	j.mu.Unlock()
+	j.changed()
+	return payout
+}
+
+// subscribe gets the pool every time it changes. Only the latest amount is kept so a slow
+// subscriber never holds up a table
+func (j *jackpot) subscribe() chan int {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	ch := make(chan int, 1)
+	j.subscribers[ch] = true
+	return ch
+}
+
+func (j *jackpot) unsubscribe(ch chan int) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	delete(j.subscribers, ch)
+}
+
+// changed saves the pool and tells the subscribers. The caller must not hold the lock, so
+// tables and the lobby can read and add to the pool while it is being saved
+func (j *jackpot) changed() {
+	j.saveMu.Lock()
+	defer j.saveMu.Unlock()
+	j.mu.Lock()
+	amount := j.amount
+	subscribers := slices.Collect(maps.Keys(j.subscribers))
+	j.mu.Unlock()
+
+	err := j.db.SaveJackpot(context.Background(), amount)
+	if err != nil {
+		j.log.Error("Unable to save jackpot", "amount", amount, "error", err)
+	}
+	for _, ch := range subscribers {
+		// only the latest amount is kept. Nothing here ever waits on a subscriber
+		select {
+		case <-ch:
+		default:
+		}
+		select {
+		case ch <- amount:
+		default:
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package server
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/dylanmccormick/blackjack-tui/game"
+	"github.com/dylanmccormick/blackjack-tui/protocol"
+	"github.com/dylanmccormick/blackjack-tui/store"
+)
+
+func TestJackpotPool(t *testing.T) {
+	db, _ := store.NewStore(":memory:", "../sql/schema")
+	j := newJackpot(db)
+	j.setSeed(1000)
+	updates := j.subscribe()
+	j.Add(5)
+	j.Add(5)
+	if amount := <-updates; amount != 1010 {
+		t.Errorf("Subscribers should only get the latest pool. expected=1010 got=%d", amount)
+	}
+	if payout := j.Win(10); payout != 101 || j.Amount() != 1000 {
+		t.Errorf("Expected 10%% of the pool to be paid and the seed put back. payout=%d pool=%d", payout, j.Amount())
+	}
+	j.Add(50)
+	if payout := j.Win(100); payout != 1050 || j.Amount() != 1000 {
+		t.Errorf("Expected the whole pool to be paid and the seed put back. payout=%d pool=%d", payout, j.Amount())
+	}
+	j.Add(25)
+	j.unsubscribe(updates)
+
+	// the pool survives a restart
+	restarted := newJackpot(db)
+	restarted.setSeed(500)
+	if restarted.Amount() != 1025 {
+		t.Errorf("Jackpot not saved. expected=1025 got=%d", restarted.Amount())
+	}
+}
+
+func TestJackpotConcurrentAdds(t *testing.T) {
+	db, _ := store.NewStore(":memory:", "../sql/schema")
+	j := newJackpot(db)
+	updates := j.subscribe()
+	var wg sync.WaitGroup
+	for range 20 {
+		wg.Go(func() {
+			j.Add(5)
+			j.Amount()
+		})
+	}
+	wg.Wait()
+	if amount := <-updates; amount != 100 {
+		t.Errorf("Subscribers should end up with the latest pool. expected=100 got=%d", amount)
+	}
+	// the last save has the whole pool
+	if saved, _ := db.JackpotAmount(context.Background()); saved != 100 {
+		t.Errorf("Jackpot saved out of order. expected=100 got=%d", saved)
+	}
+}
+
+func TestTableJackpotBet(t *testing.T) {
+	db, _ := store.NewStore(":memory:", "../sql/schema")
+	lobby := NewLobby(db, CreateMetrics())
+	lobby.jackpot.setSeed(1000)
+	ctx := context.WithValue(context.Background(), "config", Config{
+		BetTimeout:         30,
+		TableActionTimeout: 30,
+		TableDeleteTimeout: 5,
+		JackpotBet:         5,
+	})
+	tab := newTable(ctx, "test_table", lobby, db, CreateMetrics())
+	if !tab.game.JackpotOffered() || tab.CreateDTO().Rules.JackpotBet != 5 {
+		t.Fatalf("Table should offer the jackpot")
+	}
+	client := clientHelper(1)[0]
+	tab.RegisterClient(client)
+	p := tab.game.GetPlayer(client.id)
+	tab.game.StartGame()
+	tab.game.PlaceBet(p, game.Bet{Main: 10})
+	tab.handleCommand(inboundMessage{protocol.PackageClientMessage(protocol.MsgJackpotBet, ""), client})
+	if p.Jackpot != 5 || lobby.jackpot.Amount() != 1005 {
+		t.Errorf("Jackpot bet not placed. bet=%d pool=%d", p.Jackpot, lobby.jackpot.Amount())
+	}
+
+	// the lobby pushes the new pool to everyone in it
+	lobbyClient := clientHelper(1)[0]
+	lobby.RegisterClient(lobbyClient)
+	lobby.pushJackpot(lobby.jackpot.Amount())
+	msg := <-lobbyClient.send
+	if msg.Type != protocol.MsgJackpot {
+		t.Errorf("Expected a jackpot message. got=%s", msg.Type)
+	}
+
+	noJackpot := newTable(context.TODO(), "no_jackpot", lobby, db, CreateMetrics())
+	if noJackpot.game.JackpotOffered() {
+		t.Errorf("Tables shouldn't offer the jackpot unless it is configured")
+	}
+}
@@ -3,7 +3,9 @@ package server
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"log/slog"
+	"strconv"
 	"sync"
 	"time"
 
@@ -11,6 +13,8 @@ import (
 	"github.com/dylanmccormick/blackjack-tui/store"
 )
 
+const HISTORY_PAGE_SIZE = 10
+
 // The lobby will be the landing zone for any new connections to the game.
 // Players will be able to update their username, choose a table, and do whatever else they need to do
 
@@ -21,6 +25,8 @@ type Lobby struct {
 	inbound        chan inboundMessage
 	outbound       chan []byte
 	tables         map[string]*Table
+	tournaments    map[string]*tournament
+	jackpot        *jackpot
 	tableWg        sync.WaitGroup
 	log            *slog.Logger
 	store          *store.Store
@@ -35,6 +41,8 @@ func NewLobby(store *store.Store, metrics *Metrics) *Lobby {
 		inbound:        make(chan inboundMessage),
 		outbound:       make(chan []byte),
 		tables:         make(map[string]*Table),
+		tournaments:    make(map[string]*tournament),
+		jackpot:        newJackpot(store),
 		log:            slog.With("component", "lobby"),
 		store:          store,
 		Metrics:        metrics,
@@ -42,6 +50,14 @@ func NewLobby(store *store.Store, metrics *Metrics) *Lobby {
 }
 
 func (l *Lobby) run(ctx context.Context) {
+	l.scheduleTournaments(ctx, time.Now())
+	tournamentTicker := time.NewTicker(time.Second)
+	defer tournamentTicker.Stop()
+	if config, ok := ctx.Value("config").(Config); ok {
+		l.jackpot.setSeed(config.JackpotSeed)
+	}
+	jackpotUpdates := l.jackpot.subscribe()
+	defer l.jackpot.unsubscribe(jackpotUpdates)
 	for {
 		select {
 		case <-ctx.Done():
@@ -54,6 +70,10 @@ func (l *Lobby) run(ctx context.Context) {
 			l.UnregisterClient(client)
 		case msg := <-l.inbound:
 			l.handleCommand(ctx, msg)
+		case now := <-tournamentTicker.C:
+			l.startTournaments(ctx, now)
+		case amount := <-jackpotUpdates:
+			l.pushJackpot(amount)
 		}
 	}
 }
@@ -100,14 +120,43 @@ func (l *Lobby) handleCommand(ctx context.Context, msg inboundMessage) {
 		stats := protocol.UserToStatsDTO(&usr)
 		data, err := protocol.PackageMessage(stats)
 		msg.client.send <- data
+	case protocol.MsgGetHistory:
+		val, err := getValueFromRawValueMessage(msg.data.Data)
+		if err != nil {
+			return
+		}
+		page, err := strconv.Atoi(val)
+		if err != nil || page < 0 {
+			page = 0
+		}
+		l.sendHistory(ctx, msg.client, page)
+	case protocol.MsgGetRound:
+		val, err := getValueFromRawValueMessage(msg.data.Data)
+		if err != nil {
+			return
+		}
+		id, err := strconv.ParseInt(val, 10, 64)
+		if err != nil {
+			l.log.Warn("Bad round id", "value", val)
+			return
+		}
+		l.sendRound(ctx, msg.client, id)
 
 	case protocol.MsgCreateTable:
-		val, err := getValueFromRawValueMessage(msg.data.Data)
+		value := protocol.CreateTableDTO{}
+		err := json.Unmarshal(msg.data.Data, &value)
 		if err != nil {
+			l.log.Error("Got bad data from command", "command", msg.data)
 			return
 		}
-		l.log.Info("Attempting to create table", "name", val)
-		l.createTable(ctx, val)
+		l.log.Info("Attempting to create table", "name", value.Name, "profile", value.Profile)
+		err = l.createTable(ctx, value.Name, value.Profile)
+		if err != nil {
+			popup := CreatePopUp(err.Error(), "warn")
+			if popup != nil {
+				msg.client.send <- popup
+			}
+		}
 	case protocol.MsgJoinTable:
 		val, err := getValueFromRawValueMessage(msg.data.Data)
 		if err != nil {
@@ -118,6 +167,17 @@ func (l *Lobby) handleCommand(ctx context.Context, msg inboundMessage) {
 	case protocol.MsgTableList:
 		l.log.Debug("Listing Tables")
 		l.listTables(msg.client)
+	case protocol.MsgTournamentList:
+		l.listTournaments(msg.client)
+	case protocol.MsgJackpot:
+		l.sendJackpot(msg.client)
+	case protocol.MsgSignUp, protocol.MsgWithdraw:
+		val, err := getValueFromRawValueMessage(msg.data.Data)
+		if err != nil {
+			return
+		}
+		l.log.Info("Tournament sign up", "name", val, "client", msg.client, "withdraw", msg.data.Type == protocol.MsgWithdraw)
+		l.signUp(msg.client, val, msg.data.Type == protocol.MsgWithdraw)
 
 	case protocol.MsgDeleteTable:
 		val, err := getValueFromRawValueMessage(msg.data.Data)
@@ -148,12 +208,35 @@ func (l *Lobby) sendMessage(msg inboundMessage) {
 	l.inbound <- msg
 }
 
-func (l *Lobby) createTable(ctx context.Context, name string) {
+// createTable opens a table dealt with the server's rules, or a rule profile laid over them
+func (l *Lobby) createTable(ctx context.Context, name, profile string) error {
 	if _, ok := l.tables[name]; ok {
 		l.log.Warn("Table name already exists... not creating new table")
-		return
+		return fmt.Errorf("Table %s already exists", name)
+	}
+	if _, ok := l.tournaments[name]; ok {
+		l.log.Warn("Table name is saved for a tournament... not creating new table", "name", name)
+		return fmt.Errorf("%s is saved for a tournament", name)
+	}
+	if profile != "" {
+		config, ok := ctx.Value("config").(Config)
+		if !ok {
+			config = DefaultConfig()
+		}
+		config, err := config.WithProfile(profile)
+		if err != nil {
+			l.log.Warn("Unknown rule profile... not creating new table", "profile", profile)
+			return err
+		}
+		ctx = context.WithValue(ctx, "config", config)
 	}
-	t := newTable(ctx, name, l, l.store, l.Metrics)
+	l.openTable(ctx, newTable(ctx, name, l, l.store, l.Metrics))
+	return nil
+}
+
+// openTable starts a table running and tells the lobby about it
+func (l *Lobby) openTable(ctx context.Context, t *Table) {
+	name := t.id
 	tableCtx, tableCancel := context.WithCancel(ctx)
 	t.cancel = tableCancel
 	l.tables[name] = t
@@ -204,3 +287,74 @@ func (l *Lobby) listTables(c *Client) {
 	}
 	c.send <- data
 }
+
+// sendHistory sends a page of the rounds the client played, newest first
+func (l *Lobby) sendHistory(ctx context.Context, c *Client, page int) {
+	rounds, err := l.store.RecentRounds(ctx, c.username, HISTORY_PAGE_SIZE, page*HISTORY_PAGE_SIZE)
+	if err != nil {
+		l.log.Error("Unable to get hand history", "username", c.username, "error", err)
+		return
+	}
+	out := protocol.HistoryDTO{Page: page, Rounds: []protocol.RoundSummaryDTO{}}
+	for _, r := range rounds {
+		out.Rounds = append(out.Rounds, protocol.RoundSummaryToDTO(r))
+	}
+	data, err := protocol.PackageMessage(out)
+	if err != nil {
+		l.log.Error("Unable to package hand history", "error", err)
+		return
+	}
+	c.send <- data
+}
+
+// sendRound sends one round card by card. Players can only look at rounds they were in
+func (l *Lobby) sendRound(ctx context.Context, c *Client, id int64) {
+	rr, err := l.store.GetRound(ctx, id)
+	if err != nil {
+		l.log.Error("Unable to get round", "id", id, "error", err)
+		return
+	}
+	if !rr.Played(c.username) {
+		l.log.Warn("Player asked for a round they weren't in", "username", c.username, "id", id)
+		if popup := CreatePopUp("You didn't play that round", "warn"); popup != nil {
+			c.send <- popup
+		}
+		return
+	}
+	round, err := protocol.RoundToDTO(rr)
+	if err != nil {
+		l.log.Error("Unable to replay round", "id", id, "error", err)
+		return
+	}
+	data, err := protocol.PackageMessage(round)
+	if err != nil {
+		l.log.Error("Unable to package round", "error", err)
+		return
+	}
+	c.send <- data
+}
+
+// pushJackpot tells everyone in the lobby the new jackpot without blocking on slow clients
+func (l *Lobby) pushJackpot(amount int) {
+	data, err := protocol.PackageMessage(protocol.JackpotDTO{Amount: amount})
+	if err != nil {
+		l.log.Error("Unable to package jackpot", "error", err)
+		return
+	}
+	for client := range l.clients {
+		select {
+		case client.send <- data:
+		default:
+			l.log.Warn("client send buffer full. Dropping message", "client", client.id, "type", data.Type)
+		}
+	}
+}
+
+func (l *Lobby) sendJackpot(c *Client) {
+	data, err := protocol.PackageMessage(protocol.JackpotDTO{Amount: l.jackpot.Amount()})
+	if err != nil {
+		l.log.Error("Unable to package jackpot", "error", err)
+		return
+	}
+	c.send <- data
+}
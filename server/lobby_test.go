@@ -51,23 +51,66 @@ func TestRegisterClient(t *testing.T) {
 func TestAddTable(t *testing.T) {
 	store, _ := store.NewStore(":memory:", "../sql/schema")
 	lobby := NewLobby(store, CreateMetrics())
-	lobby.createTable(context.TODO(), "test_table")
+	lobby.createTable(context.TODO(), "test_table", "")
 	if len(lobby.tables) != 1 {
 		t.Fatalf("expected lobby to have 1 table. got=%d", len(lobby.tables))
 	}
-	lobby.createTable(context.TODO(), "test_table_2")
+	lobby.createTable(context.TODO(), "test_table_2", "")
 	if len(lobby.tables) != 2 {
 		t.Fatalf("expected lobby to have 2 tables. got=%d", len(lobby.tables))
 	}
 }
 
+func TestCreateTableWithRules(t *testing.T) {
+	store, _ := store.NewStore(":memory:", "../sql/schema")
+	lobby := NewLobby(store, CreateMetrics())
+	config := DefaultConfig()
+	config.RulesConfig = RulesConfig{MinBet: ptr(5)}
+	config.RuleProfiles = map[string]RulesConfig{
+		"vegas": {StandOnSoft17: ptr(false), BlackjackPayout: "6:5"},
+	}
+	ctx := context.WithValue(context.TODO(), "config", config)
+
+	err := lobby.createTable(ctx, "house", "")
+	if err != nil {
+		t.Fatalf("unexpected error creating table. got=%s", err)
+	}
+	err = lobby.createTable(ctx, "strip", "vegas")
+	if err != nil {
+		t.Fatalf("unexpected error creating table. got=%s", err)
+	}
+	err = lobby.createTable(ctx, "nowhere", "atlantis")
+	if err == nil {
+		t.Errorf("expected an error for an unknown rule profile")
+	}
+	err = lobby.createTable(ctx, "strip", "")
+	if err == nil {
+		t.Errorf("expected an error for a table name that is taken")
+	}
+	if len(lobby.tables) != 2 {
+		t.Fatalf("expected lobby to have 2 tables. got=%d", len(lobby.tables))
+	}
+
+	house := lobby.tables["house"].CreateDTO()
+	if !house.Rules.StandOnSoft17 || house.Rules.BlackjackPayout != "3:2" || house.Profile != "" {
+		t.Errorf("table without a profile should have the server's rules. got=%+v", house)
+	}
+	strip := lobby.tables["strip"].CreateDTO()
+	if strip.Rules.StandOnSoft17 || strip.Rules.BlackjackPayout != "6:5" || strip.Profile != "vegas" {
+		t.Errorf("rule profile not applied to the table. got=%+v", strip)
+	}
+	if strip.Rules.MinBet != 5 {
+		t.Errorf("rules the profile doesn't set should come from the server. expected=%d got=%d", 5, strip.Rules.MinBet)
+	}
+}
+
 func TestListTable(t *testing.T) {
 	clients := clientHelper(2)
 	c0 := clients[0]
 	c1 := clients[1]
 	store := store.Store{}
 	lobby := NewLobby(&store, CreateMetrics())
-	lobby.createTable(context.TODO(), "test")
+	lobby.createTable(context.TODO(), "test", "")
 	lobby.RegisterClient(c0)
 	lobby.RegisterClient(c1)
 	lobby.listTables(c0)
@@ -10,6 +10,10 @@ type Metrics struct {
 	HTTPRequestsTotal    *prometheus.CounterVec
 	HTTPRequestsDuration *prometheus.HistogramVec
 	HTTPRequestsInFlight prometheus.Gauge
+
+	BetAmount    prometheus.Histogram
+	HandsTotal   *prometheus.CounterVec
+	ShoeShuffles prometheus.Counter
 }
 
 func NewMetrics(reg prometheus.Registerer) *Metrics {
@@ -44,6 +48,21 @@ func NewMetrics(reg prometheus.Registerer) *Metrics {
 			Name: "blackjack_http_requests_in_flight",
 			Help: "Number of currently executing http requests",
 		}),
+		BetAmount: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Name:    "blackjack_bet_amount",
+			Help:    "Size of the bets placed at every table",
+			Buckets: []float64{10, 50, 100, 200, 500, 1000},
+		}),
+		HandsTotal: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "blackjack_hands_total",
+			Help: "Hands played broken down by outcome",
+		},
+			[]string{"outcome"},
+		),
+		ShoeShuffles: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "blackjack_shoe_shuffles_total",
+			Help: "Number of new shoes shuffled across all tables",
+		}),
 	}
 
 	reg.MustRegister(
@@ -53,6 +72,9 @@ func NewMetrics(reg prometheus.Registerer) *Metrics {
 		m.HTTPRequestsTotal,
 		m.HTTPRequestsDuration,
 		m.HTTPRequestsInFlight,
+		m.BetAmount,
+		m.HandsTotal,
+		m.ShoeShuffles,
 	)
 	return m
 }
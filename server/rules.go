@@ -0,0 +1,171 @@
+package server
+
+import (
+	"log/slog"
+
+	"github.com/dylanmccormick/blackjack-tui/game"
+)
+
+// RulesConfig is the table rules as they are written in the config. A rule that isn't set keeps
+// whatever it is laid over, so every rule is a pointer, an empty string or a nil pay table when
+// it is left out
+type RulesConfig struct {
+	Variant           string `yaml:"variant"` // classic, spanish21, pontoon or double_exposure
+	StandOnSoft17     *bool  `yaml:"stand_on_soft_17"`
+	BlackjackPayout   string `yaml:"blackjack_payout"` // 3:2 or 6:5
+	DoubleRule        string `yaml:"double_rule"`      // any, 9-11 or 10-11
+	DoubleAfterSplit  *bool  `yaml:"double_after_split"`
+	MaxSplits         *int   `yaml:"max_splits"`
+	ResplitAces       *bool  `yaml:"resplit_aces"`
+	HitSplitAces      *bool  `yaml:"hit_split_aces"`
+	NoHoleCard        *bool  `yaml:"no_hole_card"`
+	Surrender         string `yaml:"surrender"` // none, late or early
+	Charlie           *int   `yaml:"charlie"`   // 5 or 6 card Charlie. 0 turns it off
+	DealerWinsTies    *bool  `yaml:"dealer_wins_ties"`
+	Bonus678          *int   `yaml:"bonus_678"` // what a three card 6-7-8 21 pays, to 1
+	Bonus777          *int   `yaml:"bonus_777"` // what a three card 7-7-7 21 pays, to 1
+	ContinuousShuffle *bool  `yaml:"continuous_shuffle"`
+	MinBet            *int   `yaml:"min_bet"`
+	MaxBet            *int   `yaml:"max_bet"`
+	BetIncrement      *int   `yaml:"bet_increment"`
+	MaxSpots          *int   `yaml:"max_spots"` // how many spots one player can play at once
+
+	// What the table does for a player who runs out of time to bet or play. Standing for them when it isn't set
+	Timeout *TimeoutPolicy `yaml:"timeout"`
+
+	// Side bet pay tables. What each hand pays, to 1. A side bet without a pay table isn't offered
+	PerfectPairs       map[string]int `yaml:"perfect_pairs"`
+	TwentyOnePlusThree map[string]int `yaml:"twenty_one_plus_three"`
+}
+
+// apply sets the rules that are set on top of the given rules. Values that can't be parsed keep
+// the rule they were meant to replace
+func (rc RulesConfig) apply(rules game.RuleSet) game.RuleSet {
+	set(&rules.StandOnSoft17, rc.StandOnSoft17)
+	set(&rules.DoubleAfterSplit, rc.DoubleAfterSplit)
+	set(&rules.MaxSplits, rc.MaxSplits)
+	set(&rules.ResplitAces, rc.ResplitAces)
+	set(&rules.HitSplitAces, rc.HitSplitAces)
+	set(&rules.NoHoleCard, rc.NoHoleCard)
+	set(&rules.Charlie, rc.Charlie)
+	set(&rules.DealerWinsTies, rc.DealerWinsTies)
+	set(&rules.Bonus678, rc.Bonus678)
+	set(&rules.Bonus777, rc.Bonus777)
+	set(&rules.ContinuousShuffle, rc.ContinuousShuffle)
+	set(&rules.MinBet, rc.MinBet)
+	set(&rules.MaxBet, rc.MaxBet)
+	set(&rules.BetIncrement, rc.BetIncrement)
+	set(&rules.MaxSpots, rc.MaxSpots)
+	if rc.BlackjackPayout != "" {
+		payout, err := game.ParsePayout(rc.BlackjackPayout)
+		if err != nil {
+			slog.Error("Invalid blackjack payout in config. Keeping the table's payout", "error", err)
+		} else {
+			rules.BlackjackPayout = payout
+		}
+	}
+	if rc.DoubleRule != "" {
+		double, err := game.ParseDoubleRule(rc.DoubleRule)
+		if err != nil {
+			slog.Error("Invalid double rule in config. Keeping the table's double rule", "error", err)
+		} else {
+			rules.DoubleRule = double
+		}
+	}
+	if rc.Surrender != "" {
+		surrender, err := game.ParseSurrenderRule(rc.Surrender)
+		if err != nil {
+			slog.Error("Invalid surrender rule in config. Keeping the table's surrender rule", "error", err)
+		} else {
+			rules.Surrender = surrender
+		}
+	}
+	for sb, payouts := range map[game.SideBet]map[string]int{
+		game.SIDE_BET_PERFECT_PAIRS: rc.PerfectPairs,
+		game.SIDE_BET_21_PLUS_3:     rc.TwentyOnePlusThree,
+	} {
+		if payouts == nil {
+			continue
+		}
+		if rules.SidePayouts == nil {
+			rules.SidePayouts = game.SidePayouts{}
+		}
+		parsed, err := game.ParseSidePayouts(sb, payouts)
+		if err != nil {
+			slog.Error("Invalid side bet pay table in config. The side bet is turned off", "side_bet", sb, "error", err)
+			delete(rules.SidePayouts, sb)
+			continue
+		}
+		rules.SidePayouts[sb] = parsed
+	}
+	return rules
+}
+
+// timeouts is the table's timeout policy
+func (rc RulesConfig) timeouts() TimeoutPolicy {
+	policy := TimeoutPolicy{}
+	if rc.Timeout != nil {
+		policy = *rc.Timeout
+	}
+	if policy.Play == "" {
+		policy.Play = TIMEOUT_STAND
+	}
+	return policy
+}
+
+// overlay is these rules with every rule the profile sets replaced
+func (rc RulesConfig) overlay(profile RulesConfig) RulesConfig {
+	if profile.Variant != "" {
+		rc.Variant = profile.Variant
+	}
+	setPtr(&rc.StandOnSoft17, profile.StandOnSoft17)
+	if profile.BlackjackPayout != "" {
+		rc.BlackjackPayout = profile.BlackjackPayout
+	}
+	if profile.DoubleRule != "" {
+		rc.DoubleRule = profile.DoubleRule
+	}
+	setPtr(&rc.DoubleAfterSplit, profile.DoubleAfterSplit)
+	setPtr(&rc.MaxSplits, profile.MaxSplits)
+	setPtr(&rc.ResplitAces, profile.ResplitAces)
+	setPtr(&rc.HitSplitAces, profile.HitSplitAces)
+	setPtr(&rc.NoHoleCard, profile.NoHoleCard)
+	if profile.Surrender != "" {
+		rc.Surrender = profile.Surrender
+	}
+	setPtr(&rc.Charlie, profile.Charlie)
+	setPtr(&rc.DealerWinsTies, profile.DealerWinsTies)
+	setPtr(&rc.Bonus678, profile.Bonus678)
+	setPtr(&rc.Bonus777, profile.Bonus777)
+	setPtr(&rc.ContinuousShuffle, profile.ContinuousShuffle)
+	setPtr(&rc.MinBet, profile.MinBet)
+	setPtr(&rc.MaxBet, profile.MaxBet)
+	setPtr(&rc.BetIncrement, profile.BetIncrement)
+	setPtr(&rc.MaxSpots, profile.MaxSpots)
+	setPtr(&rc.Timeout, profile.Timeout)
+	if profile.PerfectPairs != nil {
+		rc.PerfectPairs = profile.PerfectPairs
+	}
+	if profile.TwentyOnePlusThree != nil {
+		rc.TwentyOnePlusThree = profile.TwentyOnePlusThree
+	}
+	return rc
+}
+
+// set replaces a rule with the configured value if there is one
+func set[T any](rule *T, value *T) {
+	if value != nil {
+		*rule = *value
+	}
+}
+
+func setPtr[T any](rule **T, value *T) {
+	if value != nil {
+		*rule = value
+	}
+}
+
+// ptr is a rule value for a RulesConfig
+func ptr[T any](v T) *T {
+	return &v
+}
@@ -14,6 +14,7 @@ import (
 	"time"
 
 	"github.com/dylanmccormick/blackjack-tui/auth"
+	"github.com/dylanmccormick/blackjack-tui/game"
 	"github.com/dylanmccormick/blackjack-tui/protocol"
 	"github.com/dylanmccormick/blackjack-tui/store"
 	"github.com/google/uuid"
@@ -71,15 +72,75 @@ type Config struct {
 
 	TableActionTimeout int  `yaml:"table_action_timeout_seconds"`
 	TableDeleteTimeout int  `yaml:"table_auto_delete_timeout_minutes"`
-	StandOnSoft17      bool `yaml:"stand_on_soft_17"`
 	BetTimeout         int  `yaml:"bet_time_seconds"`
+	InsuranceTimeout   int  `yaml:"insurance_time_seconds"`
 	DeckCount          int  `yaml:"deck_count"`
 	CutLocation        int  `yaml:"cut_location"`
+	BurnCard           bool `yaml:"burn_card"`
+
+	// The rules every table is dealt with unless it is created with a profile
+	RulesConfig `yaml:",inline"`
+
+	// Named rules a table can be created with. A profile only changes the rules it sets
+	RuleProfiles map[string]RulesConfig `yaml:"rule_profiles"`
+	Profile      string                 `yaml:"-"` // the profile laid over the rules. Set by WithProfile
+
+	// Progressive jackpot shared by every table. A jackpot bet of 0 turns the side bet off
+	JackpotBet  int `yaml:"jackpot_bet"`
+	JackpotSeed int `yaml:"jackpot_seed"` // what the pool goes back to after it is won
+
+	// Bots sit at every new table in the seats nobody is using
+	Bots         []BotConfig `yaml:"bots"`
+	BotThinkTime int         `yaml:"bot_think_time_ms"`
+
+	// Tournaments open for sign up in the lobby and are played at a table of their own
+	Tournaments []TournamentConfig `yaml:"tournaments"`
 
 	// Programming Config Items
 	LogLevel string `yaml:"log_level"`
 }
 
+// Rules builds the table rules from the config. Rules that aren't set keep the default
+func (c Config) Rules() game.RuleSet {
+	rules := c.RulesConfig.apply(game.DefaultRules())
+	rules.JackpotBet = c.JackpotBet
+	return rules
+}
+
+// WithProfile lays a rule profile over the config's rules. No profile leaves the config as it is
+func (c Config) WithProfile(name string) (Config, error) {
+	if name == "" {
+		return c, nil
+	}
+	profile, ok := c.RuleProfiles[name]
+	if !ok {
+		return c, fmt.Errorf("Unknown rules %q", name)
+	}
+	c.RulesConfig = c.RulesConfig.overlay(profile)
+	c.Profile = name
+	return c, nil
+}
+
+// GameConfig is the shoe and rules a table deals with. An unknown variant falls back to classic blackjack
+func (c Config) GameConfig() game.GameConfig {
+	variant, err := game.ParseVariant(c.Variant)
+	if err != nil {
+		slog.Error("Invalid variant in config. Dealing classic blackjack", "error", err)
+	}
+	rules := c.Rules()
+	if variant.UpCards() == 0 {
+		// 21+3 needs a dealer card to play with
+		delete(rules.SidePayouts, game.SIDE_BET_21_PLUS_3)
+	}
+	return game.GameConfig{
+		DeckCount:   c.DeckCount,
+		CutLocation: c.CutLocation,
+		BurnCard:    c.BurnCard,
+		Variant:     variant,
+		Rules:       rules,
+	}
+}
+
 type Server struct {
 	SessionManager *auth.SessionManager
 	Lobby          *Lobby
@@ -90,9 +151,16 @@ type Server struct {
 	Registry       *prometheus.Registry
 }
 
+// DefaultConfig is what a config file is read on top of. The table rules default to game.DefaultRules
 func DefaultConfig() Config {
 	return Config{
-		LogLevel: "INFO",
+		TableActionTimeout: ACTION_TIMEOUT,
+		TableDeleteTimeout: TABLE_TIMEOUT,
+		BetTimeout:         ACTION_TIMEOUT,
+		InsuranceTimeout:   INSURANCE_TIMEOUT,
+		DeckCount:          game.DECK_COUNT,
+		CutLocation:        game.CUT_LOCATION,
+		LogLevel:           "INFO",
 	}
 }
 
@@ -101,11 +169,20 @@ func LoadConfig() Config {
 	if err != nil {
 		slog.Warn("No env file found", "error", err)
 	}
-	yamlFile, err := os.ReadFile("config.yaml")
+	config, err := ReadConfig("config.yaml")
 	if err != nil {
-		slog.Error("Error reading yaml file", "error", err)
+		slog.Error("Unable to load config", "error", err)
 		os.Exit(1)
 	}
+	return config
+}
+
+// ReadConfig reads a config file on top of the defaults
+func ReadConfig(path string) (Config, error) {
+	yamlFile, err := os.ReadFile(path)
+	if err != nil {
+		return Config{}, fmt.Errorf("Error reading yaml file: %w", err)
+	}
 	// Loading all the environment variables into config locations with ${VAR}
 	expandedContent := []byte(os.ExpandEnv(string(yamlFile)))
 	// Set Defaults for Config
@@ -114,10 +191,9 @@ func LoadConfig() Config {
 	// Overwrite defaults
 	err = yaml.Unmarshal(expandedContent, &config)
 	if err != nil {
-		slog.Error("Unable to unmarshal yaml file", "error", err)
-		os.Exit(1)
+		return Config{}, fmt.Errorf("Unable to unmarshal yaml file: %w", err)
 	}
-	return config
+	return config, nil
 }
 
 func InitializeServer() *Server {
@@ -3,7 +3,10 @@ package server
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"log/slog"
+	"slices"
 	"strconv"
 	"time"
 
@@ -15,6 +18,7 @@ import (
 
 const (
 	ACTION_TIMEOUT    = 30
+	INSURANCE_TIMEOUT = 10
 	TABLE_TIMEOUT     = 5
 	REFRESH_TICK_RATE = 5
 )
@@ -34,12 +38,24 @@ type Table struct {
 	cancel         context.CancelFunc
 	lobby          *Lobby
 
-	maxPlayers    int
-	game          *game.Game
-	betTimer      *time.Timer
-	actionTimer   *time.Timer
-	tableTimer    *time.Timer
-	cleanupTicker *time.Ticker
+	maxPlayers     int
+	game           *game.Game
+	shoeSeeds      []game.Seed // seed of every shoe dealt at this table, oldest first
+	timeout        TimeoutPolicy
+	count          *hiLoCount   // what count trainer quizzes are graded against
+	roundEvents    []game.Event // everything that has happened in the round being played
+	betTimer       *time.Timer
+	insuranceTimer *time.Timer
+	actionTimer    *time.Timer
+	tableTimer     *time.Timer
+	botTimer       *time.Timer
+	cleanupTicker  *time.Ticker
+
+	bots        map[uuid.UUID]*bot
+	botThinking bool      // a bot's turn is waiting on the bot timer
+	waiting     []*Client // clients who get a seat once the round is over
+
+	tournament *tournament // nil unless the table is playing a tournament
 
 	log     *slog.Logger
 	db      *store.Store
@@ -52,16 +68,14 @@ func newTable(ctx context.Context, name string, lobby *Lobby, store *store.Store
 	config, ok := cfg.(Config)
 	if !ok {
 		slog.Error("context contains wrong type for config")
-		config = Config{
-			BetTimeout:         ACTION_TIMEOUT,
-			TableActionTimeout: ACTION_TIMEOUT,
-			TableDeleteTimeout: TABLE_TIMEOUT,
-		}
+		config = DefaultConfig()
 	}
-
-	gameConfig := game.GameConfig{
-		DeckCount:   config.DeckCount,
-		CutLocation: config.CutLocation,
+	gameConfig := config.GameConfig()
+	if config.InsuranceTimeout == 0 {
+		config.InsuranceTimeout = INSURANCE_TIMEOUT
+	}
+	if gameConfig.Rules.JackpotBet > 0 {
+		gameConfig.Jackpot = lobby.jackpot
 	}
 
 	t := &Table{
@@ -72,9 +86,14 @@ func newTable(ctx context.Context, name string, lobby *Lobby, store *store.Store
 		inbound:        make(chan inboundMessage, 100),
 		id:             name,
 		game:           game.NewGame(gameConfig),
+		count:          newHiLoCount(),
+		timeout:        config.timeouts(),
 		betTimer:       time.NewTimer(time.Duration(config.BetTimeout) * time.Second),
+		insuranceTimer: time.NewTimer(time.Duration(config.InsuranceTimeout) * time.Second),
 		actionTimer:    time.NewTimer(time.Duration(config.TableActionTimeout) * time.Second),
 		tableTimer:     time.NewTimer(time.Duration(config.TableDeleteTimeout) * time.Minute),
+		botTimer:       time.NewTimer(BOT_THINK_TIME * time.Millisecond),
+		bots:           make(map[uuid.UUID]*bot),
 		lobby:          lobby,
 		log:            slog.With("component", "table"),
 		db:             store,
@@ -87,13 +106,21 @@ func newTable(ctx context.Context, name string, lobby *Lobby, store *store.Store
 	if !t.betTimer.Stop() {
 		<-t.betTimer.C
 	}
+	if !t.insuranceTimer.Stop() {
+		<-t.insuranceTimer.C
+	}
 	if !t.actionTimer.Stop() {
 		<-t.actionTimer.C
 	}
 	if !t.tableTimer.Stop() {
 		<-t.tableTimer.C
 	}
+	if !t.botTimer.Stop() {
+		<-t.botTimer.C
+	}
 	t.log.Info("created new table", "table", t, "actionTimer", config.TableActionTimeout, "betTimer", config.BetTimeout, "tableTimer", config.TableDeleteTimeout)
+	t.seatBots()
+	t.handleEvents()
 	return t
 }
 
@@ -118,6 +145,8 @@ func (t *Table) cleanUp() {
 }
 
 func (t *Table) run(ctx context.Context) {
+	jackpotUpdates := t.lobby.jackpot.subscribe()
+	defer t.lobby.jackpot.unsubscribe(jackpotUpdates)
 	for {
 		select {
 		case <-ctx.Done():
@@ -134,23 +163,38 @@ func (t *Table) run(ctx context.Context) {
 			t.autoProgress()
 		case <-t.betTimer.C:
 			t.log.Info("BET TIMER EXPIRED")
+			t.betTimedOut()
 			err := t.game.StartRound()
-			if err != nil {
+			if errors.Is(err, game.ErrNoActivePlayers) && t.tournament != nil && t.tournament.running() {
+				// the tournament clock keeps running when nobody bets
+				t.game.SkipRound()
+				t.playTournamentHand()
+				t.betTimer.Reset(time.Duration(t.Config.BetTimeout) * time.Second)
+				t.broadcastGameState()
+			} else if err != nil {
 				t.log.Info("No active players found in game. Starting delete timer", "error", err)
 				t.tableTimer.Reset(time.Duration(t.Config.TableDeleteTimeout) * time.Minute)
 			}
 			t.autoProgress()
+		case <-t.insuranceTimer.C:
+			t.log.Info("INSURANCE TIMER EXPIRED")
+			if t.game.State != game.INSURANCE {
+				continue
+			}
+			t.endInsurance()
+			t.autoProgress()
 		case <-t.actionTimer.C:
 			t.log.Info("ACTION TIMER EXPIRED")
 			if t.game.State != game.PLAYER_TURN {
 				// we don't need to reset anything if there are no actions to be waited for. i.e. the table is dead
 				continue
 			}
-			t.game.Stay(t.game.CurrentPlayer())
+			t.actionTimedOut()
 			t.actionTimer.Reset(time.Duration(t.Config.TableActionTimeout) * time.Second)
-			if t.game.State == game.DEALER_TURN {
-				t.autoProgress()
-			}
+			t.autoProgress()
+		case <-t.botTimer.C:
+			t.playBotTurn()
+			t.autoProgress()
 		case <-t.tableTimer.C:
 			t.log.Info("KILLING TABLE")
 			t.sendDeleteMsg()
@@ -158,6 +202,8 @@ func (t *Table) run(ctx context.Context) {
 			return
 		case <-t.cleanupTicker.C:
 			t.removeInactivePlayers()
+		case amount := <-jackpotUpdates:
+			t.broadcast(protocol.JackpotDTO{Amount: amount})
 		}
 	}
 }
@@ -167,7 +213,12 @@ func (t *Table) sendDeleteMsg() {
 	t.lobby.inbound <- inboundMessage{msg, &Client{}}
 }
 
+// removeInactivePlayers gets everyone who left up from the table. Nobody is taken out of a round
+// being played, they go once it is over
 func (t *Table) removeInactivePlayers() {
+	if !t.betweenRounds() {
+		return
+	}
 	players := t.game.Players
 	for _, player := range players {
 		if player == nil {
@@ -175,11 +226,24 @@ func (t *Table) removeInactivePlayers() {
 		}
 		if player.ShouldRemove() {
 			t.log.Info("Removing player", "player_id", player.ID)
+			if t.tournament != nil {
+				t.tournament.stand(player)
+			}
 			t.game.RemovePlayer(player.ID)
+			delete(t.bots, player.ID)
 		}
 	}
 }
 
+// betweenRounds is true when no cards are in play, so seats can change hands
+func (t *Table) betweenRounds() bool {
+	switch t.game.State {
+	case game.WAIT_FOR_START, game.WAITING_FOR_BETS, game.RESOLVING_BETS:
+		return true
+	}
+	return false
+}
+
 func (t *Table) handleCommand(msg inboundMessage) {
 	switch msg.data.Type {
 	case protocol.MsgStartGame:
@@ -194,28 +258,116 @@ func (t *Table) handleCommand(msg inboundMessage) {
 		t.log.Debug("Client requested game state")
 		t.broadcastGameState()
 	case protocol.MsgPlaceBet:
-		value := protocol.ValueMessage{}
+		value := protocol.BetDTO{}
 		err := json.Unmarshal(msg.data.Data, &value)
 		if err != nil {
 			t.log.Error("Got bad data from command", "command", msg.data)
 		}
-		bet, err := strconv.Atoi(value.Value)
+		bet, err := protocol.DTOToBet(value)
 		if err != nil {
-			slog.Error("Unable to translate value to int", "error", err)
+			slog.Error("Unable to translate value to a bet", "error", err)
+		}
+		spot := t.betSpot(msg.client, value.Spot)
+		if spot == nil {
+			popup := CreatePopUp("You don't have that spot", "warn")
+			if popup != nil {
+				msg.client.send <- popup
+			}
+			return
 		}
-		err = t.game.PlaceBet(t.game.GetPlayer(msg.client.id), bet)
+		err = t.game.PlaceBet(spot, bet)
 		if err != nil {
+			var betErr *game.BetError
+			if errors.As(err, &betErr) {
+				betMsg := CreateBetError(betErr)
+				if betMsg != nil {
+					msg.client.send <- betMsg
+				}
+				return
+			}
 			popup := CreatePopUp("Cannot place bet right now", "warn")
 			if popup != nil {
 				msg.client.send <- popup
 			}
 			return
 		}
+	case protocol.MsgJackpotBet:
+		err := t.game.PlaceJackpotBet(t.jackpotSpot(msg.client))
+		if err != nil {
+			popup := CreatePopUp(err.Error(), "warn")
+			if popup != nil {
+				msg.client.send <- popup
+			}
+			return
+		}
+	case protocol.MsgAddSpot:
+		spot, err := t.game.AddSpot(t.game.GetPlayer(msg.client.id))
+		if err != nil {
+			popup := CreatePopUp(err.Error(), "warn")
+			if popup != nil {
+				msg.client.send <- popup
+			}
+			return
+		}
+		t.idToClient[spot.ID] = msg.client
+		t.log.Info("Player took another spot", "client", msg.client.id, "spot", spot.ID)
+	case protocol.MsgRemoveSpot:
+		value := protocol.ValueMessage{}
+		if len(msg.data.Data) > 0 {
+			err := json.Unmarshal(msg.data.Data, &value)
+			if err != nil {
+				t.log.Error("Got bad data from command", "command", msg.data)
+				popup := CreatePopUp("Unable to read which spot to give up", "warn")
+				if popup != nil {
+					msg.client.send <- popup
+				}
+				return
+			}
+		}
+		// no spot gives up the last one the player took
+		spots := t.game.Spots(msg.client.id)
+		i := len(spots) - 1
+		if value.Value != "" {
+			var err error
+			i, err = strconv.Atoi(value.Value)
+			if err != nil {
+				popup := CreatePopUp(fmt.Sprintf("%q isn't a spot", value.Value), "warn")
+				if popup != nil {
+					msg.client.send <- popup
+				}
+				return
+			}
+		}
+		var spot *game.Player
+		if i >= 0 && i < len(spots) {
+			spot = spots[i]
+		}
+		err := t.game.RemoveSpot(spot)
+		if err != nil {
+			popup := CreatePopUp(err.Error(), "warn")
+			if popup != nil {
+				msg.client.send <- popup
+			}
+			return
+		}
+		delete(t.idToClient, spot.ID)
+	case protocol.MsgAutoPlay:
+		value := protocol.ValueMessage{}
+		err := json.Unmarshal(msg.data.Data, &value)
+		if err != nil {
+			t.log.Error("Got bad data from command", "command", msg.data)
+		}
+		on, err := strconv.ParseBool(value.Value)
+		if err != nil {
+			t.log.Error("Unable to translate value to bool", "error", err)
+			return
+		}
+		t.setAutoPlay(msg.client, on)
 	case protocol.MsgDealCards:
 		t.game.DealCards()
 	case protocol.MsgHit:
 		t.log.Debug("Hitting", "client", msg.client.id)
-		err := t.game.Hit(t.game.GetPlayer(msg.client.id))
+		err := t.game.Hit(t.game.ActingSpot(msg.client.id))
 		if err != nil {
 			popup := CreatePopUp("It is not your turn", "warn")
 			if popup != nil {
@@ -225,7 +377,7 @@ func (t *Table) handleCommand(msg inboundMessage) {
 		}
 		t.actionTimer.Reset(time.Duration(t.Config.TableActionTimeout) * time.Second)
 	case protocol.MsgStand:
-		err := t.game.Stay(t.game.GetPlayer(msg.client.id))
+		err := t.game.Stay(t.game.ActingSpot(msg.client.id))
 		if err != nil {
 			popup := CreatePopUp("It is not your turn", "warn")
 			if popup != nil {
@@ -235,6 +387,108 @@ func (t *Table) handleCommand(msg inboundMessage) {
 		}
 		t.actionTimer.Reset(time.Duration(t.Config.TableActionTimeout) * time.Second)
 		t.log.Debug("Standing", "client", msg.client.id)
+	case protocol.MsgDoubleDown:
+		t.log.Debug("Doubling down", "client", msg.client.id)
+		err := t.game.DoubleDown(t.game.ActingSpot(msg.client.id))
+		if err != nil {
+			popup := CreatePopUp(err.Error(), "warn")
+			if popup != nil {
+				msg.client.send <- popup
+			}
+			return
+		}
+		t.actionTimer.Reset(time.Duration(t.Config.TableActionTimeout) * time.Second)
+	case protocol.MsgSplit:
+		t.log.Debug("Splitting", "client", msg.client.id)
+		err := t.game.Split(t.game.ActingSpot(msg.client.id))
+		if err != nil {
+			popup := CreatePopUp(err.Error(), "warn")
+			if popup != nil {
+				msg.client.send <- popup
+			}
+			return
+		}
+		t.actionTimer.Reset(time.Duration(t.Config.TableActionTimeout) * time.Second)
+	case protocol.MsgInsurance:
+		value := protocol.ValueMessage{}
+		err := json.Unmarshal(msg.data.Data, &value)
+		if err != nil {
+			t.log.Error("Got bad data from command", "command", msg.data)
+		}
+		amount, err := strconv.Atoi(value.Value)
+		if err != nil {
+			slog.Error("Unable to translate value to int", "error", err)
+		}
+		err = t.game.PlaceInsurance(t.game.ActingSpot(msg.client.id), amount)
+		if err != nil {
+			popup := CreatePopUp(err.Error(), "warn")
+			if popup != nil {
+				msg.client.send <- popup
+			}
+			return
+		}
+	case protocol.MsgEvenMoney:
+		err := t.game.TakeEvenMoney(t.game.ActingSpot(msg.client.id))
+		if err != nil {
+			popup := CreatePopUp(err.Error(), "warn")
+			if popup != nil {
+				msg.client.send <- popup
+			}
+			return
+		}
+	case protocol.MsgNoInsurance:
+		err := t.game.DeclineInsurance(t.game.ActingSpot(msg.client.id))
+		if err != nil {
+			popup := CreatePopUp(err.Error(), "warn")
+			if popup != nil {
+				msg.client.send <- popup
+			}
+			return
+		}
+	case protocol.MsgSurrender:
+		t.log.Debug("Surrendering", "client", msg.client.id)
+		err := t.game.Surrender(t.game.ActingSpot(msg.client.id))
+		if err != nil {
+			popup := CreatePopUp(err.Error(), "warn")
+			if popup != nil {
+				msg.client.send <- popup
+			}
+			return
+		}
+		if t.game.State == game.PLAYER_TURN {
+			t.actionTimer.Reset(time.Duration(t.Config.TableActionTimeout) * time.Second)
+		}
+	case protocol.MsgHint:
+		player := t.game.ActingSpot(msg.client.id)
+		advice, err := t.game.Advise(player)
+		if err != nil {
+			popup := CreatePopUp(err.Error(), "warn")
+			if popup != nil {
+				msg.client.send <- popup
+			}
+			return
+		}
+		hand := t.game.CurrentHandIndex
+		if t.game.State == game.INSURANCE {
+			// the insurance window is always about the first hand
+			hand = 0
+		}
+		hint := CreateStrategy(hand, "", advice)
+		if hint != nil {
+			msg.client.send <- hint
+		}
+	case protocol.MsgCountQuiz:
+		quiz := protocol.CountQuizDTO{}
+		err := json.Unmarshal(msg.data.Data, &quiz)
+		if err != nil {
+			t.log.Error("Got bad data from command", "command", msg.data)
+			return
+		}
+		if quiz.Guess == nil {
+			t.count.ask(msg.client.id)
+			return
+		}
+		t.gradeCountQuiz(msg.client, *quiz.Guess)
 	case protocol.MsgLeaveTable:
 		// intentionally left table
 		// press ctrl+c or leave button
@@ -246,11 +500,42 @@ func (t *Table) DisconnectPlayer(c *Client, intentional bool) {
 	player := t.game.GetPlayer(c.id)
 	if player != nil {
 		t.log.Info("Disconnecting player", "id", player.ID, "intentional?", intentional)
-		player.MarkDisconnected(intentional)
+	}
+	// every spot the player took goes with them. A spot in the round stands when its turn comes
+	// and gets up once the round is over
+	spots := t.game.Spots(c.id)
+	for _, spot := range spots {
+		spot.MarkDisconnected(intentional)
+	}
+	if t.game.State == game.PLAYER_TURN && slices.Contains(spots, t.game.CurrentPlayer()) {
+		t.game.AutoStay(t.game.CurrentPlayer())
 	}
 	if intentional {
-		t.game.RemovePlayer(player.ID)
+		t.removeInactivePlayers()
+	}
+}
+
+// betSpot is the spot a bet goes on. A bet for the first spot goes on the player's next spot
+// without a bet, so older clients that don't pick a spot still bet on every spot they have
+func (t *Table) betSpot(c *Client, spot *int) *game.Player {
+	if spot == nil {
+		return t.game.ActingSpot(c.id)
 	}
+	spots := t.game.Spots(c.id)
+	if *spot < 0 || *spot >= len(spots) {
+		return nil
+	}
+	return spots[*spot]
+}
+
+// jackpotSpot is the player's first spot with a bet on it and no jackpot bet yet
+func (t *Table) jackpotSpot(c *Client) *game.Player {
+	for _, spot := range t.game.Spots(c.id) {
+		if spot.Bet > 0 && spot.Jackpot == 0 {
+			return spot
+		}
+	}
+	return t.game.GetPlayer(c.id)
 }
 
 func (t *Table) cmdLeaveTable(c *Client) {
@@ -269,7 +554,11 @@ func (t *Table) promptCurrentPlayerTurn() {
 		slog.Error("Client not found in table")
 		return
 	}
-	popup := CreatePopUp("It is your turn!", "info")
+	message := "It is your turn!"
+	if spots := t.game.Spots(player.OwnerID()); len(spots) > 1 {
+		message = fmt.Sprintf("It is your turn on spot %d!", slices.Index(spots, player)+1)
+	}
+	popup := CreatePopUp(message, "info")
 	if popup != nil {
 		client.send <- popup
 	}
@@ -277,8 +566,8 @@ func (t *Table) promptCurrentPlayerTurn() {
 
 func (t *Table) promptForBets() {
 	for client := range t.clients {
-		player := t.game.GetPlayer(client.id)
-		if player.Bet == 0 {
+		player := t.game.ActingSpot(client.id)
+		if player != nil && player.Bet == 0 {
 			popup := CreatePopUp("Place your bet!", "info")
 			if popup != nil {
 				client.send <- popup
@@ -287,12 +576,104 @@ func (t *Table) promptForBets() {
 	}
 }
 
+func (t *Table) promptForInsurance() {
+	for client := range t.clients {
+		player := t.game.ActingSpot(client.id)
+		if player == nil || !player.IsActive() || len(player.Hands) == 0 {
+			continue
+		}
+		message := "Dealer shows an ace. Insurance?"
+		if player.Hands[0].GetState() == game.BLACKJACK {
+			message = "Dealer shows an ace. Even money?"
+		} else if t.game.DealerHand.Cards[0].Rank != game.ACE {
+			message = "Dealer shows a ten. Surrender?"
+		}
+		popup := CreatePopUp(message, "info")
+		if popup != nil {
+			client.send <- popup
+		}
+	}
+}
+
+// endInsurance closes the insurance window. The action timer only starts if the dealer didn't peek a blackjack
+func (t *Table) endInsurance() {
+	t.game.EndInsurance()
+	if t.game.State == game.RESOLVING_BETS {
+		return
+	}
+	t.actionTimer.Reset(time.Duration(t.Config.TableActionTimeout) * time.Second)
+}
+
+func (t *Table) announceDealerBlackjack() {
+	for client := range t.clients {
+		popup := CreatePopUp("Dealer has blackjack!", "info")
+		if popup != nil {
+			client.send <- popup
+		}
+	}
+}
+
+// announceSideBet tells the player how their side bet did on the deal
+func (t *Table) announceSideBet(data game.SideBetResolved) {
+	client, ok := t.idToClient[data.PlayerID]
+	if !ok {
+		return
+	}
+	message := fmt.Sprintf("%s lost", data.SideBet)
+	if data.Payout > 0 {
+		message = fmt.Sprintf("%s: %s pays %d", data.SideBet, data.Hand, data.Payout)
+	}
+	if popup := CreatePopUp(message, "info"); popup != nil {
+		client.send <- popup
+	}
+}
+
+// announceJackpot tells the table who hit the jackpot. Everyone else sees the pool drop
+func (t *Table) announceJackpot(data game.JackpotResolved) {
+	name := "Someone"
+	if p := t.game.GetPlayer(data.PlayerID); p != nil {
+		name = p.Name
+	}
+	t.broadcast(protocol.MessageToDTO(fmt.Sprintf("%s hit the jackpot with %s and won %d!", name, data.Hand, data.Payout), protocol.InfoMsg))
+}
+
+// reviewDecision tells the player how their decision compares to basic strategy and counts it
+// towards their strategy accuracy
+func (t *Table) reviewDecision(acted game.PlayerActed) {
+	if _, ok := t.bots[acted.PlayerID]; ok {
+		return
+	}
+	client, ok := t.idToClient[acted.PlayerID]
+	if !ok {
+		t.log.Error("Client not found in table", "id", acted.PlayerID)
+		return
+	}
+	review := CreateStrategy(acted.Hand, acted.Action, acted.Advice)
+	if review != nil {
+		select {
+		case client.send <- review:
+		default:
+			t.log.Warn("client send buffer full. Dropping message", "client", client.id, "type", review.Type)
+		}
+	}
+	err := t.db.RecordDecision(context.Background(), client.username, acted.Action != acted.Advice)
+	if err != nil {
+		t.log.Error("Unable to record strategy decision", "username", client.username, "error", err)
+	}
+}
+
 func (t *Table) autoProgress() {
 OuterLoop:
 	for {
 		switch t.game.State {
+		case game.WAIT_FOR_START:
+			// nobody is seated to play. Nothing to do until someone sits down
+			t.broadcastGameState()
+			return
 		case game.WAITING_FOR_BETS:
 			t.log.Debug("WAITING FOR MORE BETS")
+			t.placeBotBets()
+			t.rebetAutoPlayers()
 			if t.game.AllPlayersBet() {
 				t.betTimer.Stop()
 				t.game.StartRound()
@@ -306,12 +687,33 @@ OuterLoop:
 		case game.DEALING:
 			t.log.Debug("dealing cards")
 			t.game.DealCards()
-			t.actionTimer.Reset(time.Duration(t.Config.TableActionTimeout) * time.Second)
+			switch t.game.State {
+			case game.INSURANCE:
+				t.insuranceTimer.Reset(time.Duration(t.Config.InsuranceTimeout) * time.Second)
+				t.botInsurance()
+				t.autoPlayInsurance()
+				t.promptForInsurance()
+			case game.RESOLVING_BETS:
+				// the dealer peeked a blackjack. The round is over
+			default:
+				t.actionTimer.Reset(time.Duration(t.Config.TableActionTimeout) * time.Second)
+			}
+		case game.INSURANCE:
+			if !t.game.AllInsuranceDecided() {
+				// wait for the rest of the table or the insurance timer
+				t.broadcastGameState()
+				return
+			}
+			t.log.Debug("STOPPING INSURANCE TIMER")
+			t.insuranceTimer.Stop()
+			t.endInsurance()
 		case game.DEALER_TURN:
 			t.log.Debug("PLAYING DEALER")
 			t.game.PlayDealer()
 		case game.RESOLVING_BETS:
 			t.log.Debug("RESOLVING BETS")
+			// announce how the round ended before paying it out
+			t.handleEvents()
 			pmap, err := t.game.ResolveBets()
 			if err != nil {
 				slog.Error("Error in autoprogress. Unable to resolve bets", "error", err)
@@ -320,9 +722,15 @@ OuterLoop:
 			t.log.Info("Round results", "results", pmap)
 
 			t.StoreGameData(pmap)
+			t.settleBots(pmap)
+			t.playTournamentHand()
+			t.removeInactivePlayers()
+			t.seatWaiting()
 			t.betTimer.Reset(time.Duration(t.Config.BetTimeout) * time.Second)
 		default:
-			t.promptCurrentPlayerTurn()
+			if !t.scheduleBotTurn() {
+				t.promptCurrentPlayerTurn()
+			}
 			t.broadcastGameState()
 			break OuterLoop
 		}
@@ -330,29 +738,161 @@ OuterLoop:
 	}
 }
 
-func (t *Table) StoreGameData(results map[uuid.UUID]store.RoundResult) {
+func (t *Table) StoreGameData(results map[uuid.UUID][]store.RoundResult) {
 	slog.Info("STORING GAME DATA")
-	tempMap := map[uuid.UUID]*Client{}
-	for client := range t.clients {
-		tempMap[client.id] = client
+	if t.tournament != nil {
+		// tournament chips never touch the wallet
+		return
 	}
-
-	for playerId, result := range results {
-		client, ok := tempMap[playerId]
+	for playerId, playerResults := range results {
+		if _, ok := t.bots[playerId]; ok {
+			// bots have no user to keep stats for
+			continue
+		}
+		client, ok := t.idToClient[playerId]
+		if ok && !t.clients[client] {
+			// left the table
+			ok = false
+		}
 		if !ok {
 			slog.Error("player id not found in table clients", "id", playerId)
 			continue
 		}
 		githubId := client.username
-		err := t.db.RecordResult(context.Background(), githubId, result)
-		if err != nil {
-			slog.Error("Unable to record results to db", "username", githubId, "result", result)
+		for _, result := range playerResults {
+			err := t.db.RecordResult(context.Background(), githubId, result)
+			if err != nil {
+				slog.Error("Unable to record results to db", "username", githubId, "result", result)
+			}
+		}
+	}
+}
+
+// recordRound saves a finished round to the hand history
+func (t *Table) recordRound(events []game.Event) {
+	steps, err := game.ReplayRound(events)
+	if err != nil || len(steps) == 0 {
+		t.log.Error("Unable to replay round for hand history", "error", err)
+		return
+	}
+	final := steps[len(steps)-1]
+	payouts := map[uuid.UUID]int{}
+	for _, e := range events {
+		switch data := e.Data.(type) {
+		case game.HandResolved:
+			payouts[data.PlayerID] += data.Payout
+		case game.InsuranceResolved:
+			payouts[data.PlayerID] += data.Payout
+		case game.JackpotResolved:
+			payouts[data.PlayerID] += data.Payout
+		case game.SideBetResolved:
+			payouts[data.PlayerID] += data.Payout
+		}
+	}
+	eventData, err := json.Marshal(events)
+	if err != nil {
+		t.log.Error("Unable to save round events", "error", err)
+		return
+	}
+	dealer, _ := json.Marshal(final.DealerHand.Cards)
+	rr := store.RoundRecord{
+		TableID:    t.id,
+		Round:      events[len(events)-1].Round,
+		ShoeSeed:   t.shoeSeeds[len(t.shoeSeeds)-1].String(),
+		DealerHand: dealer,
+		Events:     eventData,
+		PlayedAt:   time.Now(),
+	}
+	// a player with more than one spot gets one record with the hands from all of them.
+	// Bots have no user to look the round up and can share a name, so they are only in the events
+	owners := []uuid.UUID{}
+	hands := map[uuid.UUID][][]game.Card{}
+	for seat, p := range final.Players {
+		if p.Bot {
+			continue
+		}
+		i := slices.Index(owners, p.OwnerID())
+		if i == -1 {
+			owners = append(owners, p.OwnerID())
+			rr.Players = append(rr.Players, store.RoundPlayerRecord{GithubID: p.Name, Seat: seat})
+			i = len(rr.Players) - 1
+		}
+		rr.Players[i].Bet += p.Bet + p.Insurance + p.Jackpot + p.SideBetTotal()
+		rr.Players[i].Payout += payouts[p.ID]
+		for _, h := range p.Hands {
+			hands[p.OwnerID()] = append(hands[p.OwnerID()], h.Cards)
+		}
+	}
+	for i, owner := range owners {
+		playerHands, ok := hands[owner]
+		if !ok {
+			playerHands = [][]game.Card{}
+		}
+		rr.Players[i].Hands, _ = json.Marshal(playerHands)
+	}
+	id, err := t.db.RecordRound(context.Background(), rr)
+	if err != nil {
+		t.log.Error("Unable to record round to db", "round", rr.Round, "error", err)
+		return
+	}
+	t.log.Debug("Recorded round", "round", rr.Round, "id", id)
+}
+
+// recordShoe keeps the seed of a new shoe so any hand dealt from it can be reconstructed.
+// The finished shoe's seed is revealed and the new shoe is committed to before any card comes out of it
+func (t *Table) recordShoe(seed game.Seed) {
+	if len(t.shoeSeeds) > 0 {
+		t.broadcast(t.shoeReveal(len(t.shoeSeeds)))
+	}
+	t.shoeSeeds = append(t.shoeSeeds, seed)
+	t.log.Info("New shoe", "shoe", len(t.shoeSeeds), "seed", seed.String(), "commitment", seed.Commitment(), "decks", t.game.Deck.Base().DeckCount)
+	t.broadcast(t.shoeCommit())
+}
+
+// shoeCommit is the commitment for the shoe currently being dealt
+func (t *Table) shoeCommit() protocol.ShoeCommitDTO {
+	return protocol.ShoeCommitDTO{
+		Shoe:       len(t.shoeSeeds),
+		Decks:      t.game.Deck.Base().DeckCount,
+		Variant:    t.game.Config.Variant.Name(),
+		Commitment: t.shoeSeeds[len(t.shoeSeeds)-1].Commitment(),
+	}
+}
+
+func (t *Table) shoeReveal(shoe int) protocol.ShoeRevealDTO {
+	seed := t.shoeSeeds[shoe-1]
+	return protocol.ShoeRevealDTO{
+		Shoe:       shoe,
+		Decks:      t.game.Deck.Base().DeckCount,
+		Variant:    t.game.Config.Variant.Name(),
+		Commitment: seed.Commitment(),
+		Seed:       seed.String(),
+	}
+}
+
+// broadcast sends a message to everyone at the table without blocking on slow clients
+func (t *Table) broadcast(dto any) {
+	wrapped, err := protocol.PackageMessage(dto)
+	if err != nil {
+		t.log.Error("unable to package message", "error", err)
+		return
+	}
+	for client := range t.clients {
+		select {
+		case client.send <- wrapped:
+		default:
+			t.log.Warn("client send buffer full. Dropping message", "client", client.id, "type", wrapped.Type)
 		}
 	}
 }
 
 func (t *Table) broadcastGameState() {
+	t.handleEvents()
 	gameData := protocol.GameToDTO(t.game)
+	if t.tournament != nil {
+		standings := t.tournament.DTO("")
+		gameData.Tournament = &standings
+	}
 	wrapped, err := protocol.PackageMessage(gameData)
 	if err != nil {
 		t.log.Error("unable to package message", "error", err)
@@ -377,6 +917,10 @@ func (t *Table) CreateDTO() protocol.TableDTO {
 		Id:             t.id,
 		Capacity:       t.maxPlayers,
 		CurrentPlayers: len(t.clients),
+		Rules:          protocol.RulesToDTO(t.game.Config.Rules),
+		Variant:        t.game.Config.Variant.Name(),
+		Tournament:     t.tournament != nil,
+		Profile:        t.Config.Profile,
 	}
 }
 
@@ -384,28 +928,80 @@ func (t *Table) RegisterClient(client *Client) {
 	t.log.Info("attempting to register client", "client", client.id)
 	playerReconnecting := t.game.GetPlayer(client.id) != nil
 	if !playerReconnecting {
-		user, err := t.db.GetOrCreateUser(context.Background(), client.username)
-		if err != nil {
-			slog.Error("error getting user", "username", client.username)
-			// probably should crash here?
-		}
-		p := game.NewPlayer(client.id, int(user.Wallet))
-		p.Name = client.username
-		t.game.AddPlayer(p)
+		t.seatClient(client)
 	}
 	t.clients[client] = true
 	t.idToClient[client.id] = client
+	t.count.join(client.id)
+	for _, spot := range t.game.Spots(client.id) {
+		t.idToClient[spot.ID] = client
+	}
+	if commit, err := protocol.PackageMessage(t.shoeCommit()); err == nil {
+		client.send <- commit
+	}
 	if t.game.State == game.WAIT_FOR_START {
 		t.game.State = game.WAITING_FOR_BETS
 	}
 }
 
+// seatClient sits a client down. A bot gives up its seat if the table is full. When the bot is
+// still playing a round the client waits for the seat until the round is over
+func (t *Table) seatClient(client *Client) {
+	if t.tournament != nil {
+		t.seatEntrant(client)
+		return
+	}
+	user, err := t.db.GetOrCreateUser(context.Background(), client.username)
+	if err != nil {
+		slog.Error("error getting user", "username", client.username)
+		// probably should crash here?
+	}
+	p := game.NewPlayer(client.id, int(user.Wallet))
+	p.Name = client.username
+	err = t.game.AddPlayer(p)
+	if err != nil && t.standUpBot() {
+		err = t.game.AddPlayer(p)
+	}
+	if err == nil {
+		return
+	}
+	if !t.betweenRounds() && len(t.bots) > 0 {
+		t.log.Info("Waiting for a bot's seat", "client", client.id)
+		t.waiting = append(t.waiting, client)
+		if popup := CreatePopUp("You'll get a seat when this round is over", "info"); popup != nil {
+			client.send <- popup
+		}
+		return
+	}
+	t.log.Warn("No seat for player", "client", client.id, "error", err)
+}
+
+// seatWaiting sits down the clients who have been waiting for the round to end
+func (t *Table) seatWaiting() {
+	waiting := t.waiting
+	t.waiting = nil
+	for _, client := range waiting {
+		if !t.clients[client] || t.game.GetPlayer(client.id) != nil {
+			// left while they waited
+			continue
+		}
+		t.seatClient(client)
+		for _, spot := range t.game.Spots(client.id) {
+			t.idToClient[spot.ID] = client
+		}
+	}
+}
+
 func (t *Table) UnregisterClient(client *Client) {
 	t.log.Info("attempting to unregister client", "client", client.id)
 	t.DisconnectPlayer(client, false)
 	if _, ok := t.clients[client]; ok {
 		delete(t.idToClient, client.id)
+		for _, spot := range t.game.Spots(client.id) {
+			delete(t.idToClient, spot.ID)
+		}
 		delete(t.clients, client)
+		t.count.leave(client.id)
 		close(client.send)
 		t.Metrics.ConnectedClients.Dec()
 	}
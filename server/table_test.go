@@ -3,11 +3,14 @@ package server
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"testing"
 
+	"github.com/dylanmccormick/blackjack-tui/game"
 	"github.com/dylanmccormick/blackjack-tui/protocol"
 	"github.com/dylanmccormick/blackjack-tui/store"
 	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus"
 )
 
 func TestCreateTable(t *testing.T) {
@@ -54,7 +57,7 @@ func TestAutoProgress(t *testing.T) {
 	tab.RegisterClient(client)
 	p := tab.game.GetPlayer(client.id)
 	tab.game.StartGame()
-	tab.game.PlaceBet(p, 5)
+	tab.game.PlaceBet(p, game.Bet{Main: 5})
 	tab.autoProgress()
 	tab.game.Stay(p)
 	tab.autoProgress()
@@ -67,3 +70,942 @@ func TestAutoProgress(t *testing.T) {
 		t.Logf("count: %d, msg: %#v\n", count, msgData)
 	}
 }
+
+func TestTableRulesFromConfig(t *testing.T) {
+	store, _ := store.NewStore(":memory:", "../sql/schema")
+	lobby := NewLobby(store, CreateMetrics())
+	config := Config{RulesConfig: RulesConfig{StandOnSoft17: ptr(false), BlackjackPayout: "6:5", Surrender: "early", ContinuousShuffle: ptr(true), MinBet: ptr(5), MaxBet: ptr(100), Charlie: ptr(6), DealerWinsTies: ptr(true), Bonus777: ptr(3)}}
+	ctx := context.WithValue(context.TODO(), "config", config)
+	tab := newTable(ctx, "test_table", lobby, store, CreateMetrics())
+
+	rules := tab.CreateDTO().Rules
+	if rules.StandOnSoft17 {
+		t.Errorf("stand_on_soft_17 not applied to the table")
+	}
+	if rules.BlackjackPayout != "6:5" {
+		t.Errorf("blackjack payout incorrect. expected=%s got=%s", "6:5", rules.BlackjackPayout)
+	}
+	if rules.Surrender != "early" {
+		t.Errorf("surrender rule incorrect. expected=%s got=%s", "early", rules.Surrender)
+	}
+	if rules.MinBet != 5 || rules.MaxBet != 100 {
+		t.Errorf("table limits incorrect. expected=%d-%d got=%d-%d", 5, 100, rules.MinBet, rules.MaxBet)
+	}
+	if rules.Charlie != 6 || !rules.DealerWinsTies || rules.Bonus678 != 0 || rules.Bonus777 != 3 {
+		t.Errorf("house rules incorrect. got=%+v", rules)
+	}
+	if _, ok := tab.game.Deck.(*game.CSM); !rules.ContinuousShuffle || !ok {
+		t.Errorf("continuous shuffle not applied to the table. got=%T", tab.game.Deck)
+	}
+}
+
+func TestTableRulesDefaults(t *testing.T) {
+	store, _ := store.NewStore(":memory:", "../sql/schema")
+	lobby := NewLobby(store, CreateMetrics())
+	// rules left out of the config keep the standard rules
+	config := Config{RulesConfig: RulesConfig{MaxBet: ptr(100)}}
+	ctx := context.WithValue(context.TODO(), "config", config)
+	tab := newTable(ctx, "test_table", lobby, store, CreateMetrics())
+
+	rules := tab.CreateDTO().Rules
+	if !rules.StandOnSoft17 || !rules.DoubleAfterSplit || rules.BlackjackPayout != "3:2" {
+		t.Errorf("rules not set in the config should be the defaults. got=%+v", rules)
+	}
+	if rules.MinBet != game.DefaultRules().MinBet || rules.MaxBet != 100 {
+		t.Errorf("table limits incorrect. expected=%d-%d got=%d-%d", game.DefaultRules().MinBet, 100, rules.MinBet, rules.MaxBet)
+	}
+}
+
+func TestTableVariantFromConfig(t *testing.T) {
+	store, _ := store.NewStore(":memory:", "../sql/schema")
+	lobby := NewLobby(store, CreateMetrics())
+	config := Config{RulesConfig: RulesConfig{Variant: "spanish21"}, DeckCount: 2}
+	ctx := context.WithValue(context.TODO(), "config", config)
+	tab := newTable(ctx, "test_table", lobby, store, CreateMetrics())
+
+	if variant := tab.CreateDTO().Variant; variant != game.VARIANT_SPANISH_21 {
+		t.Errorf("variant not applied to the table. expected=%s got=%s", game.VARIANT_SPANISH_21, variant)
+	}
+	if len(tab.game.Deck.Base().Cards) != 48*2 {
+		t.Errorf("Spanish 21 shoe has the wrong number of cards. expected=%d got=%d", 48*2, len(tab.game.Deck.Base().Cards))
+	}
+}
+
+func TestPlaceBetOutsideLimits(t *testing.T) {
+	store, _ := store.NewStore(":memory:", "../sql/schema")
+	lobby := NewLobby(store, CreateMetrics())
+	config := Config{RulesConfig: RulesConfig{StandOnSoft17: ptr(true), MinBet: ptr(10), MaxBet: ptr(100)}}
+	ctx := context.WithValue(context.TODO(), "config", config)
+	tab := newTable(ctx, "test_table", lobby, store, CreateMetrics())
+	client := clientHelper(1)[0]
+	tab.RegisterClient(client)
+	tab.game.StartGame()
+	for len(client.send) > 0 {
+		<-client.send
+	}
+
+	tab.handleCommand(inboundMessage{protocol.PackageClientMessage(protocol.MsgPlaceBet, "5"), client})
+	if len(client.send) != 1 {
+		t.Fatalf("Expected a bet error in send channel for client got=%d", len(client.send))
+	}
+	msg := <-client.send
+	if msg.Type != protocol.MsgBetError {
+		t.Fatalf("Expected message type %s got=%s", protocol.MsgBetError, msg.Type)
+	}
+	var betErr protocol.BetErrorDTO
+	err := json.Unmarshal(msg.Data, &betErr)
+	if err != nil {
+		t.Fatalf("Unable to unmarshal bet error. err=%v", err)
+	}
+	if betErr.Reason != "below_min" || betErr.MinBet != 10 {
+		t.Errorf("bet error incorrect. got=%#v", betErr)
+	}
+}
+
+func TestPlaceSideBets(t *testing.T) {
+	store, _ := store.NewStore(":memory:", "../sql/schema")
+	lobby := NewLobby(store, CreateMetrics())
+	config := Config{RulesConfig: RulesConfig{MinBet: ptr(10), PerfectPairs: map[string]int{"perfect_pair": 25, "mixed_pair": 6}}}
+	ctx := context.WithValue(context.TODO(), "config", config)
+	tab := newTable(ctx, "test_table", lobby, store, CreateMetrics())
+	client := clientHelper(1)[0]
+	tab.RegisterClient(client)
+	tab.game.StartGame()
+	for len(client.send) > 0 {
+		<-client.send
+	}
+
+	tab.handleCommand(inboundMessage{protocol.PackageBet(protocol.BetDTO{Main: 10, SideBets: map[string]int{"21+3": 5}}), client})
+	msg := <-client.send
+	var betErr protocol.BetErrorDTO
+	if err := json.Unmarshal(msg.Data, &betErr); err != nil || msg.Type != protocol.MsgBetError {
+		t.Fatalf("Expected a bet error for a side bet the table doesn't offer. got=%s", msg.Type)
+	}
+	if betErr.Reason != "side_not_offered" || betErr.SideBet != "21+3" {
+		t.Errorf("bet error incorrect. got=%#v", betErr)
+	}
+
+	tab.handleCommand(inboundMessage{protocol.PackageBet(protocol.BetDTO{Main: 10, SideBets: map[string]int{"perfect_pairs": 5}}), client})
+	p := tab.game.GetPlayer(client.id)
+	if p.Bet != 10 || p.SideBetTotal() != 5 {
+		t.Errorf("bet not placed. bet=%d side bets=%d", p.Bet, p.SideBetTotal())
+	}
+	if payouts := tab.CreateDTO().Rules.SidePayouts; len(payouts["perfect_pairs"]) != 2 || len(payouts["21+3"]) != 0 {
+		t.Errorf("side bet pay tables not sent to the table. got=%v", payouts)
+	}
+}
+
+func TestShuffleBroadcast(t *testing.T) {
+	store, _ := store.NewStore(":memory:", "../sql/schema")
+	lobby := NewLobby(store, CreateMetrics())
+	tab := newTable(context.TODO(), "test_table", lobby, store, CreateMetrics())
+	client := clientHelper(1)[0]
+	tab.RegisterClient(client)
+	for len(client.send) > 0 {
+		<-client.send
+	}
+
+	if err := tab.game.ShuffleShoe(); err != nil {
+		t.Fatalf("Unable to shuffle the shoe: %s", err)
+	}
+	tab.broadcastGameState()
+	for _, typ := range []string{protocol.MsgShoeReveal, protocol.MsgShoeCommit} {
+		if msg := <-client.send; msg.Type != typ {
+			t.Fatalf("Expected message type %s got=%s", typ, msg.Type)
+		}
+	}
+	msg := <-client.send
+	if msg.Type != protocol.MsgShuffle {
+		t.Fatalf("Expected message type %s got=%s", protocol.MsgShuffle, msg.Type)
+	}
+	msg = <-client.send
+	if msg.Type != protocol.MsgGameState {
+		t.Fatalf("Expected message type %s got=%s", protocol.MsgGameState, msg.Type)
+	}
+	tab.broadcastGameState()
+	if len(client.send) != 1 {
+		t.Errorf("Expected only the game state without a new shuffle. got=%d messages", len(client.send))
+	}
+}
+
+func TestShoeSeedRecorded(t *testing.T) {
+	store, _ := store.NewStore(":memory:", "../sql/schema")
+	lobby := NewLobby(store, CreateMetrics())
+	tab := newTable(context.TODO(), "test_table", lobby, store, CreateMetrics())
+	if len(tab.shoeSeeds) != 1 || tab.shoeSeeds[0] != tab.game.Deck.Base().Seed {
+		t.Fatalf("Expected the first shoe's seed to be recorded. got=%v", tab.shoeSeeds)
+	}
+
+	if err := tab.game.ShuffleShoe(); err != nil {
+		t.Fatalf("Unable to shuffle the shoe: %s", err)
+	}
+	tab.broadcastGameState()
+	if len(tab.shoeSeeds) != 2 || tab.shoeSeeds[1] != tab.game.Deck.Base().Seed {
+		t.Fatalf("Expected the new shoe's seed to be recorded. got=%v", tab.shoeSeeds)
+	}
+
+	// Reshuffling the discards mid-round keeps dealing from the same shoe
+	tab.handleEvent(game.Event{Type: game.EVENT_SHOE_SHUFFLED, Data: game.ShoeShuffled{Seed: tab.game.Deck.Base().Seed, Discards: true}})
+	if len(tab.shoeSeeds) != 2 {
+		t.Errorf("Mid-round reshuffle should not record a new shoe. got=%v", tab.shoeSeeds)
+	}
+}
+
+func TestContinuousShuffleKeepsOneShoe(t *testing.T) {
+	store, _ := store.NewStore(":memory:", "../sql/schema")
+	lobby := NewLobby(store, CreateMetrics())
+	config := Config{RulesConfig: RulesConfig{ContinuousShuffle: ptr(true)}}
+	ctx := context.WithValue(context.TODO(), "config", config)
+	tab := newTable(ctx, "test_table", lobby, store, CreateMetrics())
+	client := clientHelper(1)[0]
+	tab.RegisterClient(client)
+	for len(client.send) > 0 {
+		<-client.send
+	}
+
+	// the machine shuffles between every round without starting a new shoe
+	for range 5 {
+		if err := tab.game.ShuffleShoe(); err != nil {
+			t.Fatalf("Unable to shuffle the shoe: %s", err)
+		}
+		tab.broadcastGameState()
+	}
+	if len(tab.shoeSeeds) != 1 || tab.shoeSeeds[0] != tab.game.Deck.Base().Seed {
+		t.Errorf("Expected the machine to keep its first seed. got=%v", tab.shoeSeeds)
+	}
+	for len(client.send) > 0 {
+		if msg := <-client.send; msg.Type != protocol.MsgGameState {
+			t.Errorf("Expected only game states. got=%s", msg.Type)
+		}
+	}
+}
+
+func TestShoeCommitReveal(t *testing.T) {
+	store, _ := store.NewStore(":memory:", "../sql/schema")
+	lobby := NewLobby(store, CreateMetrics())
+	tab := newTable(context.TODO(), "test_table", lobby, store, CreateMetrics())
+	client := clientHelper(1)[0]
+	tab.RegisterClient(client)
+
+	msg := <-client.send
+	if msg.Type != protocol.MsgShoeCommit {
+		t.Fatalf("Expected message type %s got=%s", protocol.MsgShoeCommit, msg.Type)
+	}
+	commit := protocol.ShoeCommitDTO{}
+	json.Unmarshal(msg.Data, &commit)
+	if commit.Shoe != 1 || commit.Commitment != tab.game.Deck.Base().Seed.Commitment() {
+		t.Fatalf("Commitment incorrect. got=%#v", commit)
+	}
+	for len(client.send) > 0 {
+		<-client.send
+	}
+
+	if err := tab.game.ShuffleShoe(); err != nil {
+		t.Fatalf("Unable to shuffle the shoe: %s", err)
+	}
+	tab.broadcastGameState()
+	msg = <-client.send
+	if msg.Type != protocol.MsgShoeReveal {
+		t.Fatalf("Expected message type %s got=%s", protocol.MsgShoeReveal, msg.Type)
+	}
+	reveal := protocol.ShoeRevealDTO{}
+	json.Unmarshal(msg.Data, &reveal)
+	seed, err := game.ParseSeed(reveal.Seed)
+	if err != nil {
+		t.Fatalf("Unable to parse revealed seed: %s", err)
+	}
+	if reveal.Shoe != 1 || seed.Commitment() != commit.Commitment {
+		t.Errorf("Revealed seed does not match the published commitment. got=%#v", reveal)
+	}
+	msg = <-client.send
+	if msg.Type != protocol.MsgShoeCommit {
+		t.Fatalf("Expected message type %s got=%s", protocol.MsgShoeCommit, msg.Type)
+	}
+	json.Unmarshal(msg.Data, &commit)
+	if commit.Shoe != 2 || commit.Commitment != tab.game.Deck.Base().Seed.Commitment() {
+		t.Errorf("New shoe commitment incorrect. got=%#v", commit)
+	}
+}
+
+func TestTableHandlesGameEvents(t *testing.T) {
+	db, _ := store.NewStore(":memory:", "../sql/schema")
+	registry := prometheus.NewRegistry()
+	metrics := NewMetrics(registry)
+	lobby := NewLobby(db, metrics)
+	tab := newTable(context.TODO(), "test_table", lobby, db, metrics)
+	client := clientHelper(1)[0]
+	tab.RegisterClient(client)
+	for len(client.send) > 0 {
+		<-client.send
+	}
+
+	tab.handleEvent(game.Event{Type: game.EVENT_DEALER_PEEKED, Data: game.DealerPeeked{Blackjack: true}})
+	msg := <-client.send
+	if msg.Type != protocol.MsgPopUp {
+		t.Fatalf("Expected the dealer blackjack to be announced. got=%s", msg.Type)
+	}
+	tab.handleEvent(game.Event{Type: game.EVENT_DEALER_PEEKED, Data: game.DealerPeeked{Blackjack: false}})
+	if len(client.send) != 0 {
+		t.Errorf("Nothing should be announced when the dealer doesn't have blackjack")
+	}
+
+	tab.handleEvent(game.Event{Type: game.EVENT_HAND_RESOLVED, Data: game.HandResolved{Outcome: store.Won, Bet: 10, Payout: 20}})
+	tab.handleEvent(game.Event{Type: game.EVENT_BET_PLACED, Data: game.BetPlaced{Amount: 25}})
+	families, err := registry.Gather()
+	if err != nil {
+		t.Fatalf("Unable to gather metrics: %s", err)
+	}
+	got := map[string]float64{}
+	for _, f := range families {
+		for _, m := range f.GetMetric() {
+			got[f.GetName()] += m.GetCounter().GetValue() + float64(m.GetHistogram().GetSampleCount())
+		}
+	}
+	if got["blackjack_hands_total"] != 1 {
+		t.Errorf("Won hand not counted. got=%v", got["blackjack_hands_total"])
+	}
+	if got["blackjack_bet_amount"] != 1 {
+		t.Errorf("Bet amount not observed. got=%v", got["blackjack_bet_amount"])
+	}
+	if got["blackjack_shoe_shuffles_total"] != 1 {
+		t.Errorf("The table's first shoe should be counted. got=%v", got["blackjack_shoe_shuffles_total"])
+	}
+}
+
+func TestRoundRecorded(t *testing.T) {
+	db, _ := store.NewStore(":memory:", "../sql/schema")
+	lobby := NewLobby(db, CreateMetrics())
+	tab := newTable(context.TODO(), "test_table", lobby, db, CreateMetrics())
+	client := clientHelper(1)[0]
+	client.send = make(chan *protocol.TransportMessage, 100)
+	client.username = "p1"
+	tab.RegisterClient(client)
+	shoe := tab.game.Deck.Base()
+	shoe.Cards = append([]game.Card{game.NewCard("spade", 10), game.NewCard("spade", 9), game.NewCard("heart", 10), game.NewCard("heart", 7)}, shoe.Cards...)
+	p := tab.game.GetPlayer(client.id)
+	tab.game.StartGame()
+	tab.game.PlaceBet(p, game.Bet{Main: 5})
+	tab.autoProgress()
+	tab.game.Stay(p)
+	tab.autoProgress()
+
+	rounds, err := db.RecentRounds(context.Background(), "p1", HISTORY_PAGE_SIZE, 0)
+	if err != nil {
+		t.Fatalf("Unable to get hand history: %s", err)
+	}
+	if len(rounds) != 1 {
+		t.Fatalf("Expected the finished round to be recorded. got=%d", len(rounds))
+	}
+	if rounds[0].TableID != "test_table" || rounds[0].Round != 1 || rounds[0].Bet != 5 {
+		t.Errorf("Round recorded incorrectly. got=%+v", rounds[0])
+	}
+	rr, err := db.GetRound(context.Background(), rounds[0].ID)
+	if err != nil {
+		t.Fatalf("Unable to get round: %s", err)
+	}
+	if rr.ShoeSeed != tab.shoeSeeds[0].String() {
+		t.Errorf("Round should be recorded with its shoe's seed. expected=%s got=%s", tab.shoeSeeds[0], rr.ShoeSeed)
+	}
+
+	for len(client.send) > 0 {
+		<-client.send
+	}
+	lobby.sendHistory(context.Background(), client, 0)
+	msg := <-client.send
+	if msg.Type != protocol.MsgHistory {
+		t.Fatalf("Expected hand history. got=%s", msg.Type)
+	}
+	lobby.sendRound(context.Background(), client, rounds[0].ID)
+	msg = <-client.send
+	if msg.Type != protocol.MsgRound {
+		t.Fatalf("Expected the round. got=%s", msg.Type)
+	}
+	var round protocol.RoundDetailDTO
+	json.Unmarshal(msg.Data, &round)
+	if len(round.Steps) == 0 || round.Commitment != tab.shoeSeeds[0].Commitment() {
+		t.Errorf("Round sent incorrectly. got=%+v", round)
+	}
+
+	other := clientHelper(1)[0]
+	other.username = "p2"
+	lobby.sendRound(context.Background(), other, rounds[0].ID)
+	msg = <-other.send
+	if msg.Type != protocol.MsgPopUp {
+		t.Errorf("Players should only see rounds they played. got=%s", msg.Type)
+	}
+}
+
+func TestMultipleSpots(t *testing.T) {
+	db, _ := store.NewStore(":memory:", "../sql/schema")
+	lobby := NewLobby(db, CreateMetrics())
+	tab := newTable(context.TODO(), "test_table", lobby, db, CreateMetrics())
+	client := clientHelper(1)[0]
+	client.send = make(chan *protocol.TransportMessage, 100)
+	client.username = "p1"
+	tab.RegisterClient(client)
+	tab.game.GetPlayer(client.id).Wallet = 100
+
+	tab.handleCommand(inboundMessage{protocol.PackageClientMessage(protocol.MsgAddSpot, ""), client})
+	spots := tab.game.Spots(client.id)
+	if len(spots) != 2 || tab.idToClient[spots[1].ID] != client {
+		t.Fatalf("Expected the player to take a second spot. got=%d", len(spots))
+	}
+	for len(client.send) > 0 {
+		<-client.send
+	}
+	tab.handleCommand(inboundMessage{protocol.PackageClientMessage(protocol.MsgAddSpot, ""), client})
+	if msg := <-client.send; msg.Type != protocol.MsgPopUp {
+		t.Errorf("Expected a warning taking more spots than the table allows. got=%s", msg.Type)
+	}
+
+	shoe := tab.game.Deck.Base()
+	shoe.Cards = append([]game.Card{
+		game.NewCard("spade", 10), game.NewCard("heart", 9), game.NewCard("spade", 10), game.NewCard("heart", 8),
+		game.NewCard("club", 10), game.NewCard("club", 9),
+	}, shoe.Cards...)
+	tab.game.StartGame()
+	tab.handleCommand(inboundMessage{protocol.PackageBet(protocol.BetDTO{Main: 10, Spot: ptr(1)}), client})
+	if spots[0].Bet != 0 || spots[1].Bet != 10 {
+		t.Fatalf("Expected the bet on the second spot. got=%d %d", spots[0].Bet, spots[1].Bet)
+	}
+	tab.handleCommand(inboundMessage{protocol.PackageBet(protocol.BetDTO{Main: 5, Spot: ptr(0)}), client})
+	if spots[0].Bet != 5 || spots[1].Bet != 10 {
+		t.Fatalf("Expected a bet on each spot. got=%d %d", spots[0].Bet, spots[1].Bet)
+	}
+	tab.autoProgress()
+	if tab.game.State != game.PLAYER_TURN {
+		t.Fatalf("Expected the round to start once every spot bet. got=%s", tab.game.State)
+	}
+	dto := protocol.GameToDTO(tab.game)
+	if !dto.Players[0].Acting || dto.Players[1].Acting || dto.Players[1].Spot != 1 {
+		t.Errorf("Expected the first spot to be acting. got=%+v", dto.Players[:2])
+	}
+	// stand goes to whichever spot is up
+	tab.handleCommand(inboundMessage{protocol.PackageClientMessage(protocol.MsgStand, ""), client})
+	tab.handleCommand(inboundMessage{protocol.PackageClientMessage(protocol.MsgStand, ""), client})
+	tab.autoProgress()
+
+	// 20 beats 19 for 5 and 17 loses 10. The stored wallet started at 1000
+	user, err := db.DB.GetUserByUsername(context.Background(), "p1")
+	if err != nil {
+		t.Fatalf("Unable to get user. err=%v", err)
+	}
+	if user.Wallet != 995 || user.HandsWon != 1 || user.HandsLost != 1 {
+		t.Errorf("Both spots should be recorded against one wallet. got=%+v", user)
+	}
+	rounds, err := db.RecentRounds(context.Background(), "p1", HISTORY_PAGE_SIZE, 0)
+	if err != nil || len(rounds) != 1 {
+		t.Fatalf("Expected one round in the history. got=%d err=%v", len(rounds), err)
+	}
+	if rounds[0].Bet != 15 || rounds[0].Payout != 10 {
+		t.Errorf("Both spots should be in one history record. got=%+v", rounds[0])
+	}
+
+	for len(client.send) > 0 {
+		<-client.send
+	}
+	tab.handleCommand(inboundMessage{protocol.PackageClientMessage(protocol.MsgRemoveSpot, "second"), client})
+	if len(tab.game.Spots(client.id)) != 2 {
+		t.Fatalf("A spot that can't be read shouldn't give up any spot")
+	}
+	if msg := <-client.send; msg.Type != protocol.MsgPopUp {
+		t.Errorf("Expected a warning for a spot that can't be read. got=%s", msg.Type)
+	}
+	// no spot gives up the last one
+	tab.handleCommand(inboundMessage{protocol.PackageClientMessage(protocol.MsgRemoveSpot, ""), client})
+	if left := tab.game.Spots(client.id); len(left) != 1 || left[0] != spots[0] {
+		t.Errorf("Expected the second spot to be given up")
+	}
+	tab.handleCommand(inboundMessage{protocol.PackageClientMessage(protocol.MsgAddSpot, ""), client})
+	tab.DisconnectPlayer(client, true)
+	if len(tab.game.Spots(client.id)) != 0 {
+		t.Errorf("Every spot should go when the player leaves")
+	}
+}
+
+func TestLeaveWithSpotsMidRound(t *testing.T) {
+	db, _ := store.NewStore(":memory:", "../sql/schema")
+	lobby := NewLobby(db, CreateMetrics())
+	tab := newTable(context.TODO(), "test_table", lobby, db, CreateMetrics())
+	clients := clientHelper(2)
+	for i, c := range clients {
+		c.send = make(chan *protocol.TransportMessage, 100)
+		c.username = fmt.Sprintf("p%d", i+1)
+	}
+	tab.RegisterClient(clients[0])
+	tab.handleCommand(inboundMessage{protocol.PackageClientMessage(protocol.MsgAddSpot, ""), clients[0]})
+	tab.RegisterClient(clients[1])
+	spots := tab.game.Spots(clients[0].id)
+	p2 := tab.game.GetPlayer(clients[1].id)
+
+	shoe := tab.game.Deck.Base()
+	for range 8 {
+		shoe.Cards = append([]game.Card{game.NewCard("spade", 5)}, shoe.Cards...)
+	}
+	tab.game.StartGame()
+	for _, spot := range spots {
+		tab.game.PlaceBet(spot, game.Bet{Main: 10})
+	}
+	tab.game.PlaceBet(p2, game.Bet{Main: 10})
+	tab.autoProgress()
+	if tab.game.State != game.PLAYER_TURN || tab.game.CurrentPlayer() != spots[0] {
+		t.Fatalf("Expected the first spot to be acting. state=%s", tab.game.State)
+	}
+
+	// both spots stand and stay seated until the round is over. The next player still gets a turn
+	tab.DisconnectPlayer(clients[0], true)
+	if tab.game.CurrentPlayer() != p2 {
+		t.Fatalf("Expected the turn to pass both spots")
+	}
+	for _, spot := range spots {
+		if tab.game.GetPlayer(spot.ID) != spot {
+			t.Fatalf("Expected the spots to stay in the round")
+		}
+	}
+	tab.game.Stay(p2)
+	tab.autoProgress()
+	if tab.game.State != game.WAITING_FOR_BETS {
+		t.Fatalf("Round did not finish. got=%s", tab.game.State)
+	}
+	if len(tab.game.Spots(clients[0].id)) != 0 {
+		t.Errorf("Every spot should get up once the round is over")
+	}
+	user, err := db.DB.GetUserByUsername(context.Background(), "p1")
+	if err != nil {
+		t.Fatalf("Unable to get user. err=%v", err)
+	}
+	if user.HandsPlayed != 2 {
+		t.Errorf("Expected both spots to be played out. got=%d", user.HandsPlayed)
+	}
+}
+
+func TestStrategyReview(t *testing.T) {
+	db, _ := store.NewStore(":memory:", "../sql/schema")
+	lobby := NewLobby(db, CreateMetrics())
+	tab := newTable(context.TODO(), "test_table", lobby, db, CreateMetrics())
+	client := clientHelper(1)[0]
+	client.send = make(chan *protocol.TransportMessage, 100)
+	client.username = "p1"
+	tab.RegisterClient(client)
+	shoe := tab.game.Deck.Base()
+	shoe.Cards = append([]game.Card{game.NewCard("spade", 10), game.NewCard("spade", 6), game.NewCard("heart", 10), game.NewCard("heart", 7)}, shoe.Cards...)
+	p := tab.game.GetPlayer(client.id)
+	tab.game.StartGame()
+	tab.game.PlaceBet(p, game.Bet{Main: 5})
+	tab.autoProgress()
+	for len(client.send) > 0 {
+		<-client.send
+	}
+
+	strategy := func(msg *protocol.TransportMessage) protocol.StrategyDTO {
+		t.Helper()
+		if msg.Type != protocol.MsgStrategy {
+			t.Fatalf("Expected a strategy message. got=%s", msg.Type)
+		}
+		var dto protocol.StrategyDTO
+		json.Unmarshal(msg.Data, &dto)
+		return dto
+	}
+	tab.handleCommand(inboundMessage{protocol.PackageClientMessage(protocol.MsgHint, ""), client})
+	hint := strategy(<-client.send)
+	if hint.Action != "" || hint.Advice != string(game.ACTION_HIT) {
+		t.Errorf("Expected a hint to hit 16 against a ten. got=%+v", hint)
+	}
+
+	tab.handleCommand(inboundMessage{protocol.PackageClientMessage(protocol.MsgStand, ""), client})
+	tab.handleEvents()
+	review := strategy(<-client.send)
+	if review.Action != string(game.ACTION_STAND) || review.Advice != string(game.ACTION_HIT) {
+		t.Errorf("Expected the stand to be reviewed against a hit. got=%+v", review)
+	}
+	user, err := db.DB.GetUserByUsername(context.Background(), "p1")
+	if err != nil {
+		t.Fatalf("Unable to get user: %s", err)
+	}
+	if user.StrategyDecisions != 1 || user.StrategyDeviations != 1 {
+		t.Errorf("Expected one decision that deviated. decisions=%d deviations=%d", user.StrategyDecisions, user.StrategyDeviations)
+	}
+}
+
+func TestCountQuizRecorded(t *testing.T) {
+	db, _ := store.NewStore(":memory:", "../sql/schema")
+	lobby := NewLobby(db, CreateMetrics())
+	tab := newTable(context.TODO(), "test_table", lobby, db, CreateMetrics())
+	clients := clientHelper(2)
+	for i, c := range clients {
+		c.send = make(chan *protocol.TransportMessage, 100)
+		c.username = fmt.Sprintf("p%d", i+1)
+	}
+	c1, c2 := clients[0], clients[1]
+	tab.RegisterClient(c1)
+
+	dto := protocol.GameToDTO(tab.game)
+	if dto.CardsInShoe != len(tab.game.Deck.Base().Cards) {
+		t.Errorf("Game state should say how many cards are left. expected=%d got=%d", len(tab.game.Deck.Base().Cards), dto.CardsInShoe)
+	}
+
+	shoe := tab.game.Deck.Base()
+	shoe.Cards = append([]game.Card{
+		game.NewCard("spade", 10), game.NewCard("heart", 10), game.NewCard("club", 10), game.NewCard("heart", 7),
+		game.NewCard("spade", 5),
+	}, shoe.Cards...)
+	tab.game.StartGame()
+	tab.game.PlaceBet(tab.game.GetPlayer(c1.id), game.Bet{Main: 5})
+	tab.autoProgress()
+	// three tens showing. The quiz is held at -3 while the player hits a 5
+	tab.handleCommand(inboundMessage{protocol.PackageCountQuiz(nil), c1})
+	tab.handleCommand(inboundMessage{protocol.PackageClientMessage(protocol.MsgHit, ""), c1})
+	tab.autoProgress()
+	if tab.count.count(c1.id) != -2 {
+		t.Fatalf("table count incorrect. expected=%d got=%d", -2, tab.count.count(c1.id))
+	}
+	// sat down after the cards were cleared away. Their count starts at zero
+	tab.RegisterClient(c2)
+
+	answer := func(c *Client, guess int) protocol.CountQuizDTO {
+		for len(c.send) > 0 {
+			<-c.send
+		}
+		tab.handleCommand(inboundMessage{protocol.PackageCountQuiz(&guess), c})
+		msg := <-c.send
+		if msg.Type != protocol.MsgCountQuiz {
+			t.Fatalf("Expected the graded quiz. got=%s", msg.Type)
+		}
+		result := protocol.CountQuizDTO{}
+		json.Unmarshal(msg.Data, &result)
+		return result
+	}
+	if result := answer(c1, -3); !result.Correct || result.Count != -3 {
+		t.Errorf("Expected the count the quiz asked for. got=%+v", result)
+	}
+	if result := answer(c1, 5); result.Correct || result.Count != -2 {
+		t.Errorf("Expected the guess to be checked against the table's count. got=%+v", result)
+	}
+	if result := answer(c2, 0); !result.Correct || result.Count != 0 {
+		t.Errorf("Expected cards dealt before the player sat down to be left out. got=%+v", result)
+	}
+
+	expected := map[string][2]int64{"p1": {2, 1}, "p2": {1, 1}}
+	for name, quizzes := range expected {
+		user, err := db.DB.GetUserByUsername(context.Background(), name)
+		if err != nil {
+			t.Fatalf("Unable to get user: %s", err)
+		}
+		if user.CountQuizzes != quizzes[0] || user.CountQuizzesCorrect != quizzes[1] {
+			t.Errorf("%s quizzes incorrect. expected=%v got=%d %d", name, quizzes, user.CountQuizzes, user.CountQuizzesCorrect)
+		}
+	}
+
+	tab.game.State = game.WAITING_FOR_BETS
+	tab.game.ShuffleShoe()
+	tab.handleEvents()
+	if tab.count.count(c1.id) != 0 || tab.count.count(c2.id) != 0 {
+		t.Errorf("Expected the count to start over on a shuffle. got=%d %d", tab.count.count(c1.id), tab.count.count(c2.id))
+	}
+}
+
+func TestBots(t *testing.T) {
+	db, _ := store.NewStore(":memory:", "../sql/schema")
+	lobby := NewLobby(db, CreateMetrics())
+	config := Config{RulesConfig: RulesConfig{StandOnSoft17: ptr(true)}, Bots: []BotConfig{
+		{Name: "basic"},
+		{Strategy: game.STRATEGY_NEVER_BUST, Betting: BETTING_MARTINGALE, Bet: 5, Wallet: 50},
+		{Strategy: game.STRATEGY_RANDOM, Betting: BETTING_RANDOM},
+		{Betting: "double_or_nothing"},
+		{Name: "basic"}, // bots can share a name
+	}}
+	ctx := context.WithValue(context.Background(), "config", config)
+	tab := newTable(ctx, "test_table", lobby, db, CreateMetrics())
+	if len(tab.bots) != 4 {
+		t.Fatalf("Expected a bot in every seat for each valid bot config. got=%d", len(tab.bots))
+	}
+	names := []string{}
+	for _, p := range protocol.GameToDTO(tab.game).Players[:4] {
+		if !p.Bot {
+			t.Errorf("Player %s should be marked as a bot", p.Name)
+		}
+		names = append(names, p.Name)
+	}
+	if names[0] != "bot_basic" || names[1] != "bot_2" {
+		t.Errorf("Bot names incorrect. got=%v", names)
+	}
+
+	// nobody to play with. The bots stay out of the round
+	tab.game.StartGame()
+	tab.autoProgress()
+	if tab.game.AllPlayersBet() || tab.game.StartRound() == nil {
+		t.Fatalf("Bots should not play at a table without people")
+	}
+
+	clients := clientHelper(2)
+	for i, c := range clients {
+		c.send = make(chan *protocol.TransportMessage, 1000)
+		c.username = fmt.Sprintf("p%d", i+1)
+		tab.RegisterClient(c)
+	}
+	if len(tab.bots) != 3 {
+		t.Fatalf("A bot should get up when the table is full. bots=%d", len(tab.bots))
+	}
+	for _, c := range clients {
+		if tab.game.GetPlayer(c.id) == nil {
+			t.Fatalf("Player %s did not get a seat", c.username)
+		}
+	}
+
+	for round := 1; round <= 20; round++ {
+		tab.game.State = game.WAITING_FOR_BETS
+		for _, c := range clients {
+			tab.game.PlaceBet(tab.game.GetPlayer(c.id), game.Bet{Main: 10})
+		}
+		tab.autoProgress()
+		for tab.game.State != game.WAITING_FOR_BETS {
+			switch tab.game.State {
+			case game.INSURANCE:
+				for _, c := range clients {
+					tab.game.DeclineInsurance(tab.game.GetPlayer(c.id))
+				}
+			case game.PLAYER_TURN:
+				if tab.botThinking {
+					tab.playBotTurn()
+				} else {
+					tab.game.Stay(tab.game.CurrentPlayer())
+				}
+			default:
+				t.Fatalf("Round %d stuck in %s", round, tab.game.State)
+			}
+			tab.autoProgress()
+		}
+		if tab.game.Round != round+1 {
+			t.Fatalf("Round did not finish. expected=%d got=%d", round+1, tab.game.Round)
+		}
+	}
+
+	for _, b := range tab.bots {
+		if _, err := db.DB.GetUserByUsername(context.Background(), b.player.Name); err == nil {
+			t.Errorf("Bot %s should not have a user", b.player.Name)
+		}
+	}
+	user, err := db.DB.GetUserByUsername(context.Background(), "p1")
+	if err != nil {
+		t.Fatalf("Unable to get user: %s", err)
+	}
+	if user.HandsPlayed < 20 {
+		t.Errorf("Expected the player's rounds to be stored. got=%d", user.HandsPlayed)
+	}
+	rounds, err := db.RecentRounds(context.Background(), "p1", 20, 0)
+	if err != nil || len(rounds) != 20 {
+		t.Fatalf("Expected every round in the history. got=%d err=%v", len(rounds), err)
+	}
+	rr, err := db.GetRound(context.Background(), rounds[0].ID)
+	if err != nil || len(rr.Players) != 2 {
+		t.Errorf("Only the people at the table should have a history record. got=%+v err=%v", rr.Players, err)
+	}
+}
+
+func TestBotGetsUpAfterRound(t *testing.T) {
+	db, _ := store.NewStore(":memory:", "../sql/schema")
+	lobby := NewLobby(db, CreateMetrics())
+	config := Config{Bots: []BotConfig{{}, {}, {}, {}}}
+	ctx := context.WithValue(context.Background(), "config", config)
+	tab := newTable(ctx, "test_table", lobby, db, CreateMetrics())
+	clients := clientHelper(2)
+	for i, c := range clients {
+		c.send = make(chan *protocol.TransportMessage, 1000)
+		c.username = fmt.Sprintf("p%d", i+1)
+	}
+	// the person sits down behind every bot
+	tab.RegisterClient(clients[0])
+	p1 := tab.game.GetPlayer(clients[0].id)
+	if p1 == nil || tab.game.Players[len(tab.game.Players)-1] != p1 {
+		t.Fatalf("Expected the player in the last seat")
+	}
+	for range 12 {
+		tab.game.Deck.Base().Cards = append([]game.Card{{Suit: "spade", Rank: 5}}, tab.game.Deck.Base().Cards...)
+	}
+	tab.game.StartGame()
+	tab.game.PlaceBet(p1, game.Bet{Main: 10})
+	tab.autoProgress()
+	if tab.game.State != game.PLAYER_TURN {
+		t.Fatalf("Expected the bots to be playing. got=%s", tab.game.State)
+	}
+
+	// the table is full. A bot has to finish the round before it gets up
+	tab.RegisterClient(clients[1])
+	if len(tab.bots) != 4 || tab.game.GetPlayer(clients[1].id) != nil {
+		t.Fatalf("Expected the bot to keep its seat until the round is over. bots=%d", len(tab.bots))
+	}
+	tookTurn := false
+	for tab.game.State == game.PLAYER_TURN {
+		if tab.game.CurrentPlayer() == p1 {
+			tookTurn = true
+			tab.game.Stay(p1)
+		} else {
+			tab.playBotTurn()
+		}
+		tab.autoProgress()
+	}
+	if !tookTurn {
+		t.Errorf("Expected the player behind the bot to get a turn")
+	}
+	if tab.game.State != game.WAITING_FOR_BETS || tab.game.Round != 2 {
+		t.Fatalf("Round did not finish. state=%s round=%d", tab.game.State, tab.game.Round)
+	}
+	if len(tab.bots) != 3 || tab.game.GetPlayer(clients[1].id) == nil {
+		t.Errorf("Expected the bot to give up its seat once the round was over. bots=%d", len(tab.bots))
+	}
+}
+
+func TestFitBet(t *testing.T) {
+	rules := game.RuleSet{MinBet: 10, MaxBet: 100, BetIncrement: 5}
+	tests := []struct {
+		bet, wallet, expected int
+	}{
+		{1, 1000, 10},
+		{42, 1000, 40},
+		{500, 1000, 100},
+		{80, 33, 30},
+	}
+	for i, tt := range tests {
+		got := fitBet(rules, tt.bet, tt.wallet)
+		if got != tt.expected {
+			t.Errorf("tests[%d] bet incorrect. expected=%d got=%d", i, tt.expected, got)
+		}
+	}
+}
+
+func TestTimeoutPolicy(t *testing.T) {
+	db, _ := store.NewStore(":memory:", "../sql/schema")
+	lobby := NewLobby(db, CreateMetrics())
+	config := Config{
+		BetTimeout:         30,
+		TableActionTimeout: 30,
+		TableDeleteTimeout: 5,
+		DeckCount:          6,
+		RulesConfig:        RulesConfig{Timeout: &TimeoutPolicy{Play: TIMEOUT_BASIC, SitOutAfter: 3, Rebet: true}},
+	}
+	ctx := context.WithValue(context.TODO(), "config", config)
+	tab := newTable(ctx, "test_table", lobby, db, CreateMetrics())
+	client := clientHelper(1)[0]
+	client.send = make(chan *protocol.TransportMessage, 100)
+	client.username = "p1"
+	if _, err := db.GetOrCreateUser(context.Background(), client.username); err != nil {
+		t.Fatalf("Unable to create user. err=%v", err)
+	}
+	tab.RegisterClient(client)
+	p := tab.game.GetPlayer(client.id)
+	p.Wallet = 100
+
+	shoe := tab.game.Deck.Base()
+	shoe.Cards = append([]game.Card{
+		// round 1. 12 against a 2 hits to 19 and the dealer busts
+		game.NewCard("spade", 9), game.NewCard("spade", 3), game.NewCard("heart", 2), game.NewCard("heart", 10),
+		game.NewCard("club", 7), game.NewCard("club", 10),
+		// round 2. 18 against a 10 stands
+		game.NewCard("spade", 10), game.NewCard("spade", 8), game.NewCard("heart", 10), game.NewCard("heart", 9),
+		// round 3. 17 against a 10 stands
+		game.NewCard("club", 10), game.NewCard("club", 7), game.NewCard("diamond", 10), game.NewCard("diamond", 8),
+	}, shoe.Cards...)
+	tab.game.StartGame()
+	tab.handleCommand(inboundMessage{protocol.PackageBet(protocol.BetDTO{Main: 10}), client})
+	tab.autoProgress()
+
+	tab.actionTimedOut()
+	if len(p.Hands[0].Cards) != 3 || tab.game.State != game.DEALER_TURN {
+		t.Fatalf("Expected basic strategy to play the hand out. got=%d cards state=%s", len(p.Hands[0].Cards), tab.game.State)
+	}
+	tab.autoProgress()
+
+	// the bet timer puts the last bet down again
+	tab.betTimedOut()
+	if p.Bet != 10 || p.Misses != 2 {
+		t.Fatalf("Expected the last bet to be repeated. got bet=%d misses=%d", p.Bet, p.Misses)
+	}
+	tab.game.StartRound()
+	tab.autoProgress()
+	tab.actionTimedOut()
+	if !p.SittingOut {
+		t.Fatalf("Expected the player to sit out after %d timeouts in a row", config.Timeout.SitOutAfter)
+	}
+	tab.autoProgress()
+	if tab.game.State != game.WAITING_FOR_BETS {
+		t.Errorf("A table where everyone is sitting out should wait for the bet timer. got=%s", tab.game.State)
+	}
+
+	// auto-play bets and plays without timing out
+	tab.handleCommand(inboundMessage{protocol.PackageClientMessage(protocol.MsgAutoPlay, "true"), client})
+	tab.autoProgress()
+	if p.SittingOut || p.Bet != 10 || !tab.botThinking {
+		t.Fatalf("Expected auto-play to bet and take the turn. got sitting out=%v bet=%d", p.SittingOut, p.Bet)
+	}
+	tab.playBotTurn()
+	tab.handleCommand(inboundMessage{protocol.PackageClientMessage(protocol.MsgAutoPlay, "false"), client})
+	tab.autoProgress()
+	if p.AutoPlay || tab.game.State != game.WAITING_FOR_BETS {
+		t.Fatalf("Expected auto-play to stop after the round. got state=%s", tab.game.State)
+	}
+
+	user, err := db.DB.GetUserByUsername(context.Background(), "p1")
+	if err != nil {
+		t.Fatalf("Unable to get user. err=%v", err)
+	}
+	if user.Timeouts != 3 || user.HandsPlayed != 3 {
+		t.Errorf("Expected every timeout to be recorded. got timeouts=%d hands=%d", user.Timeouts, user.HandsPlayed)
+	}
+
+	// a bet of their own starts the player's timeouts over
+	p.Misses = 2
+	tab.handleCommand(inboundMessage{protocol.PackageBet(protocol.BetDTO{Main: 5}), client})
+	tab.handleEvents()
+	if p.Misses != 0 {
+		t.Errorf("Expected the player's own bet to start their timeouts over. got=%d", p.Misses)
+	}
+}
+
+func TestTimeoutPolicyPerTable(t *testing.T) {
+	db, _ := store.NewStore(":memory:", "../sql/schema")
+	lobby := NewLobby(db, CreateMetrics())
+	config := DefaultConfig()
+	config.Timeout = &TimeoutPolicy{SitOutAfter: 2}
+	config.RuleProfiles = map[string]RulesConfig{
+		"fast": {Timeout: &TimeoutPolicy{Play: TIMEOUT_BASIC, SitOutAfter: 1}},
+	}
+	fast, err := config.WithProfile("fast")
+	if err != nil {
+		t.Fatalf("Unable to apply rule profile. err=%v", err)
+	}
+	tables := []*Table{
+		newTable(context.WithValue(context.TODO(), "config", config), "house", lobby, db, CreateMetrics()),
+		newTable(context.WithValue(context.TODO(), "config", fast), "fast", lobby, db, CreateMetrics()),
+	}
+	if tables[0].timeout.Play != TIMEOUT_STAND || tables[1].timeout.Play != TIMEOUT_BASIC {
+		t.Errorf("Expected each table to have its own policy. got=%+v %+v", tables[0].timeout, tables[1].timeout)
+	}
+	players := []*game.Player{}
+	for i, c := range clientHelper(2) {
+		c.send = make(chan *protocol.TransportMessage, 100)
+		c.username = fmt.Sprintf("p%d", i+1)
+		tables[i].RegisterClient(c)
+		players = append(players, tables[i].game.GetPlayer(c.id))
+	}
+
+	// neither player has ever bet. Missing the bet still counts
+	for _, tab := range tables {
+		tab.betTimedOut()
+	}
+	if players[0].SittingOut || !players[1].SittingOut {
+		t.Errorf("Expected only the fast table to sit its player out. got=%v %v", players[0].SittingOut, players[1].SittingOut)
+	}
+	tables[0].betTimedOut()
+	if !players[0].SittingOut || players[0].Bet != 0 {
+		t.Errorf("Expected the player to sit out without a bet. got sitting out=%v bet=%d", players[0].SittingOut, players[0].Bet)
+	}
+	for i, timeouts := range []int64{2, 1} {
+		user, err := db.DB.GetUserByUsername(context.Background(), fmt.Sprintf("p%d", i+1))
+		if err != nil {
+			t.Fatalf("Unable to get user. err=%v", err)
+		}
+		if user.Timeouts != timeouts {
+			t.Errorf("p%d timeouts incorrect. expected=%d got=%d", i+1, timeouts, user.Timeouts)
+		}
+	}
+}
@@ -0,0 +1,132 @@
+package server
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/dylanmccormick/blackjack-tui/game"
+	"github.com/google/uuid"
+)
+
+const (
+	TIMEOUT_STAND = "stand"
+	TIMEOUT_BASIC = "basic"
+)
+
+// TimeoutPolicy is what a table does when a player's timer runs out. Every timeout counts
+// towards the player's stats
+type TimeoutPolicy struct {
+	Play        string `yaml:"play"`          // stand or basic. How the table plays the hand of a player out of time
+	SitOutAfter int    `yaml:"sit_out_after"` // timeouts in a row before the player sits out. 0 never sits anyone out
+	Rebet       bool   `yaml:"rebet"`         // put the player's last bet down again when they don't bet in time
+}
+
+// autoPlayer is how the table plays for people. Players who turn on auto-play get the same
+var autoPlayer = game.BasicStrategyPlayer{}
+
+// actionTimedOut plays out the hand of the player whose action timer ran out
+func (t *Table) actionTimedOut() {
+	p := t.game.CurrentPlayer()
+	hand := t.game.CurrentHandIndex
+	t.log.Info("Player ran out of time", "player", p.ID, "play", t.timeout.Play)
+	if t.timeout.Play == TIMEOUT_BASIC {
+		for t.game.State == game.PLAYER_TURN && t.game.CurrentPlayer() == p && t.game.CurrentHandIndex == hand {
+			if err := t.game.AutoPlay(p, autoPlayer); err != nil {
+				t.log.Error("Unable to play for player", "player", p.ID, "error", err)
+				break
+			}
+		}
+	} else {
+		t.game.AutoStay(p)
+	}
+	t.timedOut(p)
+}
+
+// betTimedOut handles the players who didn't bet in time. They sit the round out unless the table
+// puts their last bet down for them
+func (t *Table) betTimedOut() {
+	for _, p := range t.game.Players {
+		if p == nil || p.Bot || p.Bet > 0 || p.SittingOut || !p.DisconnectedAt.IsZero() {
+			continue
+		}
+		t.timedOut(p)
+		if t.timeout.Rebet && !p.SittingOut && p.LastBet.Main > 0 {
+			err := t.game.Rebet(p)
+			if err != nil {
+				t.log.Info("Unable to repeat bet", "player", p.ID, "error", err)
+			}
+		}
+	}
+}
+
+// timedOut counts a timeout against the player and sits them out once they miss too many in a row
+func (t *Table) timedOut(p *game.Player) {
+	p.Misses++
+	client, ok := t.idToClient[p.ID]
+	if !ok {
+		return
+	}
+	err := t.db.RecordTimeout(context.Background(), client.username)
+	if err != nil {
+		t.log.Error("Unable to record timeout", "username", client.username, "error", err)
+	}
+	limit := t.timeout.SitOutAfter
+	if limit == 0 || p.Misses < limit || p.SittingOut {
+		return
+	}
+	p.SittingOut = true
+	t.log.Info("Player is sitting out", "player", p.ID, "misses", p.Misses)
+	popup := CreatePopUp(fmt.Sprintf("You ran out of time %d times in a row. Sitting out until you bet again", p.Misses), "info")
+	if popup != nil {
+		client.send <- popup
+	}
+}
+
+// playerActed starts the player's timeouts over when they play or bet for themselves
+func (t *Table) playerActed(id uuid.UUID) {
+	if p := t.game.GetPlayer(id); p != nil {
+		p.Misses = 0
+	}
+}
+
+// setAutoPlay has the table bet and play for every spot the player has, or stop
+func (t *Table) setAutoPlay(c *Client, on bool) {
+	for _, spot := range t.game.Spots(c.id) {
+		spot.AutoPlay = on
+		spot.Misses = 0
+	}
+	if on && t.game.State == game.INSURANCE {
+		t.autoPlayInsurance()
+	}
+	message := "Auto-play off"
+	if on {
+		message = "Auto-play on. The table bets your last bet and plays basic strategy for you"
+	}
+	popup := CreatePopUp(message, "info")
+	if popup != nil {
+		c.send <- popup
+	}
+}
+
+// rebetAutoPlayers puts down the last bet for everyone on auto-play
+func (t *Table) rebetAutoPlayers() {
+	for _, p := range t.game.Players {
+		if p == nil || !p.AutoPlay || p.Bet > 0 || p.LastBet.Main == 0 || !p.DisconnectedAt.IsZero() {
+			continue
+		}
+		err := t.game.Rebet(p)
+		if err != nil {
+			t.log.Info("Unable to repeat bet for auto-play", "player", p.ID, "error", err)
+		}
+	}
+}
+
+// autoPlayInsurance makes the insurance decision for everyone on auto-play
+func (t *Table) autoPlayInsurance() {
+	for _, p := range t.game.Players {
+		if p == nil || !p.AutoPlay || !p.IsActive() || len(p.Hands) == 0 || p.InsuranceDecided {
+			continue
+		}
+		t.game.AutoPlay(p, autoPlayer)
+	}
+}
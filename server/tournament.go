@@ -0,0 +1,534 @@
+package server
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/dylanmccormick/blackjack-tui/game"
+	"github.com/dylanmccormick/blackjack-tui/protocol"
+	"github.com/dylanmccormick/blackjack-tui/store"
+	"github.com/google/uuid"
+)
+
+const (
+	TOURNAMENT_CHIPS    = 1000
+	TOURNAMENT_HANDS    = 25
+	TOURNAMENT_CAPACITY = 5 // one table's worth of seats
+
+	TOURNAMENT_SIGN_UP  = "sign_up"
+	TOURNAMENT_RUNNING  = "running"
+	TOURNAMENT_FINISHED = "finished"
+)
+
+type TournamentConfig struct {
+	Name           string `yaml:"name"`            // also the name of the table it is played at
+	Start          string `yaml:"start"`           // time of day it starts in the server's time zone. e.g. "20:00"
+	Chips          int    `yaml:"chips"`           // the stack everyone starts with
+	Hands          int    `yaml:"hands"`           // hands dealt before the final ranking
+	EliminateEvery int    `yaml:"eliminate_every"` // hands between knocking out the shortest stack. 0 only knocks out players who go broke
+	Prizes         []int  `yaml:"prizes"`          // paid into the wallets of first, second, third...
+}
+
+// tournament is shared by the lobby, which runs the sign up, and the table it is played at
+type tournament struct {
+	mu       sync.Mutex
+	config   TournamentConfig
+	capacity int
+	startsAt time.Time
+	state    string
+	entrants []*entrant // in sign up order
+	hand     int        // hands played so far
+}
+
+type entrant struct {
+	username string
+	playerID uuid.UUID // the seat the entrant is playing from. Changes every time they sit down
+	chips    int
+	place    int // 0 while the entrant is still in
+}
+
+func newTournament(config TournamentConfig, now time.Time) (*tournament, error) {
+	if config.Name == "" {
+		return nil, fmt.Errorf("Tournaments need a name")
+	}
+	startsAt, err := nextStart(config.Start, now)
+	if err != nil {
+		return nil, err
+	}
+	config.Chips = cmp.Or(config.Chips, TOURNAMENT_CHIPS)
+	config.Hands = cmp.Or(config.Hands, TOURNAMENT_HANDS)
+	return &tournament{
+		config:   config,
+		capacity: TOURNAMENT_CAPACITY,
+		startsAt: startsAt,
+		state:    TOURNAMENT_SIGN_UP,
+		entrants: []*entrant{},
+	}, nil
+}
+
+// nextStart is the next time the clock reads start
+func nextStart(start string, now time.Time) (time.Time, error) {
+	clock, err := time.Parse("15:04", start)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("Invalid tournament start %q: %w", start, err)
+	}
+	next := time.Date(now.Year(), now.Month(), now.Day(), clock.Hour(), clock.Minute(), 0, 0, now.Location())
+	if !next.After(now) {
+		next = next.AddDate(0, 0, 1)
+	}
+	return next, nil
+}
+
+func (tr *tournament) signUp(username string) error {
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+	if tr.state != TOURNAMENT_SIGN_UP {
+		return fmt.Errorf("Sign up for %s has closed", tr.config.Name)
+	}
+	if tr.find(username) != nil {
+		return fmt.Errorf("You are already signed up for %s", tr.config.Name)
+	}
+	if len(tr.entrants) >= tr.capacity {
+		return fmt.Errorf("%s is full", tr.config.Name)
+	}
+	tr.entrants = append(tr.entrants, &entrant{username: username, chips: tr.config.Chips})
+	return nil
+}
+
+func (tr *tournament) withdraw(username string) error {
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+	if tr.state != TOURNAMENT_SIGN_UP {
+		return fmt.Errorf("%s has already started", tr.config.Name)
+	}
+	i := slices.IndexFunc(tr.entrants, func(e *entrant) bool { return e.username == username })
+	if i < 0 {
+		return fmt.Errorf("You are not signed up for %s", tr.config.Name)
+	}
+	tr.entrants = slices.Delete(tr.entrants, i, i+1)
+	return nil
+}
+
+func (tr *tournament) find(username string) *entrant {
+	for _, e := range tr.entrants {
+		if e.username == username {
+			return e
+		}
+	}
+	return nil
+}
+
+// usernames is everyone who signed up
+func (tr *tournament) usernames() []string {
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+	names := []string{}
+	for _, e := range tr.entrants {
+		names = append(names, e.username)
+	}
+	return names
+}
+
+func (tr *tournament) due(now time.Time) bool {
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+	return tr.state == TOURNAMENT_SIGN_UP && !now.Before(tr.startsAt)
+}
+
+// start closes sign up. False when not enough people signed up to play
+func (tr *tournament) start() bool {
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+	if len(tr.entrants) < 2 {
+		return false
+	}
+	tr.state = TOURNAMENT_RUNNING
+	return true
+}
+
+func (tr *tournament) running() bool {
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+	return tr.state == TOURNAMENT_RUNNING
+}
+
+func (tr *tournament) finished() bool {
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+	return tr.state == TOURNAMENT_FINISHED
+}
+
+// seat gives an entrant who is still in their stack back. False for anyone else
+func (tr *tournament) seat(username string, id uuid.UUID) (int, bool) {
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+	e := tr.find(username)
+	if tr.state != TOURNAMENT_RUNNING || e == nil || e.place > 0 {
+		return 0, false
+	}
+	e.playerID = id
+	return e.chips, true
+}
+
+// stand keeps the stack of an entrant getting up from the table. A bet in play is lost
+func (tr *tournament) stand(p *game.Player) {
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+	for _, e := range tr.entrants {
+		if e.playerID == p.ID && e.place == 0 {
+			e.chips = p.Wallet
+		}
+	}
+}
+
+func (tr *tournament) remaining() []*entrant {
+	remaining := []*entrant{}
+	for _, e := range tr.entrants {
+		if e.place == 0 {
+			remaining = append(remaining, e)
+		}
+	}
+	return remaining
+}
+
+// handPlayed catches the stacks up with the table once a hand is over. Anyone who can't cover the
+// minimum bet is knocked out, then the shortest stack if an elimination hand was just played.
+// Returns everyone knocked out, worst place first
+func (tr *tournament) handPlayed(g *game.Game) []entrant {
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+	if tr.state != TOURNAMENT_RUNNING {
+		return nil
+	}
+	tr.hand++
+	minBet := max(g.Config.Rules.MinBet, 1)
+	broke := []*entrant{}
+	for _, e := range tr.remaining() {
+		if p := g.GetPlayer(e.playerID); p != nil {
+			e.chips = p.Wallet
+		}
+		if e.chips < minBet {
+			broke = append(broke, e)
+		}
+	}
+	// players who go broke on the same hand are placed by what they had left
+	slices.SortStableFunc(broke, func(a, b *entrant) int { return cmp.Compare(a.chips, b.chips) })
+	out := []entrant{}
+	for _, e := range broke {
+		tr.knockOut(e)
+		out = append(out, *e)
+	}
+
+	remaining := tr.remaining()
+	every := tr.config.EliminateEvery
+	if every > 0 && tr.hand%every == 0 && tr.hand < tr.config.Hands && len(remaining) > 1 {
+		shortest := remaining[0]
+		for _, e := range remaining[1:] {
+			// ties go against whoever signed up last
+			if e.chips <= shortest.chips {
+				shortest = e
+			}
+		}
+		tr.knockOut(shortest)
+		out = append(out, *shortest)
+	}
+
+	if tr.hand >= tr.config.Hands || len(tr.remaining()) <= 1 {
+		tr.finish()
+	}
+	return out
+}
+
+// knockOut places an entrant behind everyone still in
+func (tr *tournament) knockOut(e *entrant) {
+	e.place = len(tr.remaining())
+}
+
+// finish ranks everyone still in by their stack
+func (tr *tournament) finish() {
+	remaining := tr.remaining()
+	slices.SortStableFunc(remaining, func(a, b *entrant) int { return cmp.Compare(b.chips, a.chips) })
+	for i, e := range remaining {
+		e.place = i + 1
+	}
+	tr.state = TOURNAMENT_FINISHED
+}
+
+// standings is the leaderboard. Everyone still in comes first, biggest stack first
+func (tr *tournament) standings() []*entrant {
+	standings := slices.Clone(tr.entrants)
+	if tr.state == TOURNAMENT_SIGN_UP {
+		return standings
+	}
+	slices.SortStableFunc(standings, func(a, b *entrant) int {
+		switch {
+		case a.place == 0 && b.place == 0:
+			return cmp.Compare(b.chips, a.chips)
+		case a.place == 0:
+			return -1
+		case b.place == 0:
+			return 1
+		}
+		return cmp.Compare(a.place, b.place)
+	})
+	return standings
+}
+
+func (tr *tournament) prize(place int) int {
+	if place < 1 || place > len(tr.config.Prizes) {
+		return 0
+	}
+	return tr.config.Prizes[place-1]
+}
+
+// record is the final standings to save and pay out
+func (tr *tournament) record() store.TournamentRecord {
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+	record := store.TournamentRecord{Name: tr.config.Name, StartedAt: tr.startsAt}
+	for _, e := range tr.standings() {
+		record.Standings = append(record.Standings, store.TournamentStanding{
+			GithubID: e.username,
+			Place:    e.place,
+			Chips:    e.chips,
+			Prize:    tr.prize(e.place),
+		})
+	}
+	return record
+}
+
+// DTO is the tournament as the given player sees it
+func (tr *tournament) DTO(username string) protocol.TournamentDTO {
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+	dto := protocol.TournamentDTO{
+		Name:      tr.config.Name,
+		State:     tr.state,
+		StartsAt:  tr.startsAt,
+		Chips:     tr.config.Chips,
+		Hands:     tr.config.Hands,
+		HandsLeft: tr.config.Hands - tr.hand,
+		Prizes:    slices.Clone(tr.config.Prizes),
+		Capacity:  tr.capacity,
+		SignedUp:  tr.find(username) != nil,
+		Standings: []protocol.StandingDTO{},
+	}
+	if every := tr.config.EliminateEvery; every > 0 && tr.state == TOURNAMENT_RUNNING {
+		next := every - tr.hand%every
+		if tr.hand+next < tr.config.Hands {
+			dto.NextElimination = next
+		}
+	}
+	for _, e := range tr.standings() {
+		dto.Standings = append(dto.Standings, protocol.StandingDTO{
+			Name:  e.username,
+			Chips: e.chips,
+			Place: e.place,
+			Prize: tr.prize(e.place),
+		})
+	}
+	return dto
+}
+
+// scheduleTournaments opens sign up for every tournament in the config that isn't already
+// open or being played
+func (l *Lobby) scheduleTournaments(ctx context.Context, now time.Time) {
+	config, _ := ctx.Value("config").(Config)
+	for _, tc := range config.Tournaments {
+		if tr, ok := l.tournaments[tc.Name]; ok && !tr.finished() {
+			continue
+		}
+		tr, err := newTournament(tc, now)
+		if err != nil {
+			l.log.Error("Unable to schedule tournament", "name", tc.Name, "error", err)
+			continue
+		}
+		l.tournaments[tc.Name] = tr
+		l.log.Info("Tournament sign up open", "name", tc.Name, "starts_at", tr.startsAt)
+	}
+}
+
+// startTournaments starts every tournament whose start time has come. Tournaments that not enough
+// people signed up for are called off until their next start time
+func (l *Lobby) startTournaments(ctx context.Context, now time.Time) {
+	for name, tr := range l.tournaments {
+		if !tr.due(now) {
+			continue
+		}
+		if !tr.start() {
+			l.log.Info("Not enough players for tournament", "name", name)
+			l.notify(tr.usernames(), fmt.Sprintf("Not enough players signed up for %s. It is called off", name), "warn")
+			delete(l.tournaments, name)
+			continue
+		}
+		l.log.Info("Starting tournament", "name", name, "entrants", tr.usernames())
+		l.openTournamentTable(ctx, tr)
+		l.notify(tr.usernames(), fmt.Sprintf("%s has started! Join it from the table list", name), "info")
+	}
+	l.scheduleTournaments(ctx, now)
+}
+
+func (l *Lobby) openTournamentTable(ctx context.Context, tr *tournament) {
+	if config, ok := ctx.Value("config").(Config); ok {
+		// the seats are for the people who signed up, one each, and tournament chips can't buy into the jackpot
+		config.Bots = nil
+		config.JackpotBet = 0
+		config.MaxSpots = ptr(1)
+		ctx = context.WithValue(ctx, "config", config)
+	}
+	t := newTable(ctx, tr.config.Name, l, l.store, l.Metrics)
+	t.tournament = tr
+	t.game.StartGame()
+	t.betTimer.Reset(time.Duration(t.Config.BetTimeout) * time.Second)
+	l.openTable(ctx, t)
+}
+
+// notify sends a popup to the players in the lobby out of the given usernames
+func (l *Lobby) notify(usernames []string, message, level string) {
+	for client := range l.clients {
+		if !slices.Contains(usernames, client.username) {
+			continue
+		}
+		if popup := CreatePopUp(message, level); popup != nil {
+			client.send <- popup
+		}
+	}
+}
+
+func (l *Lobby) listTournaments(c *Client) {
+	tournaments := []*tournament{}
+	for _, tr := range l.tournaments {
+		tournaments = append(tournaments, tr)
+	}
+	slices.SortFunc(tournaments, func(a, b *tournament) int {
+		return cmp.Or(a.startsAt.Compare(b.startsAt), cmp.Compare(a.config.Name, b.config.Name))
+	})
+	out := []protocol.TournamentDTO{}
+	for _, tr := range tournaments {
+		out = append(out, tr.DTO(c.username))
+	}
+	data, err := protocol.PackageMessage(out)
+	if err != nil {
+		l.log.Error("Unable to send tournament list in lobby")
+		return
+	}
+	c.send <- data
+}
+
+// signUp signs the client up for a tournament or withdraws them from it
+func (l *Lobby) signUp(c *Client, name string, withdraw bool) {
+	tr, ok := l.tournaments[name]
+	if !ok {
+		l.log.Warn("The tournament does not exist", "name", name)
+		return
+	}
+	var err error
+	message := fmt.Sprintf("You're signed up for %s. It starts at %s", name, tr.startsAt.Format("15:04"))
+	if withdraw {
+		err = tr.withdraw(c.username)
+		message = fmt.Sprintf("You've withdrawn from %s", name)
+	} else {
+		err = tr.signUp(c.username)
+	}
+	if err != nil {
+		message = err.Error()
+	}
+	level := "info"
+	if err != nil {
+		level = "warn"
+	}
+	if popup := CreatePopUp(message, level); popup != nil {
+		c.send <- popup
+	}
+	for client := range l.clients {
+		l.listTournaments(client)
+	}
+}
+
+// playTournamentHand moves the tournament on once the table has finished a hand
+func (t *Table) playTournamentHand() {
+	if t.tournament == nil {
+		return
+	}
+	for _, e := range t.tournament.handPlayed(t.game) {
+		t.log.Info("Knocked out of tournament", "username", e.username, "place", e.place)
+		t.game.RemovePlayer(e.playerID)
+		t.broadcast(protocol.MessageToDTO(fmt.Sprintf("%s is knocked out in %s place", e.username, ordinal(e.place)), protocol.InfoMsg))
+	}
+	if t.tournament.finished() {
+		t.finishTournament()
+	}
+}
+
+// finishTournament pays the prizes. Everyone left at the table stays to watch
+func (t *Table) finishTournament() {
+	record := t.tournament.record()
+	for _, p := range t.game.Players {
+		if p != nil {
+			t.game.RemovePlayer(p.ID)
+		}
+	}
+	err := t.db.RecordTournament(context.Background(), record)
+	if err != nil {
+		// nothing was saved, so nobody was paid either
+		t.log.Error("Unable to record tournament", "name", record.Name, "error", err)
+		t.broadcast(protocol.MessageToDTO(fmt.Sprintf("Unable to save the results of %s. No prizes were paid", record.Name), protocol.WarnMsg))
+	}
+	t.log.Info("Tournament finished", "name", record.Name, "standings", record.Standings)
+	winner := record.Standings[0]
+	message := fmt.Sprintf("%s wins %s with %d chips!", winner.GithubID, record.Name, winner.Chips)
+	if winner.Prize > 0 {
+		message = fmt.Sprintf("%s wins %s with %d chips and takes home %d!", winner.GithubID, record.Name, winner.Chips, winner.Prize)
+	}
+	t.broadcast(protocol.MessageToDTO(message, protocol.InfoMsg))
+}
+
+// seatEntrant sits an entrant down with their tournament stack. Everyone else can only watch
+func (t *Table) seatEntrant(c *Client) {
+	for _, p := range t.game.Players {
+		if p != nil && p.Name == c.username {
+			if !t.betweenRounds() {
+				// back from a dropped connection mid-round. The old seat plays the round out so the
+				// bet on it is paid, then the entrant takes it back
+				t.log.Info("Entrant waiting for the round to end", "client", c.id, "username", c.username)
+				t.waiting = append(t.waiting, c)
+				if popup := CreatePopUp("You'll get your seat back when this round is over", "info"); popup != nil {
+					c.send <- popup
+				}
+				return
+			}
+			// back from a dropped connection. The old seat gets up first
+			t.tournament.stand(p)
+			t.game.RemovePlayer(p.ID)
+		}
+	}
+	chips, ok := t.tournament.seat(c.username, c.id)
+	if !ok {
+		t.log.Info("Watching tournament", "client", c.id, "username", c.username)
+		return
+	}
+	p := game.NewPlayer(c.id, chips)
+	p.Name = c.username
+	err := t.game.AddPlayer(p)
+	if err != nil {
+		t.log.Warn("No seat for tournament entrant", "client", c.id, "error", err)
+	}
+}
+
+func ordinal(n int) string {
+	suffix := "th"
+	switch {
+	case n%100 >= 11 && n%100 <= 13:
+	case n%10 == 1:
+		suffix = "st"
+	case n%10 == 2:
+		suffix = "nd"
+	case n%10 == 3:
+		suffix = "rd"
+	}
+	return fmt.Sprintf("%d%s", n, suffix)
+}
@@ -0,0 +1,339 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/dylanmccormick/blackjack-tui/game"
+	"github.com/dylanmccormick/blackjack-tui/protocol"
+	"github.com/dylanmccormick/blackjack-tui/store"
+	"github.com/google/uuid"
+)
+
+func TestNextStart(t *testing.T) {
+	now := time.Date(2026, 3, 14, 19, 30, 0, 0, time.UTC)
+	tests := []struct {
+		start    string
+		expected time.Time
+	}{
+		{"20:00", time.Date(2026, 3, 14, 20, 0, 0, 0, time.UTC)},
+		{"19:30", time.Date(2026, 3, 15, 19, 30, 0, 0, time.UTC)},
+		{"08:15", time.Date(2026, 3, 15, 8, 15, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		next, err := nextStart(tt.start, now)
+		if err != nil {
+			t.Fatalf("Unexpected error for start %s. err=%v", tt.start, err)
+		}
+		if !next.Equal(tt.expected) {
+			t.Errorf("Next start for %s incorrect. expected=%v got=%v", tt.start, tt.expected, next)
+		}
+	}
+	if _, err := nextStart("8pm", now); err == nil {
+		t.Errorf("Expected an error for a start time that isn't HH:MM")
+	}
+}
+
+func TestTournamentSignUp(t *testing.T) {
+	tr, err := newTournament(TournamentConfig{Name: "nightly", Start: "20:00"}, time.Now())
+	if err != nil {
+		t.Fatalf("Unable to create tournament. err=%v", err)
+	}
+	if tr.config.Chips != TOURNAMENT_CHIPS || tr.config.Hands != TOURNAMENT_HANDS {
+		t.Errorf("Tournament defaults not applied. got=%+v", tr.config)
+	}
+	if err := tr.signUp("p1"); err != nil {
+		t.Fatalf("Unexpected sign up error. err=%v", err)
+	}
+	if tr.start() {
+		t.Fatalf("A tournament shouldn't start with one player")
+	}
+	if err := tr.signUp("p1"); err == nil {
+		t.Errorf("Expected an error signing up twice")
+	}
+	if err := tr.withdraw("p2"); err == nil {
+		t.Errorf("Expected an error withdrawing without signing up")
+	}
+	for i := 2; i <= TOURNAMENT_CAPACITY; i++ {
+		if err := tr.signUp(fmt.Sprintf("p%d", i)); err != nil {
+			t.Fatalf("Unexpected sign up error. err=%v", err)
+		}
+	}
+	if err := tr.signUp("p6"); err == nil {
+		t.Errorf("Expected an error signing up for a full tournament")
+	}
+	if err := tr.withdraw("p3"); err != nil {
+		t.Fatalf("Unexpected withdraw error. err=%v", err)
+	}
+	if names := tr.usernames(); !slices.Equal(names, []string{"p1", "p2", "p4", "p5"}) {
+		t.Errorf("Entrants incorrect. got=%v", names)
+	}
+	if !tr.start() {
+		t.Fatalf("Expected the tournament to start")
+	}
+	if err := tr.signUp("p6"); err == nil {
+		t.Errorf("Expected sign up to close once the tournament starts")
+	}
+	if err := tr.withdraw("p1"); err == nil {
+		t.Errorf("Expected an error withdrawing once the tournament starts")
+	}
+}
+
+func TestTournamentEliminations(t *testing.T) {
+	config := TournamentConfig{Name: "nightly", Start: "20:00", Chips: 100, Hands: 4, EliminateEvery: 2, Prizes: []int{500, 250}}
+	tr, err := newTournament(config, time.Now())
+	if err != nil {
+		t.Fatalf("Unable to create tournament. err=%v", err)
+	}
+	gc := Config{RulesConfig: RulesConfig{MinBet: ptr(5)}}.GameConfig()
+	g := game.NewGame(gc)
+	players := map[string]*game.Player{}
+	for _, name := range []string{"p1", "p2", "p3", "p4"} {
+		tr.signUp(name)
+	}
+	tr.start()
+	for _, name := range []string{"p1", "p2", "p3", "p4"} {
+		chips, ok := tr.seat(name, uuid.New())
+		if !ok || chips != 100 {
+			t.Fatalf("Entrant %s should be seated with the starting stack. got=%d", name, chips)
+		}
+		p := game.NewPlayer(tr.find(name).playerID, chips)
+		p.Name = name
+		g.AddPlayer(p)
+		players[name] = p
+	}
+	if _, ok := tr.seat("p5", uuid.New()); ok {
+		t.Errorf("Only entrants should get a seat")
+	}
+
+	// hand 1. p4 can't cover the minimum bet
+	players["p1"].Wallet = 150
+	players["p2"].Wallet = 80
+	players["p3"].Wallet = 60
+	players["p4"].Wallet = 3
+	out := tr.handPlayed(g)
+	if len(out) != 1 || out[0].username != "p4" || out[0].place != 4 {
+		t.Fatalf("Expected p4 to go out in 4th. got=%+v", out)
+	}
+
+	// hand 2 is an elimination hand. p2 and p3 are tied for the shortest stack
+	players["p2"].Wallet = 60
+	out = tr.handPlayed(g)
+	if len(out) != 1 || out[0].username != "p3" || out[0].place != 3 {
+		t.Fatalf("Expected p3 to be eliminated in 3rd. got=%+v", out)
+	}
+	dto := tr.DTO("p1")
+	if dto.HandsLeft != 2 || dto.NextElimination != 0 || !dto.SignedUp {
+		t.Errorf("Tournament DTO incorrect. got=%+v", dto)
+	}
+
+	// the last two hands decide it on chips
+	players["p2"].Wallet = 200
+	tr.handPlayed(g)
+	if tr.finished() {
+		t.Fatalf("Tournament should run all of its hands")
+	}
+	tr.handPlayed(g)
+	if !tr.finished() {
+		t.Fatalf("Tournament should be finished after its last hand")
+	}
+	record := tr.record()
+	expected := []store.TournamentStanding{
+		{GithubID: "p2", Place: 1, Chips: 200, Prize: 500},
+		{GithubID: "p1", Place: 2, Chips: 150, Prize: 250},
+		{GithubID: "p3", Place: 3, Chips: 60},
+		{GithubID: "p4", Place: 4, Chips: 3},
+	}
+	if !slices.Equal(record.Standings, expected) {
+		t.Errorf("Final standings incorrect.\nexpected=%+v\ngot=%+v", expected, record.Standings)
+	}
+}
+
+func TestTournamentTable(t *testing.T) {
+	db, _ := store.NewStore(":memory:", "../sql/schema")
+	lobby := NewLobby(db, CreateMetrics())
+	config := Config{
+		BetTimeout:         30,
+		TableActionTimeout: 30,
+		TableDeleteTimeout: 5,
+		Tournaments:        []TournamentConfig{{Name: "nightly", Start: "20:00", Chips: 100, Hands: 2, Prizes: []int{300}}},
+	}
+	ctx, cancel := context.WithCancel(context.WithValue(context.Background(), "config", config))
+	defer cancel()
+	lobby.scheduleTournaments(ctx, time.Now())
+	tr, ok := lobby.tournaments["nightly"]
+	if !ok {
+		t.Fatalf("Expected the tournament to be open for sign up")
+	}
+
+	clients := clientHelper(3)
+	for i, c := range clients {
+		c.send = make(chan *protocol.TransportMessage, 1000)
+		c.username = fmt.Sprintf("p%d", i+1)
+		if _, err := db.GetOrCreateUser(ctx, c.username); err != nil {
+			t.Fatalf("Unable to create user. err=%v", err)
+		}
+		lobby.RegisterClient(c)
+	}
+	lobby.signUp(clients[0], "nightly", false)
+	lobby.signUp(clients[1], "nightly", false)
+	lobby.createTable(ctx, "nightly", "")
+	if len(lobby.tables) != 0 {
+		t.Fatalf("A table shouldn't take a tournament's name")
+	}
+
+	// run the table by hand instead of starting it from the lobby
+	tab := newTable(ctx, "nightly", lobby, db, CreateMetrics())
+	tr.start()
+	tab.tournament = tr
+	for _, c := range clients {
+		tab.RegisterClient(c)
+	}
+	if tab.game.GetPlayer(clients[2].id) != nil {
+		t.Fatalf("Only entrants should be seated")
+	}
+	p1 := tab.game.GetPlayer(clients[0].id)
+	p2 := tab.game.GetPlayer(clients[1].id)
+	if p1 == nil || p2 == nil || p1.Wallet != 100 || p2.Wallet != 100 {
+		t.Fatalf("Entrants should be seated with the starting stack. got=%v %v", p1, p2)
+	}
+	if !tab.CreateDTO().Tournament {
+		t.Errorf("Table should be marked as a tournament table")
+	}
+
+	p1.Wallet = 140
+	p2.Wallet = 60
+	tab.playTournamentHand()
+	tab.playTournamentHand()
+	if !tr.finished() {
+		t.Fatalf("Tournament should be finished")
+	}
+	if len(tab.game.ActivePlayers()) != 0 || tab.game.GetPlayer(clients[0].id) != nil {
+		t.Errorf("Everyone should get up once the tournament is over")
+	}
+	for name, wallet := range map[string]int64{"p1": 1300, "p2": 1000} {
+		user, err := db.DB.GetUserByUsername(ctx, name)
+		if err != nil {
+			t.Fatalf("Unable to get user. err=%v", err)
+		}
+		if user.Wallet != wallet {
+			t.Errorf("%s wallet incorrect. expected=%d got=%d", name, wallet, user.Wallet)
+		}
+	}
+
+	// the next one opens for sign up once this one is over
+	lobby.scheduleTournaments(ctx, time.Now())
+	if next := lobby.tournaments["nightly"]; next == tr || next.finished() {
+		t.Errorf("Expected the next tournament to open for sign up")
+	}
+}
+
+func TestTournamentReconnect(t *testing.T) {
+	db, _ := store.NewStore(":memory:", "../sql/schema")
+	lobby := NewLobby(db, CreateMetrics())
+	config := Config{Tournaments: []TournamentConfig{{Name: "nightly", Start: "20:00", Chips: 100, Hands: 5}}}
+	ctx, cancel := context.WithCancel(context.WithValue(context.Background(), "config", config))
+	defer cancel()
+	lobby.scheduleTournaments(ctx, time.Now())
+	tr := lobby.tournaments["nightly"]
+
+	clients := clientHelper(3)
+	for i, c := range clients {
+		c.send = make(chan *protocol.TransportMessage, 1000)
+		c.username = fmt.Sprintf("p%d", i+1)
+		lobby.RegisterClient(c)
+	}
+	clients[2].username = "p1"
+	lobby.signUp(clients[0], "nightly", false)
+	lobby.signUp(clients[1], "nightly", false)
+	tab := newTable(ctx, "nightly", lobby, db, CreateMetrics())
+	tr.start()
+	tab.tournament = tr
+	tab.RegisterClient(clients[0])
+	tab.RegisterClient(clients[1])
+	// everyone gets 10 and the dealer busts
+	stacked := []game.Card{{Suit: "spade", Rank: 6}, {Suit: "spade", Rank: game.KING}}
+	for range 6 {
+		stacked = append([]game.Card{{Suit: "spade", Rank: 5}}, stacked...)
+	}
+	tab.game.Deck.Base().Cards = append(stacked, tab.game.Deck.Base().Cards...)
+	tab.game.StartGame()
+	old := tab.game.GetPlayer(clients[0].id)
+	tab.game.PlaceBet(old, game.Bet{Main: 10})
+	tab.game.PlaceBet(tab.game.GetPlayer(clients[1].id), game.Bet{Main: 10})
+	tab.autoProgress()
+	if tab.game.State != game.PLAYER_TURN {
+		t.Fatalf("Expected the round to be played. got=%s", tab.game.State)
+	}
+
+	// p1 drops and comes back on a new connection in the middle of the round
+	tab.DisconnectPlayer(clients[0], false)
+	tab.RegisterClient(clients[2])
+	if tab.game.GetPlayer(clients[2].id) != nil || tab.game.GetPlayer(old.ID) != old {
+		t.Fatalf("Expected the old seat to play the round out")
+	}
+	for tab.game.State == game.PLAYER_TURN {
+		tab.game.Stay(tab.game.CurrentPlayer())
+		tab.autoProgress()
+	}
+	if tab.game.State != game.WAITING_FOR_BETS {
+		t.Fatalf("Round did not finish. got=%s", tab.game.State)
+	}
+	p := tab.game.GetPlayer(clients[2].id)
+	if p == nil || tab.game.GetPlayer(old.ID) != nil {
+		t.Fatalf("Expected the entrant to take their seat back once the round was over")
+	}
+	if p.Wallet != 110 || old.Wallet != 110 {
+		t.Errorf("Expected the bet from the old seat to be paid. old=%d new=%d", old.Wallet, p.Wallet)
+	}
+}
+
+func TestStartTournaments(t *testing.T) {
+	db, _ := store.NewStore(":memory:", "../sql/schema")
+	lobby := NewLobby(db, CreateMetrics())
+	config := Config{
+		BetTimeout:         30,
+		TableActionTimeout: 30,
+		TableDeleteTimeout: 5,
+		Bots:               []BotConfig{{Name: "crasher"}},
+		Tournaments: []TournamentConfig{
+			{Name: "early", Start: "08:00"},
+			{Name: "late", Start: "20:00"},
+		},
+	}
+	ctx, cancel := context.WithCancel(context.WithValue(context.Background(), "config", config))
+	defer func() {
+		cancel()
+		lobby.tableWg.Wait()
+	}()
+	now := time.Date(2026, 3, 14, 7, 0, 0, 0, time.Local)
+	lobby.scheduleTournaments(ctx, now)
+	clients := clientHelper(2)
+	for i, c := range clients {
+		c.username = fmt.Sprintf("p%d", i+1)
+		lobby.RegisterClient(c)
+		lobby.signUp(c, "early", false)
+	}
+	lobby.signUp(clients[0], "late", false)
+
+	lobby.startTournaments(ctx, now.Add(14*time.Hour))
+	tab, ok := lobby.tables["early"]
+	if !ok || tab.tournament != lobby.tournaments["early"] {
+		t.Fatalf("Expected the early tournament to start at its own table")
+	}
+	if len(tab.bots) != 0 {
+		t.Errorf("Bots shouldn't sit at a tournament table. got=%d", len(tab.bots))
+	}
+	if _, ok := lobby.tables["late"]; ok {
+		t.Errorf("A tournament with one entrant shouldn't start")
+	}
+	if late := lobby.tournaments["late"]; late == nil || len(late.usernames()) != 0 || !late.startsAt.After(now.Add(24*time.Hour)) {
+		t.Errorf("The late tournament should be rescheduled for the next day")
+	}
+	if early := lobby.tournaments["early"]; !early.running() {
+		t.Errorf("The early tournament should still be running")
+	}
+}
@@ -3,9 +3,19 @@ package server
 import (
 	"log/slog"
 
+	"github.com/dylanmccormick/blackjack-tui/game"
 	"github.com/dylanmccormick/blackjack-tui/protocol"
 )
 
+func CreateBetError(e *game.BetError) *protocol.TransportMessage {
+	data, err := protocol.PackageMessage(protocol.BetErrorToDTO(e))
+	if err != nil {
+		slog.Error("Unable to package bet error message", "error", err)
+		return nil
+	}
+	return data
+}
+
 func CreatePopUp(message, level string) *protocol.TransportMessage {
 	msg := protocol.PopUpDTO{Message: message, Type: level}
 	data, err := protocol.PackageMessage(msg)
@@ -15,3 +25,13 @@ func CreatePopUp(message, level string) *protocol.TransportMessage {
 	}
 	return data
 }
+
+func CreateStrategy(hand int, action, advice game.PlayerAction) *protocol.TransportMessage {
+	msg := protocol.StrategyDTO{Hand: hand, Action: string(action), Advice: string(advice)}
+	data, err := protocol.PackageMessage(msg)
+	if err != nil {
+		slog.Error("Unable to package strategy message", "error", err)
+		return nil
+	}
+	return data
+}
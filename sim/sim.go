@@ -0,0 +1,292 @@
+// Package sim plays the game engine headless to measure how a set of rules plays out over
+// a large number of hands. Every round goes through the same game.Game a live table uses
+package sim
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"math/rand/v2"
+	"sync"
+
+	"github.com/dylanmccormick/blackjack-tui/game"
+	"github.com/google/uuid"
+)
+
+const (
+	STRATEGY_BASIC = game.STRATEGY_BASIC
+
+	// BET is the stake for every round. It pays out evenly at 3:2 and 6:5 and halves for a surrender
+	BET = 10
+	// the wallet is topped back up every round so a losing streak never stops the simulation
+	BANKROLL = 1_000_000
+)
+
+type Config struct {
+	Game     game.GameConfig
+	Hands    int    // rounds to play, split between the workers
+	Strategy string // how the player decides. One of the game's strategies
+	Workers  int
+	Seed     uint64 // deals the same shoes every run. 0 shuffles from crypto/rand
+}
+
+// Result is what came out of a simulation. Rates are fractions and money is in units of the starting bet
+type Result struct {
+	Rounds         int     `json:"rounds"`
+	Hands          int     `json:"hands"` // includes the hands made by splitting
+	Wagered        float64 `json:"wagered"`
+	Net            float64 `json:"net"`
+	HouseEdge      float64 `json:"house_edge"` // expected loss per round as a fraction of the starting bet
+	Variance       float64 `json:"variance"`   // of a round's result
+	StdDev         float64 `json:"std_dev"`
+	BlackjackRate  float64 `json:"blackjack_rate"`   // per round
+	PlayerBustRate float64 `json:"player_bust_rate"` // per hand
+	DealerBustRate float64 `json:"dealer_bust_rate"` // per round the dealer played out
+	SurrenderRate  float64 `json:"surrender_rate"`   // per round
+	DealerPlayed   int     `json:"dealer_played"`    // rounds where the dealer drew to their hand
+	Strategy       string  `json:"strategy"`
+	Rules          string  `json:"rules"`
+}
+
+// tally is what one worker counted. Tallies are added up before anything is averaged
+type tally struct {
+	rounds       int
+	hands        int
+	wagered      int
+	net          int
+	netSquared   int
+	blackjacks   int
+	playerBusts  int
+	dealerBusts  int
+	dealerPlayed int
+	surrenders   int
+}
+
+func (t *tally) add(o tally) {
+	t.rounds += o.rounds
+	t.hands += o.hands
+	t.wagered += o.wagered
+	t.net += o.net
+	t.netSquared += o.netSquared
+	t.blackjacks += o.blackjacks
+	t.playerBusts += o.playerBusts
+	t.dealerBusts += o.dealerBusts
+	t.dealerPlayed += o.dealerPlayed
+	t.surrenders += o.surrenders
+}
+
+// Run plays the hands across the workers and adds up what happened
+func Run(config Config) (Result, error) {
+	if config.Strategy == "" {
+		config.Strategy = STRATEGY_BASIC
+	}
+	if _, err := game.ParseStrategy(config.Strategy); err != nil {
+		return Result{}, err
+	}
+	if config.Hands < 1 {
+		return Result{}, fmt.Errorf("Hands must be at least 1")
+	}
+	workers := max(min(config.Workers, config.Hands), 1)
+	// table limits don't change the odds. The simulation always bets the same amount
+	config.Game.Rules.MinBet = 1
+	config.Game.Rules.MaxBet = 0
+	config.Game.Rules.BetIncrement = 1
+
+	tallies := make([]tally, workers)
+	errs := make([]error, workers)
+	var wg sync.WaitGroup
+	for i := range workers {
+		hands := config.Hands / workers
+		if i < config.Hands%workers {
+			hands++
+		}
+		gameConfig := config.Game
+		if config.Seed != 0 {
+			gameConfig.Entropy = workerEntropy(config.Seed, i)
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			tallies[i], errs[i] = play(gameConfig, config.Strategy, hands)
+		}()
+	}
+	wg.Wait()
+
+	total := tally{}
+	for i := range workers {
+		if errs[i] != nil {
+			return Result{}, errs[i]
+		}
+		total.add(tallies[i])
+	}
+	return total.result(config), nil
+}
+
+// workerEntropy is where a worker's shoe seeds come from. Every worker gets its own stream off the
+// run's seed so the workers don't deal the same shoes
+func workerEntropy(seed uint64, worker int) io.Reader {
+	var s [32]byte
+	binary.LittleEndian.PutUint64(s[:8], seed)
+	binary.LittleEndian.PutUint64(s[8:16], uint64(worker))
+	return rand.NewChaCha8(s)
+}
+
+// play runs one table with a single player for the given number of rounds
+func play(config game.GameConfig, strategy string, rounds int) (tally, error) {
+	t := tally{}
+	s, err := game.ParseStrategy(strategy)
+	if err != nil {
+		return t, err
+	}
+	g := game.NewGame(config)
+	p := &game.Player{ID: uuid.New(), Name: "sim"}
+	if err := g.AddPlayer(p); err != nil {
+		return t, err
+	}
+	if err := g.StartGame(); err != nil {
+		return t, err
+	}
+	for range rounds {
+		p.Wallet = BANKROLL
+		if err := g.PlaceBet(p, game.Bet{Main: BET}); err != nil {
+			return t, err
+		}
+		if err := g.StartRound(); err != nil {
+			return t, err
+		}
+		if err := playRound(g, p, s, &t); err != nil {
+			return t, fmt.Errorf("Round %d: %w", g.Round, err)
+		}
+		// nobody is listening. Don't let the events pile up
+		g.DrainEvents()
+	}
+	return t, nil
+}
+
+func playRound(g *game.Game, p *game.Player, s game.Strategy, t *tally) error {
+	err := g.DealCards()
+	if err != nil {
+		return err
+	}
+	for {
+		switch g.State {
+		case game.INSURANCE:
+			err = decideInsurance(g, p, s)
+		case game.PLAYER_TURN:
+			err = decide(g, p, s)
+		case game.DEALER_TURN:
+			t.dealerPlayed++
+			err = g.PlayDealer()
+			if err == nil && g.DealerHand.GetState() == game.BUST {
+				t.dealerBusts++
+			}
+		case game.RESOLVING_BETS:
+			t.record(p)
+			wagered := p.Bet
+			results, err := g.ResolveBets()
+			if err != nil {
+				return err
+			}
+			net := 0
+			for _, r := range results[p.ID] {
+				net += r.WalletDelta
+				if r.Blackjack {
+					t.blackjacks++
+				}
+			}
+			t.rounds++
+			t.wagered += wagered
+			t.net += net
+			t.netSquared += net * net
+			return nil
+		default:
+			return fmt.Errorf("Unexpected state %s", g.State)
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// record counts the player's hands before they are cleared away
+func (t *tally) record(p *game.Player) {
+	for _, h := range p.Hands {
+		t.hands++
+		if h.Surrendered {
+			t.surrenders++
+		}
+		if h.GetState() == game.BUST {
+			t.playerBusts++
+		}
+	}
+}
+
+func decideInsurance(g *game.Game, p *game.Player, s game.Strategy) error {
+	err := decide(g, p, s)
+	if err != nil {
+		return err
+	}
+	if g.State == game.INSURANCE {
+		return g.EndInsurance()
+	}
+	return nil
+}
+
+func decide(g *game.Game, p *game.Player, s game.Strategy) error {
+	action, err := s.Decide(g, p)
+	if err != nil {
+		return err
+	}
+	return g.Act(p, action)
+}
+
+func (t tally) result(config Config) Result {
+	rounds := float64(t.rounds)
+	mean := float64(t.net) / rounds / BET
+	variance := float64(t.netSquared)/rounds/(BET*BET) - mean*mean
+	r := Result{
+		Rounds:         t.rounds,
+		Hands:          t.hands,
+		Wagered:        float64(t.wagered) / BET,
+		Net:            float64(t.net) / BET,
+		HouseEdge:      -mean,
+		Variance:       variance,
+		StdDev:         math.Sqrt(variance),
+		BlackjackRate:  float64(t.blackjacks) / rounds,
+		PlayerBustRate: float64(t.playerBusts) / float64(t.hands),
+		SurrenderRate:  float64(t.surrenders) / rounds,
+		DealerPlayed:   t.dealerPlayed,
+		Strategy:       config.Strategy,
+		Rules:          describeRules(config.Game),
+	}
+	if t.dealerPlayed > 0 {
+		r.DealerBustRate = float64(t.dealerBusts) / float64(t.dealerPlayed)
+	}
+	return r
+}
+
+func describeRules(config game.GameConfig) string {
+	rules := config.Rules
+	soft17 := "H17"
+	if rules.StandOnSoft17 {
+		soft17 = "S17"
+	}
+	desc := fmt.Sprintf("%d decks, %s, blackjack pays %s, double %s", config.DeckCount, soft17, rules.BlackjackPayout, rules.DoubleRule)
+	if config.Variant != nil && config.Variant.Name() != game.VARIANT_CLASSIC {
+		desc = config.Variant.Name() + ", " + desc
+	}
+	if rules.DoubleAfterSplit {
+		desc += ", DAS"
+	}
+	if rules.Surrender != game.NO_SURRENDER {
+		desc += fmt.Sprintf(", %s surrender", rules.Surrender)
+	}
+	if rules.NoHoleCard {
+		desc += ", no hole card"
+	}
+	if rules.ContinuousShuffle {
+		desc += ", continuous shuffle"
+	}
+	return desc
+}
@@ -0,0 +1,72 @@
+package sim
+
+import (
+	"io"
+	"log/slog"
+	"testing"
+
+	"github.com/dylanmccormick/blackjack-tui/game"
+)
+
+func TestRun(t *testing.T) {
+	slog.SetDefault(slog.New(slog.NewTextHandler(io.Discard, nil)))
+	config := Config{
+		Game:     game.GameConfig{DeckCount: 6, CutLocation: 150, Rules: game.DefaultRules()},
+		Hands:    40_000,
+		Strategy: STRATEGY_BASIC,
+		Workers:  4,
+		Seed:     1,
+	}
+	res, err := Run(config)
+	if err != nil {
+		t.Fatalf("Got an unexpected error running the simulation. err=%v", err)
+	}
+	if res.Rounds != config.Hands {
+		t.Fatalf("Every round should be played. expected=%d got=%d", config.Hands, res.Rounds)
+	}
+	// basic strategy on these rules is worth about a half percent to the house. The seed deals the
+	// same shoes every run, so the margins only need to cover how far this seed's shoes land from it
+	if res.HouseEdge < -0.01 || res.HouseEdge > 0.012 {
+		t.Errorf("house edge out of range. got=%.4f", res.HouseEdge)
+	}
+	if res.BlackjackRate < 0.04 || res.BlackjackRate > 0.055 {
+		t.Errorf("blackjack rate out of range. got=%.4f", res.BlackjackRate)
+	}
+	if res.DealerBustRate < 0.25 || res.DealerBustRate > 0.32 {
+		t.Errorf("dealer bust rate out of range. got=%.4f", res.DealerBustRate)
+	}
+	if res.Variance < 1 || res.Variance > 1.6 {
+		t.Errorf("variance out of range. got=%.4f", res.Variance)
+	}
+	t.Logf("%+v", res)
+
+	again, err := Run(config)
+	if err != nil {
+		t.Fatalf("Got an unexpected error running the simulation. err=%v", err)
+	}
+	if again != res {
+		t.Errorf("A seeded run should play the same hands. expected=%+v got=%+v", res, again)
+	}
+
+	// the same shoes at 6:5. Only the blackjacks pay differently, so the house has to be better off
+	config.Game.Rules.BlackjackPayout, _ = game.ParsePayout("6:5")
+	sixFive, err := Run(config)
+	if err != nil {
+		t.Fatalf("Got an unexpected error running the simulation. err=%v", err)
+	}
+	if sixFive.HouseEdge-res.HouseEdge < 0.01 {
+		t.Errorf("6:5 should cost the player over a percent more than 3:2. 3:2=%.4f 6:5=%.4f", res.HouseEdge, sixFive.HouseEdge)
+	}
+}
+
+func TestRunErrors(t *testing.T) {
+	config := Config{Game: game.GameConfig{DeckCount: 6, CutLocation: 150, Rules: game.DefaultRules()}, Hands: 10, Strategy: "martingale"}
+	if _, err := Run(config); err == nil {
+		t.Errorf("Expected an error for an unknown strategy")
+	}
+	config.Strategy = STRATEGY_BASIC
+	config.Hands = 0
+	if _, err := Run(config); err == nil {
+		t.Errorf("Expected an error for no hands")
+	}
+}
@@ -0,0 +1,12 @@
+-- name: GetJackpot :one
+SELECT amount
+FROM jackpot
+WHERE id = 1
+;
+
+-- name: UpdateJackpot :exec
+UPDATE jackpot
+SET updated_at = CURRENT_TIMESTAMP,
+amount = ?
+WHERE id = 1
+;
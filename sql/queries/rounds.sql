@@ -0,0 +1,30 @@
+-- name: CreateRound :one
+INSERT INTO rounds(table_id, round_number, shoe_seed, dealer_hand, events, played_at)
+VALUES (?, ?, ?, ?, ?, ?)
+RETURNING *;
+
+-- name: CreateRoundPlayer :exec
+INSERT INTO round_players(round_id, github_id, seat, bet, payout, hands)
+VALUES (?, ?, ?, ?, ?, ?);
+
+-- name: GetRecentRoundsForUser :many
+SELECT rounds.id, rounds.table_id, rounds.round_number, rounds.played_at, round_players.bet, round_players.payout
+FROM rounds
+JOIN round_players ON round_players.round_id = rounds.id
+WHERE round_players.github_id = ?
+ORDER BY rounds.id DESC
+LIMIT ? OFFSET ?
+;
+
+-- name: GetRound :one
+SELECT *
+FROM rounds
+WHERE id = ?
+;
+
+-- name: GetRoundPlayers :many
+SELECT *
+FROM round_players
+WHERE round_id = ?
+ORDER BY seat
+;
@@ -0,0 +1,17 @@
+-- name: CreateTournamentResult :exec
+INSERT INTO tournament_results(tournament, started_at, github_id, place, chips, prize)
+VALUES (?, ?, ?, ?, ?, ?);
+
+-- name: UpdateUserAddPrize :exec
+UPDATE users
+SET updated_at = CURRENT_TIMESTAMP,
+wallet = wallet + ?
+WHERE github_id = ?
+;
+
+-- name: GetTournamentResults :many
+SELECT *
+FROM tournament_results
+WHERE tournament = ? AND started_at = ?
+ORDER BY place
+;
@@ -12,14 +12,17 @@ where github_id = ?
 -- name: UpdateUserStats :one
 UPDATE users
 SET updated_at = CURRENT_TIMESTAMP,
-wallet = ?,
+wallet = wallet + ?,
 amount_bet_lifetime = amount_bet_lifetime + ?,
 amount_won_lifetime = amount_won_lifetime + ?,
 amount_lost_lifetime = amount_lost_lifetime + ?,
 hands_played = hands_played + 1,
 hands_won = hands_won + ?,
 hands_lost = hands_lost + ?,
-blackjacks = blackjacks + ?
+hands_surrendered = hands_surrendered + ?,
+blackjacks = blackjacks + ?,
+side_bets = side_bets + ?,
+side_bets_won = side_bets_won + ?
 WHERE github_id = ?
 RETURNING *
 ;
@@ -48,3 +51,29 @@ wallet = wallet + ?
 WHERE github_id = ?
 RETURNING *
 ;
+
+-- name: UpdateStrategyStats :one
+UPDATE users
+SET updated_at = CURRENT_TIMESTAMP,
+strategy_decisions = strategy_decisions + 1,
+strategy_deviations = strategy_deviations + ?
+WHERE github_id = ?
+RETURNING *
+;
+
+-- name: UpdateCountStats :one
+UPDATE users
+SET updated_at = CURRENT_TIMESTAMP,
+count_quizzes = count_quizzes + 1,
+count_quizzes_correct = count_quizzes_correct + ?
+WHERE github_id = ?
+RETURNING *
+;
+
+-- name: UpdateTimeoutStats :one
+UPDATE users
+SET updated_at = CURRENT_TIMESTAMP,
+timeouts = timeouts + 1
+WHERE github_id = ?
+RETURNING *
+;
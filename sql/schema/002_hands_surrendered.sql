@@ -0,0 +1,5 @@
+-- +goose Up
+ALTER TABLE users ADD COLUMN hands_surrendered INT NOT NULL DEFAULT 0;
+
+-- +goose Down
+ALTER TABLE users DROP COLUMN hands_surrendered;
@@ -0,0 +1,26 @@
+-- +goose Up
+CREATE TABLE rounds (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	table_id TEXT NOT NULL,
+	round_number INT NOT NULL,
+	shoe_seed TEXT NOT NULL,
+	dealer_hand TEXT NOT NULL,
+	events TEXT NOT NULL,
+	played_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE round_players (
+	round_id INTEGER NOT NULL REFERENCES rounds(id) ON DELETE CASCADE,
+	github_id TEXT NOT NULL,
+	seat INT NOT NULL,
+	bet INT NOT NULL,
+	payout INT NOT NULL,
+	hands TEXT NOT NULL,
+	PRIMARY KEY (round_id, github_id)
+);
+
+CREATE INDEX round_players_github_id ON round_players(github_id, round_id);
+
+-- +goose Down
+DROP TABLE IF EXISTS round_players;
+DROP TABLE IF EXISTS rounds;
@@ -0,0 +1,7 @@
+-- +goose Up
+ALTER TABLE users ADD COLUMN strategy_decisions INT NOT NULL DEFAULT 0;
+ALTER TABLE users ADD COLUMN strategy_deviations INT NOT NULL DEFAULT 0;
+
+-- +goose Down
+ALTER TABLE users DROP COLUMN strategy_deviations;
+ALTER TABLE users DROP COLUMN strategy_decisions;
@@ -0,0 +1,7 @@
+-- +goose Up
+ALTER TABLE users ADD COLUMN count_quizzes INT NOT NULL DEFAULT 0;
+ALTER TABLE users ADD COLUMN count_quizzes_correct INT NOT NULL DEFAULT 0;
+
+-- +goose Down
+ALTER TABLE users DROP COLUMN count_quizzes_correct;
+ALTER TABLE users DROP COLUMN count_quizzes;
@@ -0,0 +1,15 @@
+-- +goose Up
+CREATE TABLE tournament_results (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	tournament TEXT NOT NULL,
+	started_at TIMESTAMP NOT NULL,
+	github_id TEXT NOT NULL,
+	place INT NOT NULL,
+	chips INT NOT NULL,
+	prize INT NOT NULL
+);
+
+CREATE INDEX tournament_results_github_id ON tournament_results(github_id);
+
+-- +goose Down
+DROP TABLE IF EXISTS tournament_results;
@@ -0,0 +1,11 @@
+-- +goose Up
+CREATE TABLE jackpot (
+	id INTEGER PRIMARY KEY CHECK (id = 1),
+	amount INT NOT NULL DEFAULT 0,
+	updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+INSERT INTO jackpot(id, amount) VALUES (1, 0);
+
+-- +goose Down
+DROP TABLE IF EXISTS jackpot;
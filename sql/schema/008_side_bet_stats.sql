@@ -0,0 +1,7 @@
+-- +goose Up
+ALTER TABLE users ADD COLUMN side_bets INT NOT NULL DEFAULT 0;
+ALTER TABLE users ADD COLUMN side_bets_won INT NOT NULL DEFAULT 0;
+
+-- +goose Down
+ALTER TABLE users DROP COLUMN side_bets_won;
+ALTER TABLE users DROP COLUMN side_bets;
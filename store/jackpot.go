@@ -0,0 +1,28 @@
+package store
+
+import (
+	"context"
+	"fmt"
+)
+
+type JackpotRepository interface {
+	GetJackpot(ctx context.Context) (int64, error)
+	UpdateJackpot(ctx context.Context, amount int64) error
+}
+
+// JackpotAmount is the progressive jackpot pool as it was last saved
+func (s *Store) JackpotAmount(ctx context.Context) (int, error) {
+	if s.Jackpot == nil {
+		return 0, fmt.Errorf("Jackpot is not available")
+	}
+	amount, err := s.Jackpot.GetJackpot(ctx)
+	return int(amount), err
+}
+
+// SaveJackpot keeps the pool so it survives a restart
+func (s *Store) SaveJackpot(ctx context.Context, amount int) error {
+	if s.Jackpot == nil {
+		return fmt.Errorf("Jackpot is not available")
+	}
+	return s.Jackpot.UpdateJackpot(ctx, int64(amount))
+}
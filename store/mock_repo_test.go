@@ -25,6 +25,15 @@ type MockUserRepo struct {
 	UpdateUserStatsReturn database.User
 	UpdateUserStatsError  error
 
+	UpdateStrategyStatsReturn database.User
+	UpdateStrategyStatsError  error
+
+	UpdateCountStatsReturn database.User
+	UpdateCountStatsError  error
+
+	UpdateTimeoutStatsReturn database.User
+	UpdateTimeoutStatsError  error
+
 	GetUserCalls             []string // githubIDs passed
 	UpdateStreakCalls        []database.UpdateLoginStreakParams
 	CreateUserCalls          []database.CreateUserParams
@@ -32,6 +41,9 @@ type MockUserRepo struct {
 	UpdateLoginStreakCalls   []database.UpdateLoginStreakParams
 	UpdateUserAddIncomeCalls []database.UpdateUserAddIncomeParams
 	UpdateUserStatsCalls     []database.UpdateUserStatsParams
+	UpdateStrategyStatsCalls []database.UpdateStrategyStatsParams
+	UpdateCountStatsCalls    []database.UpdateCountStatsParams
+	UpdateTimeoutStatsCalls  []string // githubIDs passed
 }
 
 func (m *MockUserRepo) GetUserByUsername(ctx context.Context, githubID string) (database.User, error) {
@@ -63,3 +75,18 @@ func (m *MockUserRepo) UpdateUserStats(ctx context.Context, arg database.UpdateU
 	m.UpdateUserStatsCalls = append(m.UpdateUserStatsCalls, arg)
 	return m.UpdateUserStatsReturn, m.UpdateUserStatsError
 }
+
+func (m *MockUserRepo) UpdateStrategyStats(ctx context.Context, arg database.UpdateStrategyStatsParams) (database.User, error) {
+	m.UpdateStrategyStatsCalls = append(m.UpdateStrategyStatsCalls, arg)
+	return m.UpdateStrategyStatsReturn, m.UpdateStrategyStatsError
+}
+
+func (m *MockUserRepo) UpdateCountStats(ctx context.Context, arg database.UpdateCountStatsParams) (database.User, error) {
+	m.UpdateCountStatsCalls = append(m.UpdateCountStatsCalls, arg)
+	return m.UpdateCountStatsReturn, m.UpdateCountStatsError
+}
+
+func (m *MockUserRepo) UpdateTimeoutStats(ctx context.Context, githubID string) (database.User, error) {
+	m.UpdateTimeoutStatsCalls = append(m.UpdateTimeoutStatsCalls, githubID)
+	return m.UpdateTimeoutStatsReturn, m.UpdateTimeoutStatsError
+}
@@ -0,0 +1,160 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/dylanmccormick/blackjack-tui/internal/database"
+)
+
+type RoundRepository interface {
+	CreateRound(ctx context.Context, arg database.CreateRoundParams) (database.Round, error)
+	CreateRoundPlayer(ctx context.Context, arg database.CreateRoundPlayerParams) error
+	GetRecentRoundsForUser(ctx context.Context, arg database.GetRecentRoundsForUserParams) ([]database.GetRecentRoundsForUserRow, error)
+	GetRound(ctx context.Context, id int64) (database.Round, error)
+	GetRoundPlayers(ctx context.Context, roundID int64) ([]database.RoundPlayer, error)
+}
+
+// RoundRecord is one finished round. Cards and events are stored as the game's json
+// since the store doesn't know about the game
+type RoundRecord struct {
+	ID         int64
+	TableID    string
+	Round      int
+	ShoeSeed   string
+	DealerHand json.RawMessage
+	Events     json.RawMessage
+	PlayedAt   time.Time
+	Players    []RoundPlayerRecord
+}
+
+type RoundPlayerRecord struct {
+	GithubID string
+	Seat     int
+	Bet      int // everything the player put on the table including doubles, splits and insurance
+	Payout   int // everything paid back to the player including the bet
+	Hands    json.RawMessage
+}
+
+// RoundSummary is a round from one player's point of view
+type RoundSummary struct {
+	ID       int64
+	TableID  string
+	Round    int
+	PlayedAt time.Time
+	Bet      int
+	Payout   int
+}
+
+// RecordRound saves a finished round and every player in it. Either all of it is saved or none of it
+func (s *Store) RecordRound(ctx context.Context, rr RoundRecord) (int64, error) {
+	if s.Rounds == nil {
+		return 0, fmt.Errorf("Hand history is not available")
+	}
+	var id int64
+	err := s.inTx(ctx, func(tx *Store) error {
+		round, err := tx.Rounds.CreateRound(ctx, database.CreateRoundParams{
+			TableID:     rr.TableID,
+			RoundNumber: int64(rr.Round),
+			ShoeSeed:    rr.ShoeSeed,
+			DealerHand:  string(rr.DealerHand),
+			Events:      string(rr.Events),
+			PlayedAt:    rr.PlayedAt,
+		})
+		if err != nil {
+			return err
+		}
+		for _, p := range rr.Players {
+			err = tx.Rounds.CreateRoundPlayer(ctx, database.CreateRoundPlayerParams{
+				RoundID:  round.ID,
+				GithubID: p.GithubID,
+				Seat:     int64(p.Seat),
+				Bet:      int64(p.Bet),
+				Payout:   int64(p.Payout),
+				Hands:    string(p.Hands),
+			})
+			if err != nil {
+				return err
+			}
+		}
+		id = round.ID
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return id, nil
+}
+
+// RecentRounds is a page of the rounds a player was in, newest first
+func (s *Store) RecentRounds(ctx context.Context, githubID string, limit, offset int) ([]RoundSummary, error) {
+	if s.Rounds == nil {
+		return nil, fmt.Errorf("Hand history is not available")
+	}
+	rows, err := s.Rounds.GetRecentRoundsForUser(ctx, database.GetRecentRoundsForUserParams{
+		GithubID: githubID,
+		Limit:    int64(limit),
+		Offset:   int64(offset),
+	})
+	if err != nil {
+		return nil, err
+	}
+	rounds := []RoundSummary{}
+	for _, row := range rows {
+		rounds = append(rounds, RoundSummary{
+			ID:       row.ID,
+			TableID:  row.TableID,
+			Round:    int(row.RoundNumber),
+			PlayedAt: row.PlayedAt,
+			Bet:      int(row.Bet),
+			Payout:   int(row.Payout),
+		})
+	}
+	return rounds, nil
+}
+
+func (s *Store) GetRound(ctx context.Context, id int64) (RoundRecord, error) {
+	if s.Rounds == nil {
+		return RoundRecord{}, fmt.Errorf("Hand history is not available")
+	}
+	round, err := s.Rounds.GetRound(ctx, id)
+	if err != nil {
+		return RoundRecord{}, err
+	}
+	players, err := s.Rounds.GetRoundPlayers(ctx, id)
+	if err != nil {
+		return RoundRecord{}, err
+	}
+	rr := RoundRecord{
+		ID:         round.ID,
+		TableID:    round.TableID,
+		Round:      int(round.RoundNumber),
+		ShoeSeed:   round.ShoeSeed,
+		DealerHand: json.RawMessage(round.DealerHand),
+		Events:     json.RawMessage(round.Events),
+		PlayedAt:   round.PlayedAt,
+		Players:    []RoundPlayerRecord{},
+	}
+	for _, p := range players {
+		rr.Players = append(rr.Players, RoundPlayerRecord{
+			GithubID: p.GithubID,
+			Seat:     int(p.Seat),
+			Bet:      int(p.Bet),
+			Payout:   int(p.Payout),
+			Hands:    json.RawMessage(p.Hands),
+		})
+	}
+	return rr, nil
+}
+
+// Played reports whether a player was dealt into the round
+func (rr RoundRecord) Played(githubID string) bool {
+	for _, p := range rr.Players {
+		if p.GithubID == githubID {
+			return true
+		}
+	}
+	return false
+}
@@ -3,7 +3,9 @@ package store
 import (
 	"context"
 	"database/sql"
+	"fmt"
 	"log/slog"
+	"slices"
 	"time"
 
 	"github.com/dylanmccormick/blackjack-tui/internal/database"
@@ -18,10 +20,17 @@ type UserRepository interface {
 	UpdateLoginStreak(ctx context.Context, arg database.UpdateLoginStreakParams) (database.User, error)
 	UpdateUserAddIncome(ctx context.Context, arg database.UpdateUserAddIncomeParams) (database.User, error)
 	UpdateUserStats(ctx context.Context, arg database.UpdateUserStatsParams) (database.User, error)
+	UpdateStrategyStats(ctx context.Context, arg database.UpdateStrategyStatsParams) (database.User, error)
+	UpdateCountStats(ctx context.Context, arg database.UpdateCountStatsParams) (database.User, error)
+	UpdateTimeoutStats(ctx context.Context, githubID string) (database.User, error)
 }
 
 type Store struct {
-	DB UserRepository
+	db          *sql.DB // nil when the store was built from a user repo alone
+	DB          UserRepository
+	Rounds      RoundRepository      // hand history. nil when the store was built from a user repo alone
+	Tournaments TournamentRepository // nil when the store was built from a user repo alone
+	Jackpot     JackpotRepository    // nil when the store was built from a user repo alone
 }
 
 func NewStore(dbPath, schemaLocation string) (*Store, error) {
@@ -36,11 +45,47 @@ func NewStore(dbPath, schemaLocation string) (*Store, error) {
 		slog.Error("Error running goose", "error", err)
 		return &Store{}, err
 	}
-	return &Store{database.New(db)}, nil
+	queries := database.New(db)
+	return &Store{db: db, DB: queries, Rounds: queries, Tournaments: queries, Jackpot: queries}, nil
+}
+
+// inTx runs fn with a store whose queries all go through one transaction. Nothing fn writes is
+// kept unless it returns nil. A store built from a user repo alone has no database to begin a
+// transaction on and runs fn as it is
+func (s *Store) inTx(ctx context.Context, fn func(tx *Store) error) error {
+	if s.db == nil {
+		return fn(s)
+	}
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	queries := database.New(s.db).WithTx(tx)
+	err = fn(&Store{DB: queries, Rounds: queries, Tournaments: queries, Jackpot: queries})
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// OpenStore opens an existing database read only. Nothing is migrated, so a copy of the
+// server's database can be read from anywhere without the schema around
+func OpenStore(dbPath string) (*Store, error) {
+	db, err := sql.Open("sqlite", "file:"+dbPath+"?mode=ro")
+	if err != nil {
+		return &Store{}, err
+	}
+	err = db.Ping()
+	if err != nil {
+		return &Store{}, err
+	}
+	queries := database.New(db)
+	return &Store{db: db, DB: queries, Rounds: queries, Tournaments: queries, Jackpot: queries}, nil
 }
 
 func NewStoreWithRepo(repo UserRepository) (*Store, error) {
-	return &Store{repo}, nil
+	return &Store{DB: repo}, nil
 }
 
 func calculateIncome(streak int64) int64 {
@@ -77,22 +122,64 @@ const (
 	Won WonState = iota
 	Lost
 	Tied
+	Surrendered
 )
 
+var wonStateNames = []string{"won", "lost", "tied", "surrendered"}
+
+func (ws WonState) String() string {
+	if int(ws) < 0 || int(ws) >= len(wonStateNames) {
+		return "unknown"
+	}
+	return wonStateNames[ws]
+}
+
+func (ws WonState) MarshalText() ([]byte, error) {
+	return []byte(ws.String()), nil
+}
+
+func (ws *WonState) UnmarshalText(b []byte) error {
+	i := slices.Index(wonStateNames, string(b))
+	if i < 0 {
+		return fmt.Errorf("unknown outcome %q", b)
+	}
+	*ws = WonState(i)
+	return nil
+}
+
 type RoundResult struct {
 	Outcome     WonState
 	Blackjack   bool
 	Bet         int
+	Insurance   int // Insurance side bet. WalletDelta already includes what it won or lost
+	Jackpot     int // Progressive jackpot side bet. Same as insurance
+	SideBets    []SideBetResult
 	Wallet      int
 	WalletDelta int
 }
 
+// SideBetResult is a side bet settled on the deal. WalletDelta already includes what it won or lost
+type SideBetResult struct {
+	Name   string
+	Bet    int
+	Payout int // everything paid back including the bet. 0 when it lost
+}
+
 func (s *Store) RecordResult(ctx context.Context, githubID string, rr RoundResult) error {
 	var addWinAmount int64
 	var addLossAmount int64
 	var addHandWin int64
 	var addHandLoss int64
+	var addHandSurrendered int64
 	var addBlackjacks int64
+	var addSideBetAmount int64
+	var addSideBetsWon int64
+	for _, sb := range rr.SideBets {
+		addSideBetAmount += int64(sb.Bet)
+		if sb.Payout > 0 {
+			addSideBetsWon++
+		}
+	}
 	if rr.Blackjack {
 		addBlackjacks = 1
 	} else {
@@ -100,30 +187,44 @@ func (s *Store) RecordResult(ctx context.Context, githubID string, rr RoundResul
 	}
 	switch rr.Outcome {
 	case Won:
-		addWinAmount = int64(rr.WalletDelta)
-		addLossAmount = 0
 		addHandWin = 1
 		addHandLoss = 0
 	case Lost:
-		addWinAmount = 0
-		addLossAmount = int64(-1 * rr.WalletDelta)
 		addHandWin = 0
 		addHandLoss = 1
+	case Surrendered:
+		// tracked on its own so giving up half a bet doesn't read as a lost hand
+		addHandSurrendered = 1
 	default:
-		addWinAmount = 0
-		addLossAmount = 0
 		addHandWin = 0
 		addHandLoss = 0
 	}
+	// side bets can turn a lost hand into a win (or the other way around) so the money
+	// follows the wallet delta instead of the hand outcome
+	switch {
+	case rr.WalletDelta > 0:
+		addWinAmount = int64(rr.WalletDelta)
+		addLossAmount = 0
+	case rr.WalletDelta < 0:
+		addWinAmount = 0
+		addLossAmount = int64(-1 * rr.WalletDelta)
+	default:
+		addWinAmount = 0
+		addLossAmount = 0
+	}
 	params := database.UpdateUserStatsParams{
-		Wallet:             int64(rr.Wallet),
-		AmountBetLifetime:  int64(rr.Bet),
+		// the round's change, so money paid into the wallet elsewhere in the meantime isn't written over
+		Wallet:             int64(rr.WalletDelta),
+		AmountBetLifetime:  int64(rr.Bet+rr.Insurance+rr.Jackpot) + addSideBetAmount,
 		AmountWonLifetime:  addWinAmount,
 		AmountLostLifetime: addLossAmount,
 		HandsWon:           addHandWin,
 		HandsLost:          addHandLoss,
+		HandsSurrendered:   addHandSurrendered,
 		GithubID:           githubID,
 		Blackjacks:         addBlackjacks,
+		SideBets:           int64(len(rr.SideBets)),
+		SideBetsWon:        addSideBetsWon,
 	}
 	_, err := s.DB.UpdateUserStats(ctx, params)
 	if err != nil {
@@ -132,6 +233,39 @@ func (s *Store) RecordResult(ctx context.Context, githubID string, rr RoundResul
 	return nil
 }
 
+// RecordDecision counts one decision against basic strategy. Deviated is when the player
+// made a different play than the one basic strategy recommends
+func (s *Store) RecordDecision(ctx context.Context, githubID string, deviated bool) error {
+	var addDeviation int64
+	if deviated {
+		addDeviation = 1
+	}
+	_, err := s.DB.UpdateStrategyStats(ctx, database.UpdateStrategyStatsParams{
+		StrategyDeviations: addDeviation,
+		GithubID:           githubID,
+	})
+	return err
+}
+
+// RecordCountQuiz counts one answer from the card counting trainer
+func (s *Store) RecordCountQuiz(ctx context.Context, githubID string, correct bool) error {
+	var addCorrect int64
+	if correct {
+		addCorrect = 1
+	}
+	_, err := s.DB.UpdateCountStats(ctx, database.UpdateCountStatsParams{
+		CountQuizzesCorrect: addCorrect,
+		GithubID:            githubID,
+	})
+	return err
+}
+
+// RecordTimeout counts one time the table had to act for the player because they ran out of time
+func (s *Store) RecordTimeout(ctx context.Context, githubID string) error {
+	_, err := s.DB.UpdateTimeoutStats(ctx, githubID)
+	return err
+}
+
 func isYesterday(t time.Time) bool {
 	now := time.Now()
 	todayStart := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
@@ -3,6 +3,8 @@ package store
 import (
 	"context"
 	"database/sql"
+	"path/filepath"
+	"slices"
 	"testing"
 	"time"
 
@@ -114,3 +116,347 @@ func TestProcessLogin_ExistingUser(t *testing.T) {
 		t.Errorf("Expected 0 calls to CreateUser. got=%d", len(mockRepo.CreateUserCalls))
 	}
 }
+
+func TestRecordResult_Insurance(t *testing.T) {
+	mockRepo := &MockUserRepo{}
+	store, err := NewStoreWithRepo(mockRepo)
+	if err != nil {
+		t.Fatalf("Unalbe to initialize test. err:%v", err)
+	}
+	// lost the hand but the insurance paid out, so the player broke even
+	rr := RoundResult{Outcome: Lost, Bet: 10, Insurance: 5, Wallet: 100, WalletDelta: 0}
+	err = store.RecordResult(context.Background(), "TEST_GH_ID", rr)
+	if err != nil {
+		t.Fatalf("Got an unexpected error recording result. err=%v", err)
+	}
+	params := mockRepo.UpdateUserStatsCalls[0]
+	if params.HandsLost != 1 {
+		t.Errorf("hands lost incorrect. expected=%d got=%d", 1, params.HandsLost)
+	}
+	if params.AmountBetLifetime != 15 {
+		t.Errorf("amount bet should include insurance. expected=%d got=%d", 15, params.AmountBetLifetime)
+	}
+	if params.AmountLostLifetime != 0 || params.AmountWonLifetime != 0 {
+		t.Errorf("break even round should not change won/lost amounts. won=%d lost=%d", params.AmountWonLifetime, params.AmountLostLifetime)
+	}
+}
+
+func TestRecordResult_Surrendered(t *testing.T) {
+	mockRepo := &MockUserRepo{}
+	store, err := NewStoreWithRepo(mockRepo)
+	if err != nil {
+		t.Fatalf("Unalbe to initialize test. err:%v", err)
+	}
+	rr := RoundResult{Outcome: Surrendered, Bet: 10, Wallet: 95, WalletDelta: -5}
+	err = store.RecordResult(context.Background(), "TEST_GH_ID", rr)
+	if err != nil {
+		t.Fatalf("Got an unexpected error recording result. err=%v", err)
+	}
+	params := mockRepo.UpdateUserStatsCalls[0]
+	if params.HandsSurrendered != 1 {
+		t.Errorf("hands surrendered incorrect. expected=%d got=%d", 1, params.HandsSurrendered)
+	}
+	if params.HandsLost != 0 {
+		t.Errorf("surrendered hand should not count as lost. expected=%d got=%d", 0, params.HandsLost)
+	}
+	if params.AmountLostLifetime != 5 {
+		t.Errorf("amount lost incorrect. expected=%d got=%d", 5, params.AmountLostLifetime)
+	}
+}
+
+func TestRecordResult_SideBets(t *testing.T) {
+	mockRepo := &MockUserRepo{}
+	store, err := NewStoreWithRepo(mockRepo)
+	if err != nil {
+		t.Fatalf("Unalbe to initialize test. err:%v", err)
+	}
+	// won the hand and a perfect pair, lost the 21+3
+	rr := RoundResult{
+		Outcome:     Won,
+		Bet:         10,
+		SideBets:    []SideBetResult{{Name: "perfect_pairs", Bet: 5, Payout: 130}, {Name: "21+3", Bet: 5}},
+		Wallet:      230,
+		WalletDelta: 130,
+	}
+	err = store.RecordResult(context.Background(), "TEST_GH_ID", rr)
+	if err != nil {
+		t.Fatalf("Got an unexpected error recording result. err=%v", err)
+	}
+	params := mockRepo.UpdateUserStatsCalls[0]
+	if params.SideBets != 2 || params.SideBetsWon != 1 {
+		t.Errorf("side bets incorrect. expected=1/2 got=%d/%d", params.SideBetsWon, params.SideBets)
+	}
+	if params.AmountBetLifetime != 20 {
+		t.Errorf("amount bet should include side bets. expected=%d got=%d", 20, params.AmountBetLifetime)
+	}
+	if params.AmountWonLifetime != 130 {
+		t.Errorf("amount won incorrect. expected=%d got=%d", 130, params.AmountWonLifetime)
+	}
+}
+
+func TestRoundHistory(t *testing.T) {
+	store, err := NewStore(":memory:", "../sql/schema")
+	if err != nil {
+		t.Fatalf("Unable to initialize test. err:%v", err)
+	}
+	ctx := context.Background()
+	for i := range 3 {
+		rr := RoundRecord{
+			TableID:    "test",
+			Round:      i + 1,
+			ShoeSeed:   "seed",
+			DealerHand: []byte(`[]`),
+			Events:     []byte(`[]`),
+			PlayedAt:   time.Now(),
+			Players: []RoundPlayerRecord{
+				{GithubID: "p1", Seat: 0, Bet: 10, Payout: 20 * i, Hands: []byte(`[[]]`)},
+			},
+		}
+		if i == 2 {
+			rr.Players = append(rr.Players, RoundPlayerRecord{GithubID: "p2", Seat: 1, Bet: 5, Hands: []byte(`[[]]`)})
+		}
+		_, err := store.RecordRound(ctx, rr)
+		if err != nil {
+			t.Fatalf("Got an unexpected error recording round. err=%v", err)
+		}
+	}
+
+	rounds, err := store.RecentRounds(ctx, "p1", 2, 0)
+	if err != nil {
+		t.Fatalf("Got an unexpected error getting rounds. err=%v", err)
+	}
+	if len(rounds) != 2 || rounds[0].Round != 3 || rounds[1].Round != 2 {
+		t.Fatalf("Expected the two newest rounds first. got=%v", rounds)
+	}
+	if rounds[0].Payout != 40 {
+		t.Errorf("payout incorrect. expected=%d got=%d", 40, rounds[0].Payout)
+	}
+	rounds, err = store.RecentRounds(ctx, "p1", 2, 2)
+	if err != nil {
+		t.Fatalf("Got an unexpected error getting rounds. err=%v", err)
+	}
+	if len(rounds) != 1 || rounds[0].Round != 1 {
+		t.Errorf("Expected the oldest round on the second page. got=%v", rounds)
+	}
+	rounds, err = store.RecentRounds(ctx, "p2", 10, 0)
+	if err != nil {
+		t.Fatalf("Got an unexpected error getting rounds. err=%v", err)
+	}
+	if len(rounds) != 1 {
+		t.Fatalf("Expected p2 to have played one round. got=%d", len(rounds))
+	}
+
+	rr, err := store.GetRound(ctx, rounds[0].ID)
+	if err != nil {
+		t.Fatalf("Got an unexpected error getting round. err=%v", err)
+	}
+	if rr.TableID != "test" || rr.Round != 3 || len(rr.Players) != 2 {
+		t.Errorf("round incorrect. got=%+v", rr)
+	}
+	if !rr.Played("p1") || rr.Played("p3") {
+		t.Errorf("Only players dealt in should have played the round")
+	}
+	// a round that can't be saved in full isn't saved at all
+	_, err = store.RecordRound(ctx, RoundRecord{
+		TableID:    "test",
+		Round:      4,
+		DealerHand: []byte(`[]`),
+		Events:     []byte(`[]`),
+		PlayedAt:   time.Now(),
+		Players: []RoundPlayerRecord{
+			{GithubID: "p3", Seat: 0, Hands: []byte(`[[]]`)},
+			{GithubID: "p3", Seat: 1, Hands: []byte(`[[]]`)},
+		},
+	})
+	if err == nil {
+		t.Fatalf("Expected an error recording the same player twice")
+	}
+	rounds, err = store.RecentRounds(ctx, "p3", 10, 0)
+	if err != nil || len(rounds) != 0 {
+		t.Errorf("Expected the failed round to be rolled back. got=%v err=%v", rounds, err)
+	}
+}
+
+func TestRecordDecision(t *testing.T) {
+	store, err := NewStore(":memory:", "../sql/schema")
+	if err != nil {
+		t.Fatalf("Unable to initialize test. err:%v", err)
+	}
+	ctx := context.Background()
+	_, err = store.GetOrCreateUser(ctx, "p1")
+	if err != nil {
+		t.Fatalf("Unable to create user. err:%v", err)
+	}
+	for _, deviated := range []bool{false, true, false, false} {
+		err = store.RecordDecision(ctx, "p1", deviated)
+		if err != nil {
+			t.Fatalf("Got an unexpected error recording decision. err=%v", err)
+		}
+	}
+	user, err := store.DB.GetUserByUsername(ctx, "p1")
+	if err != nil {
+		t.Fatalf("Unable to get user. err:%v", err)
+	}
+	if user.StrategyDecisions != 4 {
+		t.Errorf("strategy decisions incorrect. expected=%d got=%d", 4, user.StrategyDecisions)
+	}
+	if user.StrategyDeviations != 1 {
+		t.Errorf("strategy deviations incorrect. expected=%d got=%d", 1, user.StrategyDeviations)
+	}
+}
+
+func TestRecordCountQuiz(t *testing.T) {
+	mockRepo := &MockUserRepo{}
+	store, err := NewStoreWithRepo(mockRepo)
+	if err != nil {
+		t.Fatalf("Unalbe to initialize test. err:%v", err)
+	}
+	for _, correct := range []bool{true, false} {
+		err = store.RecordCountQuiz(context.Background(), "TEST_GH_ID", correct)
+		if err != nil {
+			t.Fatalf("Got an unexpected error recording quiz. err=%v", err)
+		}
+	}
+	if len(mockRepo.UpdateCountStatsCalls) != 2 {
+		t.Fatalf("Expected every answer to be recorded. got=%d", len(mockRepo.UpdateCountStatsCalls))
+	}
+	if mockRepo.UpdateCountStatsCalls[0].CountQuizzesCorrect != 1 || mockRepo.UpdateCountStatsCalls[1].CountQuizzesCorrect != 0 {
+		t.Errorf("correct answers recorded incorrectly. got=%+v", mockRepo.UpdateCountStatsCalls)
+	}
+}
+
+func TestRecordTimeout(t *testing.T) {
+	store, err := NewStore(":memory:", "../sql/schema")
+	if err != nil {
+		t.Fatalf("Unable to initialize test. err:%v", err)
+	}
+	ctx := context.Background()
+	if _, err = store.GetOrCreateUser(ctx, "TEST_GH_ID"); err != nil {
+		t.Fatalf("Unable to create user. err:%v", err)
+	}
+	for range 2 {
+		err = store.RecordTimeout(ctx, "TEST_GH_ID")
+		if err != nil {
+			t.Fatalf("Got an unexpected error recording timeout. err=%v", err)
+		}
+	}
+	user, err := store.DB.GetUserByUsername(ctx, "TEST_GH_ID")
+	if err != nil {
+		t.Fatalf("Unable to get user. err:%v", err)
+	}
+	if user.Timeouts != 2 {
+		t.Errorf("Expected every timeout to be counted. got=%d", user.Timeouts)
+	}
+}
+
+func TestRecordTournament(t *testing.T) {
+	store, err := NewStore(":memory:", "../sql/schema")
+	if err != nil {
+		t.Fatalf("Unable to initialize test. err:%v", err)
+	}
+	ctx := context.Background()
+	for _, id := range []string{"p1", "p2", "p3"} {
+		if _, err = store.GetOrCreateUser(ctx, id); err != nil {
+			t.Fatalf("Unable to create user. err:%v", err)
+		}
+	}
+	startedAt := time.Date(2026, 1, 2, 20, 0, 0, 0, time.UTC)
+	tr := TournamentRecord{
+		Name:      "nightly",
+		StartedAt: startedAt,
+		Standings: []TournamentStanding{
+			{GithubID: "p2", Place: 1, Chips: 2400, Prize: 500},
+			{GithubID: "p1", Place: 2, Chips: 600, Prize: 250},
+			{GithubID: "p3", Place: 3, Chips: 0},
+		},
+	}
+	err = store.RecordTournament(ctx, tr)
+	if err != nil {
+		t.Fatalf("Got an unexpected error recording tournament. err=%v", err)
+	}
+	expected := map[string]int64{"p1": 1250, "p2": 1500, "p3": 1000}
+	for id, wallet := range expected {
+		user, err := store.DB.GetUserByUsername(ctx, id)
+		if err != nil {
+			t.Fatalf("Unable to get user. err:%v", err)
+		}
+		if user.Wallet != wallet {
+			t.Errorf("%s wallet incorrect. expected=%d got=%d", id, wallet, user.Wallet)
+		}
+	}
+	standings, err := store.TournamentResults(ctx, "nightly", startedAt)
+	if err != nil {
+		t.Fatalf("Unable to get tournament results. err:%v", err)
+	}
+	if !slices.Equal(standings, tr.Standings) {
+		t.Errorf("standings incorrect. expected=%+v got=%+v", tr.Standings, standings)
+	}
+	// a round p2 was still playing when the prize was paid keeps the prize
+	err = store.RecordResult(ctx, "p2", RoundResult{Outcome: Won, Bet: 10, Wallet: 1010, WalletDelta: 10})
+	if err != nil {
+		t.Fatalf("Got an unexpected error recording result. err=%v", err)
+	}
+	user, err := store.DB.GetUserByUsername(ctx, "p2")
+	if err != nil || user.Wallet != 1510 {
+		t.Errorf("Expected the round to be added to the prize. got=%d err=%v", user.Wallet, err)
+	}
+}
+
+func TestSaveJackpot(t *testing.T) {
+	store, err := NewStore(":memory:", "../sql/schema")
+	if err != nil {
+		t.Fatalf("Unable to initialize test. err:%v", err)
+	}
+	ctx := context.Background()
+	amount, err := store.JackpotAmount(ctx)
+	if err != nil || amount != 0 {
+		t.Fatalf("Jackpot should start empty. got=%d err=%v", amount, err)
+	}
+	if err := store.SaveJackpot(ctx, 12345); err != nil {
+		t.Fatalf("Got an unexpected error saving the jackpot. err=%v", err)
+	}
+	amount, err = store.JackpotAmount(ctx)
+	if err != nil || amount != 12345 {
+		t.Errorf("Jackpot incorrect. expected=12345 got=%d err=%v", amount, err)
+	}
+
+	repoStore, _ := NewStoreWithRepo(store.DB)
+	if _, err := repoStore.JackpotAmount(ctx); err == nil {
+		t.Errorf("Expected an error without a jackpot repo")
+	}
+}
+
+func TestOpenStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "blackjack.db")
+	server, err := NewStore(path, "../sql/schema")
+	if err != nil {
+		t.Fatalf("Unable to initialize test. err:%v", err)
+	}
+	ctx := context.Background()
+	id, err := server.RecordRound(ctx, RoundRecord{
+		TableID:    "test",
+		Round:      1,
+		DealerHand: []byte(`[]`),
+		Events:     []byte(`[]`),
+		PlayedAt:   time.Now(),
+		Players:    []RoundPlayerRecord{{GithubID: "p1", Hands: []byte(`[[]]`)}},
+	})
+	if err != nil {
+		t.Fatalf("Got an unexpected error recording round. err=%v", err)
+	}
+
+	store, err := OpenStore(path)
+	if err != nil {
+		t.Fatalf("Unable to open store. err:%v", err)
+	}
+	rr, err := store.GetRound(ctx, id)
+	if err != nil || rr.TableID != "test" || !rr.Played("p1") {
+		t.Errorf("Expected the recorded round. got=%+v err=%v", rr, err)
+	}
+	if _, err = store.GetOrCreateUser(ctx, "p2"); err == nil {
+		t.Errorf("Expected the store to be read only")
+	}
+	if _, err = OpenStore(filepath.Join(t.TempDir(), "missing.db")); err == nil {
+		t.Errorf("Expected an error opening a database that doesn't exist")
+	}
+}
@@ -0,0 +1,88 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/dylanmccormick/blackjack-tui/internal/database"
+)
+
+type TournamentRepository interface {
+	CreateTournamentResult(ctx context.Context, arg database.CreateTournamentResultParams) error
+	UpdateUserAddPrize(ctx context.Context, arg database.UpdateUserAddPrizeParams) error
+	GetTournamentResults(ctx context.Context, arg database.GetTournamentResultsParams) ([]database.TournamentResult, error)
+}
+
+// TournamentRecord is how a finished tournament ended. Chips are tournament chips and never
+// touch the wallet. Only the prize does
+type TournamentRecord struct {
+	Name      string
+	StartedAt time.Time
+	Standings []TournamentStanding
+}
+
+type TournamentStanding struct {
+	GithubID string
+	Place    int
+	Chips    int
+	Prize    int
+}
+
+// RecordTournament saves the final standings and pays every prize into the winner's wallet.
+// Either all of it happens or none of it does
+func (s *Store) RecordTournament(ctx context.Context, tr TournamentRecord) error {
+	if s.Tournaments == nil {
+		return fmt.Errorf("Tournaments are not available")
+	}
+	return s.inTx(ctx, func(tx *Store) error {
+		for _, st := range tr.Standings {
+			err := tx.Tournaments.CreateTournamentResult(ctx, database.CreateTournamentResultParams{
+				Tournament: tr.Name,
+				StartedAt:  tr.StartedAt,
+				GithubID:   st.GithubID,
+				Place:      int64(st.Place),
+				Chips:      int64(st.Chips),
+				Prize:      int64(st.Prize),
+			})
+			if err != nil {
+				return err
+			}
+			if st.Prize <= 0 {
+				continue
+			}
+			err = tx.Tournaments.UpdateUserAddPrize(ctx, database.UpdateUserAddPrizeParams{
+				Wallet:   int64(st.Prize),
+				GithubID: st.GithubID,
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// TournamentResults is the final standings of a tournament, winner first
+func (s *Store) TournamentResults(ctx context.Context, name string, startedAt time.Time) ([]TournamentStanding, error) {
+	if s.Tournaments == nil {
+		return nil, fmt.Errorf("Tournaments are not available")
+	}
+	rows, err := s.Tournaments.GetTournamentResults(ctx, database.GetTournamentResultsParams{
+		Tournament: name,
+		StartedAt:  startedAt,
+	})
+	if err != nil {
+		return nil, err
+	}
+	standings := []TournamentStanding{}
+	for _, row := range rows {
+		standings = append(standings, TournamentStanding{
+			GithubID: row.GithubID,
+			Place:    int(row.Place),
+			Chips:    int(row.Chips),
+			Prize:    int(row.Prize),
+		})
+	}
+	return standings, nil
+}